@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/database"
+	"financebroke/backend/internal/migrate"
+	"financebroke/backend/migrations"
+)
+
+const usage = `Usage: go run ./cmd/migrate <command>
+
+Commands:
+  status     List migrations and whether they are applied (default)
+  up         Apply all pending migrations
+  down [n]   Roll back the last n applied migrations (default 1)
+  redo       Roll back and re-apply the latest applied migration`
+
+func main() {
+	command := "status"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	steps := 1
+	if command == "down" && len(os.Args) > 2 {
+		n, err := strconv.Atoi(os.Args[2])
+		if err != nil || n <= 0 {
+			log.Fatalf("Invalid number of steps %q", os.Args[2])
+		}
+		steps = n
+	}
+
+	switch command {
+	case "status", "up", "down", "redo":
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		log.Fatal("[DATABASE] ", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal("Failed to read migration status:", err)
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d  %-40s %s\n", status.Version, status.Name, applied)
+		}
+
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations("Applied", applied)
+		if err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+
+	case "down":
+		rolledBack, err := migrator.Down(ctx, steps)
+		printMigrations("Rolled back", rolledBack)
+		if err != nil {
+			log.Fatal("Rollback failed:", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("No applied migrations to roll back")
+		}
+
+	case "redo":
+		redone, err := migrator.Redo(ctx)
+		if err != nil {
+			log.Fatal("Redo failed:", err)
+		}
+		if redone == nil {
+			fmt.Println("No applied migrations to redo")
+			return
+		}
+		fmt.Printf("Redid %03d_%s\n", redone.Version, redone.Name)
+	}
+}
+
+func printMigrations(action string, migrations []migrate.Migration) {
+	for _, migration := range migrations {
+		fmt.Printf("%s %03d_%s\n", action, migration.Version, migration.Name)
+	}
+}
@@ -1,58 +1,165 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"financebroke/backend/internal/config"
 	"financebroke/backend/internal/database"
 	"financebroke/backend/internal/handler"
 	"financebroke/backend/internal/middleware"
+	"financebroke/backend/internal/migrate"
 	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/scheduler"
 	"financebroke/backend/internal/services"
 	"financebroke/backend/internal/usecase"
+	"financebroke/backend/internal/utils"
+	"financebroke/backend/migrations"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Route all logging, including gin and the standard log package, through
+	// the structured logger
+	if err := utils.SetupLogger(os.Stdout, cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
 	// Connect to database
-	database.Connect()
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		log.Fatal("[DATABASE] ", err)
+	}
+
+	// Apply pending migrations when enabled
+	if cfg.AutoMigrate {
+		runMigrations(db)
+	}
 
 	// Initialize services
-	telegramService := services.NewTelegramService(os.Getenv("TELEGRAM_BOT_TOKEN"))
-	emailService := services.NewEmailService(
-		os.Getenv("SMTP_HOST"),
-		os.Getenv("SMTP_PORT"),
-		os.Getenv("SMTP_USERNAME"),
-		os.Getenv("SMTP_PASSWORD"),
-		os.Getenv("FROM_EMAIL"),
-	)
+	telegramService := services.NewTelegramService(cfg.Telegram)
+	emailService := services.NewEmailService(cfg.SMTP)
+	slackService := services.NewSlackService(cfg.Slack, cfg.Server.AppURL)
+	discordService := services.NewDiscordService(cfg.Discord, cfg.Server.AppURL)
+	webhookService := services.NewWebhookService(cfg.Webhooks)
+	tokenManager := utils.NewTokenManager(cfg.JWT)
+
+	// Notification channels, in the order they are tried
+	notifiers := services.NewNotifierRegistry()
+	notifiers.Register(emailService, true)
+	notifiers.Register(telegramService, false)
+	notifiers.Register(slackService, false)
+	notifiers.Register(discordService, false)
 
 	// Initialize repositories
-	userRepo := repository.NewUserRepository(database.DB)
-	billRepo := repository.NewBillRepository(database.DB)
+	repository.SetQueryTimeout(cfg.Database.QueryTimeout)
+	userRepo := repository.NewUserRepository(db)
+	billRepo := repository.NewBillRepository(db)
+	recurrenceRepo := repository.NewRecurrenceRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	budgetRepo := repository.NewBudgetRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	notificationPrefRepo := repository.NewNotificationPreferenceRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookDeliveryRepo := repository.NewWebhookDeliveryRepository(db)
+	transactor := repository.NewTransactor(db)
 
 	// Initialize usecases
-	authUsecase := usecase.NewAuthUsecase(userRepo)
-	billUsecase := usecase.NewBillUsecase(billRepo)
-	notificationUsecase := usecase.NewNotificationUsecase(userRepo, telegramService, emailService)
+	authUsecase := usecase.NewAuthUsecase(userRepo, sessionRepo, userTokenRepo, twoFactorRepo, tokenManager, emailService, cfg.Server.AppURL)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(userRepo, twoFactorRepo)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, webhookDeliveryRepo, webhookService, cfg.Webhooks)
+	billUsecase := usecase.NewBillUsecase(billRepo, recurrenceRepo, paymentRepo, categoryRepo, tagRepo, transactor, webhookUsecase)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, tagRepo)
+	budgetUsecase := usecase.NewBudgetUsecase(budgetRepo, categoryRepo)
+	recurrenceUsecase := usecase.NewRecurrenceUsecase(recurrenceRepo, billRepo, webhookUsecase)
+	notificationUsecase := usecase.NewNotificationUsecase(userRepo, billRepo, budgetRepo, notificationPrefRepo, notificationRepo, notifiers, webhookUsecase, cfg.Notify)
+
+	// Start background jobs
+	jobScheduler := scheduler.New(cfg.Scheduler.Interval, scheduler.SystemClock())
+	jobScheduler.Register("recurring_bills", scheduler.NewRecurrenceJob(recurrenceUsecase))
+	jobScheduler.Register("overdue_bills", scheduler.NewOverdueJob(billUsecase, notificationUsecase))
+	jobScheduler.Register("bill_reminders", scheduler.NewReminderJob(notificationUsecase))
+	jobScheduler.Register("budget_alerts", scheduler.NewBudgetAlertJob(notificationUsecase))
+	jobScheduler.Register("account_deletions", scheduler.NewAccountDeletionJob(authUsecase))
+	jobScheduler.Start()
+
+	// Deliver queued notifications and webhook events
+	deliveryScheduler := scheduler.New(cfg.Notify.PollInterval, scheduler.SystemClock())
+	deliveryScheduler.Register("notification_delivery", scheduler.NewNotificationDeliveryJob(notificationUsecase))
+	deliveryScheduler.Register("webhook_delivery", scheduler.NewWebhookDeliveryJob(webhookUsecase))
+	deliveryScheduler.Start()
+	schedulers := []*scheduler.Scheduler{jobScheduler, deliveryScheduler}
+
+	// Telegram bot commands, pushed to the webhook route below or polled
+	telegramBotUsecase := usecase.NewTelegramBotUsecase(userTokenRepo, notificationPrefRepo, billUsecase, telegramService, cfg.Telegram)
+	if cfg.Telegram.UpdateMode != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := telegramBotUsecase.RegisterWebhook(ctx); err != nil {
+			utils.LogError("TELEGRAM_WEBHOOK", err)
+		}
+		cancel()
+	}
+	if cfg.Telegram.UpdateMode == config.TelegramUpdatesPolling {
+		// Each poll waits for updates itself, so the next one starts right away
+		botScheduler := scheduler.New(time.Second, scheduler.SystemClock())
+		botScheduler.Register("telegram_updates", scheduler.NewTelegramUpdatesJob(telegramBotUsecase))
+		botScheduler.Start()
+		schedulers = append(schedulers, botScheduler)
+	}
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authUsecase)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorUsecase)
 	billHandler := handler.NewBillHandler(billUsecase)
-	dashboardHandler := handler.NewDashboardHandler(billUsecase)
+	recurrenceHandler := handler.NewRecurrenceHandler(recurrenceUsecase)
+	paymentHandler := handler.NewPaymentHandler(billUsecase)
+	categoryHandler := handler.NewCategoryHandler(categoryUsecase)
+	budgetHandler := handler.NewBudgetHandler(budgetUsecase)
+	dashboardHandler := handler.NewDashboardHandler(billUsecase, budgetUsecase)
 	notificationHandler := handler.NewNotificationHandler(notificationUsecase)
+	webhookHandler := handler.NewWebhookHandler(webhookUsecase)
+	telegramHandler := handler.NewTelegramHandler(telegramBotUsecase, cfg.Telegram.WebhookSecret)
+	healthHandler := handler.NewHealthHandler(db)
 
 	// Setup router
-	r := gin.Default()
+	r := gin.New()
+
+	// Request ID and logging middleware
+	r.Use(middleware.RequestID())
+	r.Use(middleware.LoggingMiddleware(cfg.Logging))
 
-	// Logging middleware
-	r.Use(middleware.LoggingMiddleware())
+	// Error responses: panics and errors attached by handlers become the
+	// standard error body
+	r.Use(middleware.Recovery())
+	r.Use(middleware.ErrorHandler())
+	r.NoRoute(middleware.NotFound())
 
 	// CORS middleware
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -63,45 +170,48 @@ func main() {
 		c.Next()
 	})
 
-	// Health check endpoint
-	r.GET("/api/v1/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-			"service": "financebroke-api",
-			"version": "1.0.0",
-		})
-	})
-
-	// Ready check endpoint
-	r.GET("/api/v1/ready", func(c *gin.Context) {
-		// Check database connection
-		if database.DB == nil {
-			c.JSON(503, gin.H{
-				"status": "not ready",
-				"error": "database not connected",
-			})
-			return
-		}
-
-		c.JSON(200, gin.H{
-			"status": "ready",
-			"database": "connected",
-		})
-	})
+	// Health and readiness probes
+	r.GET("/api/v1/health", middleware.DisableRequestLogging(), healthHandler.Health)
+	r.GET("/api/v1/ready", middleware.DisableRequestLogging(), healthHandler.Ready)
 
 	// Public routes
 	public := r.Group("/api/v1")
 	{
 		public.POST("/register", authHandler.Register)
 		public.POST("/login", authHandler.Login)
+		public.POST("/login/2fa", authHandler.LoginTwoFactor)
+		public.POST("/refresh", authHandler.Refresh)
+		public.POST("/password/forgot", authHandler.ForgotPassword)
+		public.POST("/password/reset", authHandler.ResetPassword)
+		public.POST("/email/verify", authHandler.VerifyEmail)
+	}
+
+	// Updates pushed by Telegram, authenticated by the webhook secret
+	if cfg.Telegram.UpdateMode == config.TelegramUpdatesWebhook {
+		r.POST("/api/v1/telegram/webhook", middleware.DisableBodyLogging(), telegramHandler.Webhook)
 	}
 
 	// Protected routes
 	protected := r.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(tokenManager, authUsecase))
 	{
 		// Auth
 		protected.GET("/profile", authHandler.GetProfile)
+		protected.PUT("/profile", authHandler.UpdateProfile)
+		protected.DELETE("/profile", authHandler.DeleteAccount)
+		protected.POST("/profile/restore", authHandler.RestoreAccount)
+		protected.PUT("/profile/password", authHandler.ChangePassword)
+		protected.POST("/profile/email", authHandler.ChangeEmail)
+		protected.POST("/logout", authHandler.Logout)
+		protected.POST("/logout-all", authHandler.LogoutAll)
+		protected.POST("/email/verify/resend", authHandler.ResendVerification)
+
+		// Two-factor authentication
+		protected.GET("/2fa", twoFactorHandler.GetStatus)
+		protected.POST("/2fa/enroll", twoFactorHandler.Enroll)
+		protected.POST("/2fa/confirm", twoFactorHandler.Confirm)
+		protected.POST("/2fa/disable", twoFactorHandler.Disable)
+		protected.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
 
 		// Bills
 		protected.GET("/bills", billHandler.GetBills)
@@ -111,20 +221,153 @@ func main() {
 		protected.DELETE("/bills/:id", billHandler.DeleteBill)
 		protected.GET("/bills/upcoming", billHandler.GetUpcomingBills)
 
+		// Payments
+		protected.GET("/bills/:id/payments", paymentHandler.GetPayments)
+		protected.POST("/bills/:id/payments", paymentHandler.RecordPayment)
+		protected.POST("/bills/:id/payments/:paymentId/void", paymentHandler.VoidPayment)
+
+		// Recurring bills
+		protected.GET("/recurrences", recurrenceHandler.GetRecurrences)
+		protected.GET("/recurrences/:id", recurrenceHandler.GetRecurrence)
+		protected.PUT("/recurrences/:id", recurrenceHandler.UpdateRecurrence)
+		protected.DELETE("/recurrences/:id", recurrenceHandler.DeleteRecurrence)
+
+		// Categories and tags
+		protected.GET("/categories", categoryHandler.GetCategories)
+		protected.POST("/categories", categoryHandler.CreateCategory)
+		protected.PUT("/categories/:id", categoryHandler.UpdateCategory)
+		protected.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+		protected.GET("/tags", categoryHandler.GetTags)
+		protected.POST("/tags", categoryHandler.CreateTag)
+		protected.PUT("/tags/:id", categoryHandler.UpdateTag)
+		protected.DELETE("/tags/:id", categoryHandler.DeleteTag)
+
+		// Budgets
+		protected.GET("/budgets", budgetHandler.GetBudgets)
+		protected.POST("/budgets", budgetHandler.CreateBudget)
+		protected.PUT("/budgets/:id", budgetHandler.UpdateBudget)
+		protected.DELETE("/budgets/:id", budgetHandler.DeleteBudget)
+
 		// Dashboard
 		protected.GET("/dashboard", dashboardHandler.GetDashboard)
 
 		// Notifications
-		protected.PUT("/notifications/settings", notificationHandler.UpdateNotificationSettings)
-		protected.POST("/notifications/test-telegram", notificationHandler.TestTelegram)
+		protected.GET("/notifications", notificationHandler.GetNotifications)
+		protected.GET("/notifications/settings", notificationHandler.GetNotificationSettings)
+		// Slack and Discord addresses are webhook URLs that act as secrets
+		protected.PUT("/notifications/settings", middleware.DisableBodyLogging(), notificationHandler.UpdateNotificationSettings)
+		protected.POST("/notifications/test", notificationHandler.SendTest)
+		protected.POST("/notifications/telegram/link", telegramHandler.CreateLink)
+
+		// Webhooks
+		protected.GET("/webhooks", webhookHandler.GetWebhooks)
+		// Endpoint URLs can carry credentials, so their bodies are not logged
+		protected.POST("/webhooks", middleware.DisableBodyLogging(), webhookHandler.CreateWebhook)
+		protected.PUT("/webhooks/:id", middleware.DisableBodyLogging(), webhookHandler.UpdateWebhook)
+		protected.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		protected.POST("/webhooks/:id/test", webhookHandler.TestWebhook)
+		protected.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries)
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	serverErr := make(chan error, 1)
+	go func() {
+		utils.GetLogger().Info("[SERVER] Listening", map[string]interface{}{"port": cfg.Server.Port})
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	stop, cancelSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancelSignals()
+
+	select {
+	case err := <-serverErr:
+		utils.LogError("SERVER", err)
+		for _, s := range schedulers {
+			s.Stop()
+		}
+		db.Close()
+		os.Exit(1)
+	case <-stop.Done():
+	}
+	// A second signal kills the process without waiting for the drain
+	cancelSignals()
+
+	shutdown(cfg.Server, srv, healthHandler, schedulers, notificationUsecase, webhookUsecase, authUsecase, db)
+}
+
+// shutdown stops the server in order: readiness fails first so the proxy
+// stops sending traffic, then the listener closes and in-flight requests are
+// drained, background jobs are cancelled, queued notifications, webhook events
+// and pending emails are delivered and finally the database pool is closed.
+func shutdown(
+	cfg config.ServerConfig,
+	srv *http.Server,
+	health *handler.HealthHandler,
+	schedulers []*scheduler.Scheduler,
+	notificationUsecase usecase.NotificationUsecase,
+	webhookUsecase usecase.WebhookUsecase,
+	authUsecase usecase.AuthUsecase,
+	db *sql.DB,
+) {
+	logger := utils.GetLogger()
+	logger.Info("[SERVER] Shutting down", map[string]interface{}{
+		"delay":   cfg.ShutdownDelay.String(),
+		"timeout": cfg.ShutdownTimeout.String(),
+	})
+
+	health.MarkShuttingDown()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		utils.LogError("SERVER_SHUTDOWN", err)
+	}
+	for _, s := range schedulers {
+		s.Stop()
+	}
+	// Whatever is still queued afterwards is sent after the next start
+	if _, err := notificationUsecase.DeliverPending(ctx, time.Now()); err != nil {
+		utils.LogError("SERVER_SHUTDOWN_NOTIFICATIONS", err)
+	}
+	if _, err := webhookUsecase.DeliverPending(ctx, time.Now()); err != nil {
+		utils.LogError("SERVER_SHUTDOWN_WEBHOOKS", err)
+	}
+	if err := authUsecase.Shutdown(ctx); err != nil {
+		utils.LogError("SERVER_SHUTDOWN_EMAILS", err)
+	}
+	if err := db.Close(); err != nil {
+		utils.LogError("SERVER_SHUTDOWN_DATABASE", err)
+	}
+
+	logger.Info("[SERVER] Stopped")
+}
+
+func runMigrations(db *sql.DB) {
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		utils.GetLogger().Info("[MIGRATE] Applied migration", map[string]interface{}{
+			"version": migration.Version,
+			"name":    migration.Name,
+		})
+	}
+	if err != nil {
+		log.Fatal("Failed to apply migrations:", err)
 	}
+}
 
-	log.Printf("Server running on port %s", port)
-	r.Run(":" + port)
-}
\ No newline at end of file
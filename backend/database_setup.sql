@@ -1,71 +0,0 @@
--- Full database setup script
--- Run this script to create the database and tables
-
--- Create database (run this manually in psql: CREATE DATABASE financetok;)
-
--- Connect to the database first, then run:
-
--- Create users table
-CREATE TABLE IF NOT EXISTS users (
-    id SERIAL PRIMARY KEY,
-    email VARCHAR(255) UNIQUE NOT NULL,
-    password VARCHAR(255) NOT NULL,
-    name VARCHAR(255) NOT NULL,
-    telegram_chat_id VARCHAR(255),
-    email_notify BOOLEAN DEFAULT true,
-    telegram_notify BOOLEAN DEFAULT false,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-);
-
--- Create bills table
-CREATE TABLE IF NOT EXISTS bills (
-    id SERIAL PRIMARY KEY,
-    user_id INTEGER NOT NULL,
-    name VARCHAR(255) NOT NULL,
-    amount DECIMAL(10,2) NOT NULL,
-    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
-    description TEXT,
-    status VARCHAR(50) DEFAULT 'unpaid' CHECK (status IN ('unpaid', 'paid', 'overdue')),
-    remind_before INTEGER DEFAULT 3,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-);
-
--- Create indexes
-CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
-CREATE INDEX IF NOT EXISTS idx_bills_user_id ON bills(user_id);
-CREATE INDEX IF NOT EXISTS idx_bills_status ON bills(status);
-CREATE INDEX IF NOT EXISTS idx_bills_due_date ON bills(due_date);
-
--- Create function for updating updated_at
-CREATE OR REPLACE FUNCTION update_updated_at_column()
-RETURNS TRIGGER AS $$
-BEGIN
-    NEW.updated_at = CURRENT_TIMESTAMP;
-    RETURN NEW;
-END;
-$$ language 'plpgsql';
-
--- Create triggers
-CREATE TRIGGER update_users_updated_at
-    BEFORE UPDATE ON users
-    FOR EACH ROW
-    EXECUTE FUNCTION update_updated_at_column();
-
-CREATE TRIGGER update_bills_updated_at
-    BEFORE UPDATE ON bills
-    FOR EACH ROW
-    EXECUTE FUNCTION update_updated_at_column();
-
--- Insert sample data (optional)
--- Uncomment the lines below to insert sample data
-
--- INSERT INTO users (email, password, name) VALUES
--- ('user@example.com', '$2a$10$rOZXp7mGXmHWK7vJtxB7uO5D3Q7J8Y.rKJ5L9n8mJ4q8wW2x6v0Oi', 'Test User');
-
--- INSERT INTO bills (user_id, name, amount, due_date, description, status) VALUES
--- (1, 'Electricity Bill', 150000.00, '2024-10-15', 'Monthly electricity bill', 'unpaid'),
--- (1, 'Internet Bill', 250000.00, '2024-10-20', 'Monthly internet subscription', 'unpaid'),
--- (1, 'Water Bill', 75000.00, '2024-10-10', 'Monthly water bill', 'paid');
\ No newline at end of file
@@ -0,0 +1,131 @@
+// Package apperror defines the typed errors that usecases return for expected
+// failures. Each error has a Kind, which decides the HTTP status, a stable
+// machine-readable Code and a Message that is safe to show to users. Any other
+// error reaching the HTTP layer is treated as an internal error.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error.
+type Kind string
+
+const (
+	KindBadRequest   Kind = "bad_request"
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindRateLimited  Kind = "rate_limited"
+	KindUpstream     Kind = "upstream"
+)
+
+// Error is a domain error. Errors with the same Kind and Code match under
+// errors.Is, so a sentinel still matches after WithMessage, WithDetails or
+// Wrap.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Details carries per-field information, e.g. which request fields
+	// failed validation.
+	Details map[string]string
+
+	cause error
+}
+
+func newError(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// BadRequest is for requests that cannot be understood, such as malformed
+// JSON or an unusable token.
+func BadRequest(code, message string) *Error {
+	return newError(KindBadRequest, code, message)
+}
+
+// Validation is for well-formed requests whose values are not acceptable.
+func Validation(code, message string) *Error {
+	return newError(KindValidation, code, message)
+}
+
+// Unauthorized is for missing or invalid credentials.
+func Unauthorized(code, message string) *Error {
+	return newError(KindUnauthorized, code, message)
+}
+
+// Forbidden is for authenticated users who may not perform the action, for
+// example after re-entering a wrong password.
+func Forbidden(code, message string) *Error {
+	return newError(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return newError(KindNotFound, code, message)
+}
+
+// Conflict is for requests that clash with the current state, such as a
+// duplicate name.
+func Conflict(code, message string) *Error {
+	return newError(KindConflict, code, message)
+}
+
+func RateLimited(code, message string) *Error {
+	return newError(KindRateLimited, code, message)
+}
+
+// Upstream is for failures of an external service the request depends on.
+func Upstream(code, message string) *Error {
+	return newError(KindUpstream, code, message)
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.cause)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an *Error of the same kind and code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// WithMessage returns a copy of e with a more specific message.
+func (e *Error) WithMessage(format string, args ...interface{}) *Error {
+	copied := *e
+	copied.Message = fmt.Sprintf(format, args...)
+	return &copied
+}
+
+// WithDetails returns a copy of e carrying details.
+func (e *Error) WithDetails(details map[string]string) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Wrap returns a copy of e that records cause for logging. The cause is not
+// shown to users.
+func (e *Error) Wrap(cause error) *Error {
+	copied := *e
+	copied.cause = cause
+	return &copied
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
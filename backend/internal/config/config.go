@@ -0,0 +1,554 @@
+// Package config loads the application configuration. Values come from, in
+// increasing order of precedence: built-in defaults, an optional YAML file
+// named by CONFIG_FILE, a .env file and the process environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// DefaultJWTSecret is only good for local development; Load refuses to start
+// in production while it is in use.
+const DefaultJWTSecret = "your-secret-key-change-in-production"
+
+const defaultDBPassword = "secret"
+
+// minProductionSecretLength is the shortest JWT secret accepted in production.
+const minProductionSecretLength = 32
+
+type Config struct {
+	Env         string          `yaml:"env"`
+	AutoMigrate bool            `yaml:"auto_migrate"`
+	Server      ServerConfig    `yaml:"server"`
+	Database    DatabaseConfig  `yaml:"database"`
+	JWT         JWTConfig       `yaml:"jwt"`
+	Telegram    TelegramConfig  `yaml:"telegram"`
+	Slack       ChatConfig      `yaml:"slack"`
+	Discord     ChatConfig      `yaml:"discord"`
+	SMTP        SMTPConfig      `yaml:"smtp"`
+	Scheduler   SchedulerConfig `yaml:"scheduler"`
+	Notify      NotifyConfig    `yaml:"notifications"`
+	Webhooks    WebhookConfig   `yaml:"webhooks"`
+	Logging     LoggingConfig   `yaml:"logging"`
+}
+
+// ServerConfig holds the HTTP listener settings. AppURL is the address of the
+// frontend, used to build the links sent to users by email.
+//
+// On shutdown the server first reports itself not ready for ShutdownDelay, so
+// the proxy stops sending it traffic, then gives in-flight requests and
+// background work up to ShutdownTimeout to finish.
+type ServerConfig struct {
+	Port              string        `yaml:"port"`
+	AppURL            string        `yaml:"app_url"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownDelay     time.Duration `yaml:"shutdown_delay"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+}
+
+type DatabaseConfig struct {
+	Host            string        `yaml:"host"`
+	Port            string        `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"sslmode"`
+	TimeZone        string        `yaml:"timezone"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// QueryTimeout bounds every repository call, so a slow query cannot hold
+	// a request or a scheduler run indefinitely.
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+}
+
+// DSN returns the connection string for the pgx driver.
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+		d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode, d.TimeZone)
+}
+
+// JWTConfig controls authentication tokens. TTL is the lifetime of an access
+// token; RefreshTTL is how long a session survives without being refreshed.
+type JWTConfig struct {
+	Secret     string        `yaml:"secret"`
+	TTL        time.Duration `yaml:"ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+}
+
+// Ways the Telegram bot can receive messages and button presses.
+const (
+	TelegramUpdatesWebhook = "webhook"
+	TelegramUpdatesPolling = "polling"
+)
+
+// TelegramConfig controls the Telegram bot. APIURL is the Bot API base URL,
+// which tests can point at a local fake. UpdateMode is how the bot receives
+// commands: "webhook", "polling" for local development, or empty to only send
+// notifications. In webhook mode Telegram must send WebhookSecret with every
+// update, and WebhookURL, when set, is registered with Telegram at startup.
+// BotUsername is used to build the links that connect a chat to an account.
+type TelegramConfig struct {
+	BotToken      string        `yaml:"bot_token"`
+	BotUsername   string        `yaml:"bot_username"`
+	APIURL        string        `yaml:"api_url"`
+	UpdateMode    string        `yaml:"update_mode"`
+	WebhookURL    string        `yaml:"webhook_url"`
+	WebhookSecret string        `yaml:"webhook_secret"`
+	PollTimeout   time.Duration `yaml:"poll_timeout"`
+}
+
+// ChatConfig controls a chat channel, such as Slack or Discord, that posts to
+// incoming webhooks users create in their own workspace. Users can only save
+// webhook URLs on one of Hosts.
+type ChatConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Hosts   []string `yaml:"hosts"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+// NotifyConfig controls delivery of queued notifications. Workers poll the
+// queue every PollInterval and give each send SendTimeout. A failed send is
+// retried after RetryDelay, doubling up to MaxRetryDelay, until MaxAttempts
+// sends have failed.
+type NotifyConfig struct {
+	PollInterval  time.Duration `yaml:"poll_interval"`
+	Workers       int           `yaml:"workers"`
+	SendTimeout   time.Duration `yaml:"send_timeout"`
+	MaxAttempts   int           `yaml:"max_attempts"`
+	RetryDelay    time.Duration `yaml:"retry_delay"`
+	MaxRetryDelay time.Duration `yaml:"max_retry_delay"`
+}
+
+// WebhookConfig controls delivery of bill events to user webhooks. Pending
+// deliveries are sent on the notification poll, by up to Workers at a time,
+// each waiting Timeout for the endpoint. A failed delivery is retried after
+// RetryDelay, doubling up to MaxRetryDelay, until MaxAttempts have failed.
+// An endpoint is disabled after DisableAfter failed attempts in a row.
+//
+// Endpoints on loopback, private and link-local addresses are refused unless
+// AllowPrivateNetworks is set, so users cannot make the server call into its
+// own network.
+type WebhookConfig struct {
+	Workers              int           `yaml:"workers"`
+	Timeout              time.Duration `yaml:"timeout"`
+	MaxAttempts          int           `yaml:"max_attempts"`
+	RetryDelay           time.Duration `yaml:"retry_delay"`
+	MaxRetryDelay        time.Duration `yaml:"max_retry_delay"`
+	DisableAfter         int           `yaml:"disable_after"`
+	AllowPrivateNetworks bool          `yaml:"allow_private_networks"`
+}
+
+// LoggingConfig controls log output. Level is the lowest level written (debug,
+// info, warn or error) and Format is "json" or "text". Request bodies are only
+// logged when LogBodies is set, with the JSON or form fields named in RedactFields masked
+// (matched case-insensitively at any depth) and cut off after MaxBodyBytes.
+type LoggingConfig struct {
+	Level        string   `yaml:"level"`
+	Format       string   `yaml:"format"`
+	LogBodies    bool     `yaml:"log_bodies"`
+	MaxBodyBytes int      `yaml:"max_body_bytes"`
+	RedactFields []string `yaml:"redact_fields"`
+}
+
+// DefaultRedactFields are the request fields that carry credentials or
+// one-time secrets anywhere in the API.
+var DefaultRedactFields = []string{
+	"password", "confirm_password", "current_password", "new_password",
+	"token", "refresh_token", "challenge_token", "code", "recovery_code",
+	"secret", "authorization",
+}
+
+// IsProduction reports whether the application runs in production mode.
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+func defaults() *Config {
+	return &Config{
+		Env: EnvDevelopment,
+		Server: ServerConfig{
+			Port:              "8080",
+			AppURL:            "http://localhost:3000",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownDelay:     5 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            "5432",
+			User:            "postgres",
+			Password:        defaultDBPassword,
+			Name:            "financetok",
+			SSLMode:         "disable",
+			TimeZone:        "Asia/Jakarta",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 5 * time.Minute,
+			QueryTimeout:    5 * time.Second,
+		},
+		JWT:       JWTConfig{Secret: DefaultJWTSecret, TTL: 15 * time.Minute, RefreshTTL: 30 * 24 * time.Hour},
+		Telegram:  TelegramConfig{APIURL: "https://api.telegram.org", PollTimeout: 30 * time.Second},
+		Slack:     ChatConfig{Enabled: true, Hosts: []string{"hooks.slack.com"}},
+		Discord:   ChatConfig{Enabled: true, Hosts: []string{"discord.com", "discordapp.com"}},
+		Scheduler: SchedulerConfig{Interval: 15 * time.Minute},
+		Notify: NotifyConfig{
+			PollInterval:  10 * time.Second,
+			Workers:       4,
+			SendTimeout:   30 * time.Second,
+			MaxAttempts:   6,
+			RetryDelay:    30 * time.Second,
+			MaxRetryDelay: time.Hour,
+		},
+		Webhooks: WebhookConfig{
+			Workers:       4,
+			Timeout:       10 * time.Second,
+			MaxAttempts:   8,
+			RetryDelay:    time.Minute,
+			MaxRetryDelay: 6 * time.Hour,
+			DisableAfter:  20,
+		},
+		Logging: LoggingConfig{
+			Level:        "info",
+			Format:       "json",
+			LogBodies:    true,
+			MaxBodyBytes: 2048,
+			RedactFields: append([]string(nil), DefaultRedactFields...),
+		},
+	}
+}
+
+// Load reads and validates the configuration.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	cfg := defaults()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	var errs []error
+	setString(&c.Env, "APP_ENV")
+	setBool(&c.AutoMigrate, "AUTO_MIGRATE", &errs)
+
+	setString(&c.Server.Port, "PORT")
+	setString(&c.Server.AppURL, "APP_URL")
+	setDuration(&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT", &errs)
+	setDuration(&c.Server.ReadHeaderTimeout, "SERVER_READ_HEADER_TIMEOUT", &errs)
+	setDuration(&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT", &errs)
+	setDuration(&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT", &errs)
+	setDuration(&c.Server.ShutdownDelay, "SHUTDOWN_DELAY", &errs)
+	setDuration(&c.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT", &errs)
+
+	setString(&c.Database.Host, "DB_HOST")
+	setString(&c.Database.Port, "DB_PORT")
+	setString(&c.Database.User, "DB_USER")
+	setString(&c.Database.Password, "DB_PASSWORD")
+	setString(&c.Database.Name, "DB_NAME")
+	setString(&c.Database.SSLMode, "DB_SSLMODE")
+	setString(&c.Database.TimeZone, "DB_TIMEZONE")
+	setInt(&c.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS", &errs)
+	setInt(&c.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS", &errs)
+	setDuration(&c.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME", &errs)
+	setDuration(&c.Database.QueryTimeout, "DB_QUERY_TIMEOUT", &errs)
+
+	setString(&c.JWT.Secret, "JWT_SECRET")
+	setDuration(&c.JWT.TTL, "JWT_TTL", &errs)
+	setDuration(&c.JWT.RefreshTTL, "JWT_REFRESH_TTL", &errs)
+
+	setString(&c.Telegram.BotToken, "TELEGRAM_BOT_TOKEN")
+	setString(&c.Telegram.BotUsername, "TELEGRAM_BOT_USERNAME")
+	setString(&c.Telegram.APIURL, "TELEGRAM_API_URL")
+	setString(&c.Telegram.UpdateMode, "TELEGRAM_UPDATE_MODE")
+	setString(&c.Telegram.WebhookURL, "TELEGRAM_WEBHOOK_URL")
+	setString(&c.Telegram.WebhookSecret, "TELEGRAM_WEBHOOK_SECRET")
+	setDuration(&c.Telegram.PollTimeout, "TELEGRAM_POLL_TIMEOUT", &errs)
+
+	setBool(&c.Slack.Enabled, "SLACK_ENABLED", &errs)
+	setList(&c.Slack.Hosts, "SLACK_WEBHOOK_HOSTS")
+	setBool(&c.Discord.Enabled, "DISCORD_ENABLED", &errs)
+	setList(&c.Discord.Hosts, "DISCORD_WEBHOOK_HOSTS")
+
+	setString(&c.SMTP.Host, "SMTP_HOST")
+	setString(&c.SMTP.Port, "SMTP_PORT")
+	setString(&c.SMTP.Username, "SMTP_USERNAME")
+	setString(&c.SMTP.Password, "SMTP_PASSWORD")
+	setString(&c.SMTP.From, "FROM_EMAIL")
+
+	setDuration(&c.Scheduler.Interval, "SCHEDULER_INTERVAL", &errs)
+
+	setDuration(&c.Notify.PollInterval, "NOTIFY_POLL_INTERVAL", &errs)
+	setInt(&c.Notify.Workers, "NOTIFY_WORKERS", &errs)
+	setDuration(&c.Notify.SendTimeout, "NOTIFY_SEND_TIMEOUT", &errs)
+	setInt(&c.Notify.MaxAttempts, "NOTIFY_MAX_ATTEMPTS", &errs)
+	setDuration(&c.Notify.RetryDelay, "NOTIFY_RETRY_DELAY", &errs)
+	setDuration(&c.Notify.MaxRetryDelay, "NOTIFY_MAX_RETRY_DELAY", &errs)
+
+	setInt(&c.Webhooks.Workers, "WEBHOOK_WORKERS", &errs)
+	setDuration(&c.Webhooks.Timeout, "WEBHOOK_TIMEOUT", &errs)
+	setInt(&c.Webhooks.MaxAttempts, "WEBHOOK_MAX_ATTEMPTS", &errs)
+	setDuration(&c.Webhooks.RetryDelay, "WEBHOOK_RETRY_DELAY", &errs)
+	setDuration(&c.Webhooks.MaxRetryDelay, "WEBHOOK_MAX_RETRY_DELAY", &errs)
+	setInt(&c.Webhooks.DisableAfter, "WEBHOOK_DISABLE_AFTER", &errs)
+	setBool(&c.Webhooks.AllowPrivateNetworks, "WEBHOOK_ALLOW_PRIVATE_NETWORKS", &errs)
+
+	setString(&c.Logging.Level, "LOG_LEVEL")
+	setString(&c.Logging.Format, "LOG_FORMAT")
+	setBool(&c.Logging.LogBodies, "LOG_BODIES", &errs)
+	setInt(&c.Logging.MaxBodyBytes, "LOG_MAX_BODY_BYTES", &errs)
+	setList(&c.Logging.RedactFields, "LOG_REDACT_FIELDS")
+
+	return errors.Join(errs...)
+}
+
+// Validate checks that required values are present and, in production, that
+// development defaults are not in use.
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		problems = append(problems, fmt.Sprintf("APP_ENV must be %q or %q", EnvDevelopment, EnvProduction))
+	}
+	if _, err := strconv.Atoi(c.Server.Port); err != nil {
+		problems = append(problems, "PORT must be a number")
+	}
+	if !strings.HasPrefix(c.Server.AppURL, "http://") && !strings.HasPrefix(c.Server.AppURL, "https://") {
+		problems = append(problems, "APP_URL must be an http(s) URL")
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.ReadHeaderTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 {
+		problems = append(problems, "SERVER_READ_TIMEOUT, SERVER_READ_HEADER_TIMEOUT, SERVER_WRITE_TIMEOUT and SERVER_IDLE_TIMEOUT must be positive")
+	}
+	if c.Server.ShutdownDelay < 0 {
+		problems = append(problems, "SHUTDOWN_DELAY must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "SHUTDOWN_TIMEOUT must be positive")
+	}
+	if c.Database.Host == "" || c.Database.Name == "" || c.Database.User == "" {
+		problems = append(problems, "DB_HOST, DB_NAME and DB_USER are required")
+	}
+	if c.Database.QueryTimeout <= 0 {
+		problems = append(problems, "DB_QUERY_TIMEOUT must be positive")
+	}
+	if c.JWT.Secret == "" {
+		problems = append(problems, "JWT_SECRET is required")
+	}
+	if c.JWT.TTL <= 0 {
+		problems = append(problems, "JWT_TTL must be positive")
+	}
+	if c.JWT.RefreshTTL < c.JWT.TTL {
+		problems = append(problems, "JWT_REFRESH_TTL must not be shorter than JWT_TTL")
+	}
+	if !strings.HasPrefix(c.Telegram.APIURL, "http://") && !strings.HasPrefix(c.Telegram.APIURL, "https://") {
+		problems = append(problems, "TELEGRAM_API_URL must be an http(s) URL")
+	}
+	switch c.Telegram.UpdateMode {
+	case "":
+	case TelegramUpdatesWebhook, TelegramUpdatesPolling:
+		if c.Telegram.BotToken == "" {
+			problems = append(problems, "TELEGRAM_BOT_TOKEN is required when TELEGRAM_UPDATE_MODE is set")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("TELEGRAM_UPDATE_MODE must be empty, %q or %q", TelegramUpdatesWebhook, TelegramUpdatesPolling))
+	}
+	if c.Telegram.UpdateMode == TelegramUpdatesWebhook && !validTelegramSecret(c.Telegram.WebhookSecret) {
+		problems = append(problems, "TELEGRAM_WEBHOOK_SECRET must be 1-256 letters, digits, _ or - in webhook mode")
+	}
+	if c.Telegram.PollTimeout <= 0 || c.Telegram.PollTimeout > 50*time.Second {
+		problems = append(problems, "TELEGRAM_POLL_TIMEOUT must be between 1s and 50s")
+	}
+	if c.Slack.Enabled && len(c.Slack.Hosts) == 0 {
+		problems = append(problems, "SLACK_WEBHOOK_HOSTS is required when Slack is enabled")
+	}
+	if c.Discord.Enabled && len(c.Discord.Hosts) == 0 {
+		problems = append(problems, "DISCORD_WEBHOOK_HOSTS is required when Discord is enabled")
+	}
+	if c.Scheduler.Interval <= 0 {
+		problems = append(problems, "SCHEDULER_INTERVAL must be positive")
+	}
+	if c.Notify.PollInterval <= 0 || c.Notify.SendTimeout <= 0 || c.Notify.RetryDelay <= 0 {
+		problems = append(problems, "NOTIFY_POLL_INTERVAL, NOTIFY_SEND_TIMEOUT and NOTIFY_RETRY_DELAY must be positive")
+	}
+	if c.Notify.MaxRetryDelay < c.Notify.RetryDelay {
+		problems = append(problems, "NOTIFY_MAX_RETRY_DELAY must not be shorter than NOTIFY_RETRY_DELAY")
+	}
+	if c.Notify.Workers < 1 || c.Notify.MaxAttempts < 1 {
+		problems = append(problems, "NOTIFY_WORKERS and NOTIFY_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Webhooks.Timeout <= 0 || c.Webhooks.RetryDelay <= 0 {
+		problems = append(problems, "WEBHOOK_TIMEOUT and WEBHOOK_RETRY_DELAY must be positive")
+	}
+	if c.Webhooks.MaxRetryDelay < c.Webhooks.RetryDelay {
+		problems = append(problems, "WEBHOOK_MAX_RETRY_DELAY must not be shorter than WEBHOOK_RETRY_DELAY")
+	}
+	if c.Webhooks.Workers < 1 || c.Webhooks.MaxAttempts < 1 || c.Webhooks.DisableAfter < 1 {
+		problems = append(problems, "WEBHOOK_WORKERS, WEBHOOK_MAX_ATTEMPTS and WEBHOOK_DISABLE_AFTER must be at least 1")
+	}
+	switch strings.ToLower(c.Logging.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, "LOG_LEVEL must be one of debug, info, warn or error")
+	}
+	if f := strings.ToLower(c.Logging.Format); f != "json" && f != "text" {
+		problems = append(problems, `LOG_FORMAT must be "json" or "text"`)
+	}
+	if c.Logging.MaxBodyBytes <= 0 {
+		problems = append(problems, "LOG_MAX_BODY_BYTES must be positive")
+	}
+	if c.SMTP.Host != "" && c.SMTP.Port == "" {
+		problems = append(problems, "SMTP_PORT is required when SMTP_HOST is set")
+	}
+
+	if c.IsProduction() {
+		if c.JWT.Secret == DefaultJWTSecret {
+			problems = append(problems, "JWT_SECRET must be changed from the default in production")
+		} else if len(c.JWT.Secret) < minProductionSecretLength {
+			problems = append(problems, fmt.Sprintf("JWT_SECRET must be at least %d characters in production", minProductionSecretLength))
+		}
+		if c.Database.Password == "" || c.Database.Password == defaultDBPassword {
+			problems = append(problems, "DB_PASSWORD must be set in production")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// The set helpers override a field with an environment variable when it is
+// set to a non-empty value.
+func setString(field *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*field = value
+	}
+}
+
+// setList reads a comma-separated list, dropping empty items.
+func setList(field *[]string, key string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*field = items
+}
+
+func setBool(field *bool, key string, errs *[]error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s: invalid boolean %q", key, value))
+		return
+	}
+	*field = parsed
+}
+
+func setInt(field *int, key string, errs *[]error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s: invalid number %q", key, value))
+		return
+	}
+	*field = parsed
+}
+
+func setDuration(field *time.Duration, key string, errs *[]error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("%s: invalid duration %q", key, value))
+		return
+	}
+	*field = parsed
+}
+
+// validTelegramSecret reports whether secret is accepted by Telegram as a
+// webhook secret token.
+func validTelegramSecret(secret string) bool {
+	if secret == "" || len(secret) > 256 {
+		return false
+	}
+	for _, r := range secret {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -4,58 +4,38 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
 	"time"
 
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/utils"
+
 	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/joho/godotenv"
 )
 
-var DB *sql.DB
+// Connect opens the connection pool described by cfg and checks that the
+// database is reachable.
+func Connect(cfg config.DatabaseConfig) (*sql.DB, error) {
+	utils.GetLogger().Info("[DATABASE] Connecting", map[string]interface{}{"database": cfg.Name})
 
-func Connect() {
-	err := godotenv.Load()
+	db, err := sql.Open("pgx", cfg.DSN())
 	if err != nil {
-		log.Println("No .env file found")
-	}
-
-	dbName := getEnv("DB_NAME", "financetok")
-	fmt.Printf("[DATABASE] Connecting to database: %s\n", dbName)
-
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
-		getEnv("DB_HOST", "localhost"),
-		getEnv("DB_USER", "postgres"),
-		getEnv("DB_PASSWORD", "secret"),
-		dbName,
-		getEnv("DB_PORT", "5432"),
-	)
-
-	DB, err = sql.Open("pgx", dsn)
-	if err != nil {
-		log.Fatal("[DATABASE] Failed to open database:", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Configure connection pool
-	DB.SetMaxOpenConns(25)
-	DB.SetMaxIdleConns(5)
-	DB.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err = DB.PingContext(ctx)
-	if err != nil {
-		log.Fatal("[DATABASE] Failed to ping database:", err)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	fmt.Println("[DATABASE] Database connected successfully")
+	utils.GetLogger().Info("[DATABASE] Connected")
+	return db, nil
 }
-
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return defaultValue
-}
\ No newline at end of file
@@ -1,6 +1,9 @@
 package dto
 
-import "financebroke/backend/internal/entity"
+import (
+	"financebroke/backend/internal/entity"
+	"time"
+)
 
 type RegisterRequest struct {
 	Name            string `json:"name" binding:"required"`
@@ -14,7 +17,60 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token           string `json:"token" binding:"required"`
+	Password        string `json:"password" binding:"required,min=6"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// LoginResponse is either a completed login, with the AuthResponse fields
+// set, or, for accounts with two-factor authentication, a challenge to
+// complete through POST /login/2fa.
+type LoginResponse struct {
+	*AuthResponse
+	TwoFactorRequired  bool       `json:"two_factor_required"`
+	ChallengeToken     string     `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt *time.Time `json:"challenge_expires_at,omitempty"`
+}
+
+type UpdateProfileRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ClientInfo identifies the device a session was started from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// AuthResponse carries a short-lived access token in Token, which expires at
+// ExpiresAt, and the refresh token that renews it through POST /refresh.
 type AuthResponse struct {
-	Token string      `json:"token"`
-	User  entity.User `json:"user"`
-}
\ No newline at end of file
+	Token        string      `json:"token"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	RefreshToken string      `json:"refresh_token"`
+	User         entity.User `json:"user"`
+}
@@ -2,23 +2,67 @@ package dto
 
 import "financebroke/backend/internal/entity"
 
+// Edit scopes for recurring bills, passed as the "scope" query parameter.
+const (
+	EditScopeThis   = "this"
+	EditScopeFuture = "future"
+)
+
 type BillCreateRequest struct {
-	Name         string  `json:"name" binding:"required"`
-	Amount       float64 `json:"amount" binding:"required,min=0"`
-	DueDate      string  `json:"due_date" binding:"required"`
-	Description  string  `json:"description"`
-	RemindBefore int     `json:"remind_before"`
+	Name         string             `json:"name" binding:"required"`
+	Amount       entity.Money       `json:"amount" binding:"gt=0"`
+	DueDate      string             `json:"due_date" binding:"required"`
+	Description  string             `json:"description"`
+	RemindBefore int                `json:"remind_before"`
+	CategoryID   *uint              `json:"category_id"`
+	Tags         []string           `json:"tags"`
+	Recurrence   *RecurrenceRequest `json:"recurrence"`
 }
 
+// BillUpdateRequest edits a bill. Status is derived from the bill's payments;
+// setting it to "paid" records a payment for the outstanding balance.
+// CategoryID 0 removes the category and Tags, when present, replaces the
+// bill's tags.
 type BillUpdateRequest struct {
-	Name         string  `json:"name"`
-	Amount       float64 `json:"amount"`
-	DueDate      string  `json:"due_date"`
-	Description  string  `json:"description"`
-	Status       string  `json:"status"`
-	RemindBefore int     `json:"remind_before"`
+	Name         string       `json:"name"`
+	Amount       entity.Money `json:"amount"`
+	DueDate      string       `json:"due_date"`
+	Description  string       `json:"description"`
+	Status       string       `json:"status"`
+	RemindBefore int          `json:"remind_before"`
+	CategoryID   *uint        `json:"category_id"`
+	Tags         *[]string    `json:"tags"`
 }
 
 type BillResponse struct {
 	entity.Bill
-}
\ No newline at end of file
+}
+
+// BillListQuery holds the query parameters of GET /bills. Status accepts a
+// comma-separated list; Sort is a field name, prefixed with "-" for
+// descending order.
+type BillListQuery struct {
+	Status     string `form:"status"`
+	DueFrom    string `form:"due_from"`
+	DueTo      string `form:"due_to"`
+	MinAmount  string `form:"min_amount"`
+	MaxAmount  string `form:"max_amount"`
+	Search     string `form:"q"`
+	CategoryID uint   `form:"category_id"`
+	Tag        string `form:"tag"`
+	Sort       string `form:"sort" binding:"omitempty,oneof=due_date -due_date amount -amount name -name status -status created_at -created_at"`
+	Page       int    `form:"page" binding:"min=0"`
+	PageSize   int    `form:"page_size" binding:"min=0,max=100"`
+}
+
+type BillListResponse struct {
+	Data       []entity.Bill `json:"data"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+type BudgetCreateRequest struct {
+	CategoryID uint         `json:"category_id" binding:"required"`
+	Amount     entity.Money `json:"amount" binding:"required,gt=0"`
+}
+
+type BudgetUpdateRequest struct {
+	Amount entity.Money `json:"amount" binding:"required,gt=0"`
+}
+
+// BudgetStatusResponse is a budget compared against the bills of one month.
+type BudgetStatusResponse struct {
+	ID           uint         `json:"id"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	PeriodStart  time.Time    `json:"period_start"`
+	PeriodEnd    time.Time    `json:"period_end"`
+	Amount       entity.Money `json:"amount"`
+	Spent        entity.Money `json:"spent"`
+	Paid         entity.Money `json:"paid"`
+	Remaining    entity.Money `json:"remaining"`
+	PercentUsed  float64      `json:"percent_used"`
+	Threshold    int          `json:"threshold"`
+}
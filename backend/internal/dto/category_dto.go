@@ -0,0 +1,10 @@
+package dto
+
+type CategoryRequest struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Color string `json:"color" binding:"max=20"`
+}
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
@@ -3,18 +3,33 @@ package dto
 import "financebroke/backend/internal/entity"
 
 type DashboardResponse struct {
-	TotalBills    int64          `json:"total_bills"`
-	PaidBills     int64          `json:"paid_bills"`
-	UnpaidBills   int64          `json:"unpaid_bills"`
-	OverdueBills  int64          `json:"overdue_bills"`
-	UpcomingBills []entity.Bill  `json:"upcoming_bills"`
-	RecentBills   []entity.Bill  `json:"recent_bills"`
-	Summary       BillSummary    `json:"summary"`
+	TotalBills         int64                  `json:"total_bills"`
+	PaidBills          int64                  `json:"paid_bills"`
+	UnpaidBills        int64                  `json:"unpaid_bills"`
+	PartiallyPaidBills int64                  `json:"partially_paid_bills"`
+	OverdueBills       int64                  `json:"overdue_bills"`
+	UpcomingBills      []entity.Bill          `json:"upcoming_bills"`
+	RecentBills        []entity.Bill          `json:"recent_bills"`
+	Summary            BillSummary            `json:"summary"`
+	Categories         []CategorySummary      `json:"categories"`
+	Budgets            []BudgetStatusResponse `json:"budgets"`
 }
 
 type BillSummary struct {
-	TotalAmount    float64 `json:"total_amount"`
-	PaidAmount     float64 `json:"paid_amount"`
-	UnpaidAmount   float64 `json:"unpaid_amount"`
-	OverdueAmount  float64 `json:"overdue_amount"`
-}
\ No newline at end of file
+	TotalAmount   entity.Money `json:"total_amount"`
+	PaidAmount    entity.Money `json:"paid_amount"`
+	UnpaidAmount  entity.Money `json:"unpaid_amount"`
+	OverdueAmount entity.Money `json:"overdue_amount"`
+}
+
+// CategorySummary totals the bills in one category. CategoryID is null for
+// uncategorized bills.
+type CategorySummary struct {
+	CategoryID   *uint        `json:"category_id"`
+	Name         string       `json:"name"`
+	Color        string       `json:"color"`
+	BillCount    int64        `json:"bill_count"`
+	TotalAmount  entity.Money `json:"total_amount"`
+	PaidAmount   entity.Money `json:"paid_amount"`
+	UnpaidAmount entity.Money `json:"unpaid_amount"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+// ErrorResponse is the body of every error response. ErrorCode is stable and
+// meant for programs; Error is a message for people.
+type ErrorResponse struct {
+	Error     string            `json:"error"`
+	ErrorCode string            `json:"error_code"`
+	Details   map[string]string `json:"details,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
@@ -1,11 +1,54 @@
 package dto
 
+import (
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+// NotificationSettingsRequest updates the listed channels. Address is where
+// the user is reached on the channel, such as a Slack webhook URL. Linked
+// channels such as Telegram keep the address saved by linking them.
 type NotificationSettingsRequest struct {
-	TelegramNotify  bool   `json:"telegram_notify"`
-	EmailNotify     bool   `json:"email_notify"`
-	TelegramChatID  string `json:"telegram_chat_id"`
+	Channels []NotificationChannelSetting `json:"channels" binding:"required,dive"`
+}
+
+type NotificationChannelSetting struct {
+	Channel string `json:"channel" binding:"required"`
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address" binding:"max=255"`
+}
+
+// NotificationChannelResponse is the user's setting for one channel.
+// Available is false when the server has not been set up to use the channel.
+type NotificationChannelResponse struct {
+	Channel   string `json:"channel"`
+	Enabled   bool   `json:"enabled"`
+	Address   string `json:"address"`
+	Available bool   `json:"available"`
+}
+
+type TestNotificationRequest struct {
+	Channel string `json:"channel" binding:"required"`
+	Message string `json:"message" binding:"required,max=1000"`
+}
+
+type NotificationListQuery struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending sending sent failed"`
+	Page     int    `form:"page" binding:"min=0"`
+	PageSize int    `form:"page_size" binding:"min=0,max=100"`
 }
 
-type TestTelegramRequest struct {
-	Message string `json:"message" binding:"required"`
-}
\ No newline at end of file
+type NotificationListResponse struct {
+	Data       []entity.QueuedNotification `json:"data"`
+	Pagination Pagination                  `json:"pagination"`
+}
+
+// TelegramLinkResponse is a one-time token that connects a Telegram chat to
+// the account. Link opens the bot with the token filled in; Command is what
+// to send the bot instead.
+type TelegramLinkResponse struct {
+	Token     string    `json:"token"`
+	Link      string    `json:"link,omitempty"`
+	Command   string    `json:"command"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
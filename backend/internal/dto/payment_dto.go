@@ -0,0 +1,24 @@
+package dto
+
+import "financebroke/backend/internal/entity"
+
+// PaymentCreateRequest records a payment against a bill. PaidAt accepts a date
+// (2006-01-02) or an RFC 3339 timestamp and defaults to now.
+type PaymentCreateRequest struct {
+	Amount    entity.Money `json:"amount" binding:"required,gt=0"`
+	PaidAt    string       `json:"paid_at"`
+	Method    string       `json:"method" binding:"omitempty,oneof=cash bank_transfer card e_wallet other"`
+	Reference string       `json:"reference"`
+	Note      string       `json:"note"`
+}
+
+type PaymentVoidRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PaymentResponse returns the affected payment together with the bill, whose
+// paid amount and status are derived from the ledger.
+type PaymentResponse struct {
+	Payment entity.Payment `json:"payment"`
+	Bill    entity.Bill    `json:"bill"`
+}
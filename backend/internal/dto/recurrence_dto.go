@@ -0,0 +1,21 @@
+package dto
+
+// RecurrenceRequest makes a new bill repeat. The bill's due date is the first
+// occurrence. EndDate and Count are optional limits; Interval defaults to 1.
+type RecurrenceRequest struct {
+	Frequency string `json:"frequency" binding:"required,oneof=daily weekly monthly yearly"`
+	Interval  int    `json:"interval" binding:"min=0"`
+	EndDate   string `json:"end_date"`
+	Count     int    `json:"count" binding:"min=0"`
+}
+
+// RecurrenceUpdateRequest changes the schedule of an existing recurrence.
+// Omitted fields are left unchanged; an empty EndDate or a zero Count removes
+// that limit.
+type RecurrenceUpdateRequest struct {
+	Frequency string  `json:"frequency" binding:"omitempty,oneof=daily weekly monthly yearly"`
+	Interval  int     `json:"interval" binding:"min=0"`
+	EndDate   *string `json:"end_date"`
+	Count     *int    `json:"count" binding:"omitempty,min=0"`
+	Active    *bool   `json:"active"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorEnrollResponse carries the secret for manual entry and the
+// otpauth URI to render as a QR code.
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type PasswordConfirmRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// TwoFactorLoginRequest completes a login with either a code from the
+// authenticator app or one of the recovery codes.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode   string `json:"recovery_code"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+// WebhookRequest creates a webhook. Events are the bill events it receives,
+// from entity.BillEvents.
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2048"`
+	Events      []string `json:"events" binding:"required,min=1,dive,required"`
+	Description string   `json:"description" binding:"max=255"`
+}
+
+// WebhookUpdateRequest changes the fields that are set. Enabling a webhook
+// that was disabled after failing clears its failure count.
+type WebhookUpdateRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,max=2048"`
+	Events      []string `json:"events" binding:"omitempty,min=1,dive,required"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+// WebhookCreatedResponse is the only response that includes the secret used
+// to sign the webhook's deliveries.
+type WebhookCreatedResponse struct {
+	entity.Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryListQuery struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending sending delivered failed"`
+	Page     int    `form:"page" binding:"min=0"`
+	PageSize int    `form:"page_size" binding:"min=0,max=100"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Data       []entity.WebhookDelivery `json:"data"`
+	Pagination Pagination               `json:"pagination"`
+}
+
+// WebhookEvent is the JSON body posted to webhooks. Data holds the bill for
+// bill events and a short message for pings.
+type WebhookEvent struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      map[string]interface{} `json:"data"`
+}
@@ -2,15 +2,47 @@ package entity
 
 import "time"
 
+const (
+	BillStatusUnpaid        = "unpaid"
+	BillStatusPartiallyPaid = "partially_paid"
+	BillStatusPaid          = "paid"
+	BillStatusOverdue       = "overdue"
+)
+
 type Bill struct {
-	ID           uint      `json:"id"`
-	UserID       uint      `json:"user_id"`
-	Name         string    `json:"name"`
-	Amount       float64   `json:"amount"`
-	DueDate      time.Time `json:"due_date"`
-	Description  string    `json:"description"`
-	Status       string    `json:"status"`
-	RemindBefore int       `json:"remind_before"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-}
\ No newline at end of file
+	ID                uint       `json:"id"`
+	UserID            uint       `json:"user_id"`
+	Name              string     `json:"name"`
+	Amount            Money      `json:"amount"`
+	PaidAmount        Money      `json:"paid_amount"`
+	DueDate           time.Time  `json:"due_date"`
+	Description       string     `json:"description"`
+	Status            string     `json:"status"`
+	RemindBefore      int        `json:"remind_before"`
+	RemindedAt        *time.Time `json:"reminded_at,omitempty"`
+	OverdueNotifiedAt *time.Time `json:"overdue_notified_at,omitempty"`
+	// ReminderSnoozedUntil holds back the bill's reminder or overdue notice,
+	// which is sent again once this time has passed.
+	ReminderSnoozedUntil *time.Time `json:"reminder_snoozed_until,omitempty"`
+	RecurrenceID         *uint      `json:"recurrence_id,omitempty"`
+	OccurrenceIndex      *int       `json:"occurrence_index,omitempty"`
+	CategoryID           *uint      `json:"category_id"`
+	Tags                 []BillTag  `json:"tags"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// BillTag is the short form of a Tag embedded in a bill.
+type BillTag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// IsValidBillStatus reports whether status is one the bills table accepts.
+func IsValidBillStatus(status string) bool {
+	switch status {
+	case BillStatusUnpaid, BillStatusPartiallyPaid, BillStatusPaid, BillStatusOverdue:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// Budget alert thresholds, as a percentage of the budget's limit.
+const (
+	BudgetThresholdWarning  = 80
+	BudgetThresholdExceeded = 100
+)
+
+// Budget is a monthly spending limit for one category. The same limit applies
+// to every calendar month.
+type Budget struct {
+	ID         uint      `json:"id"`
+	UserID     uint      `json:"user_id"`
+	CategoryID uint      `json:"category_id"`
+	Amount     Money     `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// BudgetUsage is a budget compared against one month of bills. Spent is the
+// total of the category's bills due in the period and Paid the part of it
+// already covered by payments. AlertedThreshold is the highest threshold
+// already announced for the period, or 0.
+type BudgetUsage struct {
+	Budget
+	CategoryName     string    `json:"category_name"`
+	PeriodStart      time.Time `json:"period_start"`
+	PeriodEnd        time.Time `json:"period_end"`
+	Spent            Money     `json:"spent"`
+	Paid             Money     `json:"paid"`
+	AlertedThreshold int       `json:"-"`
+}
+
+// Remaining is what is left of the limit; it is negative once overspent.
+func (u BudgetUsage) Remaining() Money {
+	return u.Amount - u.Spent
+}
+
+// PercentUsed is Spent as a percentage of the limit.
+func (u BudgetUsage) PercentUsed() float64 {
+	if u.Amount <= 0 {
+		return 0
+	}
+	return float64(u.Spent) / float64(u.Amount) * 100
+}
+
+// CrossedThreshold returns the highest alert threshold Spent has reached, or
+// 0 when it is below all of them.
+func (u BudgetUsage) CrossedThreshold() int {
+	switch {
+	case u.Amount <= 0:
+		return 0
+	case u.Spent >= u.Amount:
+		return BudgetThresholdExceeded
+	case u.Spent*5 >= u.Amount*4:
+		return BudgetThresholdWarning
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package entity
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact monetary amount stored as integer minor units (sen for
+// Rupiah, i.e. hundredths). It marshals to and from JSON as a plain decimal
+// number with two fractional digits and maps to NUMERIC(18,2) columns, so
+// amounts never pass through float64.
+type Money int64
+
+// MaxMoney is the largest amount a NUMERIC(18,2) column can hold.
+const MaxMoney Money = 999999999999999999
+
+var (
+	ErrInvalidMoney    = errors.New("amount must be a decimal number")
+	ErrNegativeMoney   = errors.New("amount must not be negative")
+	ErrMoneyTooPrecise = errors.New("amount must have at most 2 decimal places")
+	ErrMoneyOutOfRange = errors.New("amount is too large")
+)
+
+// ParseMoney parses a non-negative decimal string such as "150000" or
+// "99.95". Exponents, signs and more than two decimal places are rejected.
+func ParseMoney(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, ErrInvalidMoney
+	}
+	if strings.HasPrefix(value, "-") {
+		return 0, ErrNegativeMoney
+	}
+
+	whole, fraction, hasPoint := strings.Cut(value, ".")
+	if whole == "" || (hasPoint && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidMoney
+	}
+
+	// Trailing zeros beyond the second decimal place carry no precision.
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > 2 {
+		return 0, ErrMoneyTooPrecise
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	whole = strings.TrimLeft(whole, "0")
+	if len(whole) > 16 {
+		return 0, ErrMoneyOutOfRange
+	}
+
+	minor, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrMoneyOutOfRange
+	}
+
+	amount := Money(minor)
+	if amount > MaxMoney {
+		return 0, ErrMoneyOutOfRange
+	}
+	return amount, nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount with exactly two decimal places, e.g. "150000.00".
+func (m Money) String() string {
+	sign := ""
+	minor := int64(m)
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts either a JSON number or a numeric string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	value := string(data)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	amount, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+
+	*m = amount
+	return nil
+}
+
+// Scan reads a NUMERIC value from the database.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case int64:
+		*m = Money(v * 100)
+		return nil
+	case float64:
+		*m = Money(math.Round(v * 100))
+		return nil
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	}
+	return fmt.Errorf("cannot scan %T into Money", src)
+}
+
+func (m *Money) scanString(value string) error {
+	negative := strings.HasPrefix(value, "-")
+	amount, err := ParseMoney(strings.TrimPrefix(value, "-"))
+	if err != nil {
+		return err
+	}
+	if negative {
+		amount = -amount
+	}
+	*m = amount
+	return nil
+}
+
+// Value writes the amount as an exact decimal string.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
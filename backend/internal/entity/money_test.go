@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr error
+	}{
+		{input: "0", want: 0},
+		{input: "150000", want: 15000000},
+		{input: "99.95", want: 9995},
+		{input: "99.9", want: 9990},
+		{input: "  12.50  ", want: 1250},
+		{input: "1.2300", want: 123},
+		{input: "0007.05", want: 705},
+		{input: "9999999999999999.99", want: MaxMoney},
+		{input: "", wantErr: ErrInvalidMoney},
+		{input: "abc", wantErr: ErrInvalidMoney},
+		{input: ".5", wantErr: ErrInvalidMoney},
+		{input: "5.", wantErr: ErrInvalidMoney},
+		{input: "1e5", wantErr: ErrInvalidMoney},
+		{input: "+5", wantErr: ErrInvalidMoney},
+		{input: "1,000", wantErr: ErrInvalidMoney},
+		{input: "-1", wantErr: ErrNegativeMoney},
+		{input: "0.001", wantErr: ErrMoneyTooPrecise},
+		{input: "10.999", wantErr: ErrMoneyTooPrecise},
+		{input: "10000000000000000", wantErr: ErrMoneyOutOfRange},
+		{input: "99999999999999999999", wantErr: ErrMoneyOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMoney(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseMoney(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Money
+		wantErr bool
+	}{
+		{name: "null", src: nil, want: 0},
+		{name: "int64", src: int64(150000), want: 15000000},
+		{name: "float64", src: 99.95, want: 9995},
+		{name: "float64 rounding", src: 0.29, want: 29},
+		{name: "bytes", src: []byte("1234.56"), want: 123456},
+		{name: "string", src: "0.05", want: 5},
+		{name: "negative string", src: "-42.10", want: -4210},
+		{name: "negative bytes", src: []byte("-0.01"), want: -1},
+		{name: "invalid string", src: "n/a", wantErr: true},
+		{name: "too precise", src: "1.005", wantErr: true},
+		{name: "unsupported type", src: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Money(-999)
+			err := m.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan(%v) error = %v, wantErr %v", tt.src, err, tt.wantErr)
+			}
+			if !tt.wantErr && m != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, m, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyValue(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{amount: 0, want: "0.00"},
+		{amount: 5, want: "0.05"},
+		{amount: 9995, want: "99.95"},
+		{amount: 15000000, want: "150000.00"},
+		{amount: -4210, want: "-42.10"},
+		{amount: MaxMoney, want: "9999999999999999.99"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			v, err := tt.amount.Value()
+			if err != nil {
+				t.Fatalf("Value: %v", err)
+			}
+			if v != tt.want {
+				t.Errorf("Value() = %v, want %q", v, tt.want)
+			}
+
+			var back Money
+			if err := back.Scan(v); err != nil {
+				t.Fatalf("Scan(Value()): %v", err)
+			}
+			if back != tt.amount {
+				t.Errorf("Scan(Value()) = %d, want %d", back, tt.amount)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: `99.95`, want: 9995},
+		{input: `"99.95"`, want: 9995},
+		{input: `150000`, want: 15000000},
+		{input: `null`, want: 0},
+		{input: `-1`, wantErr: true},
+		{input: `1e3`, wantErr: true},
+		{input: `"0.001"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var m Money
+			err := json.Unmarshal([]byte(tt.input), &m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if m != tt.want {
+				t.Errorf("Unmarshal(%s) = %d, want %d", tt.input, m, tt.want)
+			}
+
+			data, err := json.Marshal(m)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if want := tt.want.String(); string(data) != want {
+				t.Errorf("Marshal = %s, want %s", data, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package entity
+
+import "time"
+
+// Kinds of notification sent to users.
+const (
+	NotificationBillReminder = "bill_reminder"
+	NotificationBillOverdue  = "bill_overdue"
+	NotificationBudgetAlert  = "budget_alert"
+	NotificationTest         = "test"
+)
+
+// Notification is a message for one user. Each channel renders it in its own
+// format from Kind and the record it is about: Bill for bill reminders and
+// overdue notices, Budget and Threshold for budget alerts, and Text for test
+// messages.
+type Notification struct {
+	Kind      string       `json:"kind"`
+	User      User         `json:"user"`
+	Bill      *Bill        `json:"bill,omitempty"`
+	Budget    *BudgetUsage `json:"budget,omitempty"`
+	Threshold int          `json:"threshold,omitempty"`
+	Text      string       `json:"text,omitempty"`
+}
+
+// NotificationPreference is a user's setting for one notification channel.
+// Address is where the user is reached on the channel, such as a Telegram chat
+// ID; channels that deliver to the account's email address ignore it.
+type NotificationPreference struct {
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+}
+
+// Delivery states of a queued notification.
+const (
+	NotificationPending = "pending"
+	NotificationSending = "sending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+)
+
+// QueuedNotification is a notification in the outbox, addressed to one
+// channel. It stays pending until a worker delivers it, and is marked failed
+// once MaxAttempts deliveries have failed. Notifications about a bill are
+// unique per BillID, Channel, Kind and Reference.
+type QueuedNotification struct {
+	ID               uint         `json:"id"`
+	UserID           uint         `json:"user_id"`
+	Channel          string       `json:"channel"`
+	Kind             string       `json:"kind"`
+	BillID           *uint        `json:"bill_id"`
+	Reference        string       `json:"-"`
+	Recipient        string       `json:"-"`
+	Payload          Notification `json:"-"`
+	Status           string       `json:"status"`
+	Attempts         int          `json:"attempts"`
+	MaxAttempts      int          `json:"max_attempts"`
+	NextAttemptAt    time.Time    `json:"next_attempt_at"`
+	LastError        string       `json:"last_error"`
+	ProviderResponse string       `json:"provider_response"`
+	SentAt           *time.Time   `json:"sent_at"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	PaymentMethodCash         = "cash"
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodCard         = "card"
+	PaymentMethodEWallet      = "e_wallet"
+	PaymentMethodOther        = "other"
+)
+
+// Payment is a single entry in a bill's payment ledger. Payments are never
+// deleted; a mistaken entry is voided and no longer counts towards the bill.
+type Payment struct {
+	ID         uint       `json:"id"`
+	BillID     uint       `json:"bill_id"`
+	UserID     uint       `json:"user_id"`
+	Amount     Money      `json:"amount"`
+	PaidAt     time.Time  `json:"paid_at"`
+	Method     string     `json:"method"`
+	Reference  string     `json:"reference"`
+	Note       string     `json:"note"`
+	VoidedAt   *time.Time `json:"voided_at,omitempty"`
+	VoidReason string     `json:"void_reason,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
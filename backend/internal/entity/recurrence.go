@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// Recurrence describes a repeating bill. Occurrence n is due at AnchorDate
+// shifted by (n - AnchorIndex) * Interval periods of Frequency; re-anchoring
+// lets "all future occurrences" edits move the schedule without touching the
+// occurrences already generated. NextIndex is the index the next generated
+// occurrence will receive. AnchorDay is the day of month monthly and yearly
+// schedules fall on when AnchorDate was clamped to a shorter month, so a bill
+// due on the 31st stays on the 31st after re-anchoring on Feb 28; zero means
+// AnchorDate's own day.
+type Recurrence struct {
+	ID             uint       `json:"id"`
+	UserID         uint       `json:"user_id"`
+	Frequency      string     `json:"frequency"`
+	Interval       int        `json:"interval"`
+	AnchorDate     time.Time  `json:"anchor_date"`
+	AnchorIndex    int        `json:"anchor_index"`
+	AnchorDay      int        `json:"anchor_day,omitempty"`
+	EndDate        *time.Time `json:"end_date,omitempty"`
+	MaxOccurrences *int       `json:"max_occurrences,omitempty"`
+	NextIndex      int        `json:"next_index"`
+	Name           string     `json:"name"`
+	Amount         Money      `json:"amount"`
+	Description    string     `json:"description"`
+	RemindBefore   int        `json:"remind_before"`
+	Active         bool       `json:"active"`
+	CategoryID     *uint      `json:"category_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (r Recurrence) Validate() error {
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+	default:
+		return errors.New("frequency must be one of daily, weekly, monthly, yearly")
+	}
+
+	if r.Interval < 1 {
+		return errors.New("interval must be at least 1")
+	}
+	if r.MaxOccurrences != nil && *r.MaxOccurrences < 1 {
+		return errors.New("count must be at least 1")
+	}
+	if r.EndDate != nil && r.EndDate.Before(r.AnchorDate) {
+		return errors.New("end date must not be before the first due date")
+	}
+
+	return nil
+}
+
+// OccurrenceDate returns the due date of the occurrence with the given index.
+// Monthly and yearly schedules keep the anchor's day of month, clamped to the
+// last day of shorter months (e.g. the 31st becomes Feb 28/29).
+func (r Recurrence) OccurrenceDate(index int) time.Time {
+	steps := (index - r.AnchorIndex) * r.Interval
+
+	switch r.Frequency {
+	case FrequencyDaily:
+		return r.AnchorDate.AddDate(0, 0, steps)
+	case FrequencyWeekly:
+		return r.AnchorDate.AddDate(0, 0, 7*steps)
+	case FrequencyYearly:
+		return addMonthsClamped(r.AnchorDate, 12*steps, r.DayOfMonth())
+	default:
+		return addMonthsClamped(r.AnchorDate, steps, r.DayOfMonth())
+	}
+}
+
+// DayOfMonth is the day monthly and yearly occurrences are due on before
+// clamping.
+func (r Recurrence) DayOfMonth() int {
+	if r.AnchorDay > 0 {
+		return r.AnchorDay
+	}
+	return r.AnchorDate.Day()
+}
+
+// IsMonthBased reports whether the frequency counts in months, so occurrences
+// keep a day of month.
+func IsMonthBased(frequency string) bool {
+	return frequency == FrequencyMonthly || frequency == FrequencyYearly
+}
+
+// HasOccurrence reports whether the occurrence with the given index falls
+// within the recurrence's count and end date limits.
+func (r Recurrence) HasOccurrence(index int) bool {
+	if index < 0 {
+		return false
+	}
+	if r.MaxOccurrences != nil && index >= *r.MaxOccurrences {
+		return false
+	}
+	if r.EndDate != nil && r.OccurrenceDate(index).After(*r.EndDate) {
+		return false
+	}
+	return true
+}
+
+// addMonthsClamped moves t by months and onto day, or the last day of the
+// target month when it is shorter.
+func addMonthsClamped(t time.Time, months, day int) time.Time {
+	year, month, _ := t.Date()
+
+	total := int(month) - 1 + months
+	year += total / 12
+	monthIndex := total % 12
+	if monthIndex < 0 {
+		monthIndex += 12
+		year--
+	}
+	target := time.Month(monthIndex + 1)
+
+	// Day 0 of the following month is the last day of the target month.
+	lastDay := time.Date(year, target+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(year, target, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAddMonthsClamped(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   time.Time
+		months int
+		day    int
+		want   time.Time
+	}{
+		{name: "same day next month", from: date(2025, 3, 15), months: 1, day: 15, want: date(2025, 4, 15)},
+		{name: "31st into February", from: date(2025, 1, 31), months: 1, day: 31, want: date(2025, 2, 28)},
+		{name: "31st into leap February", from: date(2024, 1, 31), months: 1, day: 31, want: date(2024, 2, 29)},
+		{name: "31st into a 30 day month", from: date(2025, 3, 31), months: 1, day: 31, want: date(2025, 4, 30)},
+		{name: "day restored after a short month", from: date(2025, 2, 28), months: 1, day: 31, want: date(2025, 3, 31)},
+		{name: "across the year end", from: date(2025, 11, 30), months: 3, day: 30, want: date(2026, 2, 28)},
+		{name: "backwards across the year end", from: date(2025, 1, 31), months: -2, day: 31, want: date(2024, 11, 30)},
+		{name: "backwards a whole year", from: date(2025, 1, 15), months: -12, day: 15, want: date(2024, 1, 15)},
+		{name: "leap day to the next year", from: date(2024, 2, 29), months: 12, day: 29, want: date(2025, 2, 28)},
+		{name: "leap day to the next leap year", from: date(2024, 2, 29), months: 48, day: 29, want: date(2028, 2, 29)},
+		{name: "zero months", from: date(2025, 6, 30), months: 0, day: 30, want: date(2025, 6, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addMonthsClamped(tt.from, tt.months, tt.day)
+			if !got.Equal(tt.want) {
+				t.Errorf("addMonthsClamped(%s, %d, %d) = %s, want %s",
+					tt.from.Format("2006-01-02"), tt.months, tt.day,
+					got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestAddMonthsClampedKeepsTimeOfDay(t *testing.T) {
+	loc := time.FixedZone("WIB", 7*60*60)
+	from := time.Date(2025, 1, 31, 9, 30, 0, 0, loc)
+
+	got := addMonthsClamped(from, 1, 31)
+	want := time.Date(2025, 2, 28, 9, 30, 0, 0, loc)
+	if !got.Equal(want) || got.Location() != loc {
+		t.Errorf("addMonthsClamped = %s, want %s", got, want)
+	}
+}
+
+func TestOccurrenceDate(t *testing.T) {
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		want       []time.Time
+	}{
+		{
+			name:       "daily across a leap day",
+			recurrence: Recurrence{Frequency: FrequencyDaily, Interval: 1, AnchorDate: date(2024, 2, 28)},
+			want:       []time.Time{date(2024, 2, 28), date(2024, 2, 29), date(2024, 3, 1)},
+		},
+		{
+			name:       "weekly across a month end",
+			recurrence: Recurrence{Frequency: FrequencyWeekly, Interval: 2, AnchorDate: date(2025, 1, 20)},
+			want:       []time.Time{date(2025, 1, 20), date(2025, 2, 3), date(2025, 2, 17)},
+		},
+		{
+			name:       "monthly on the 31st",
+			recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, AnchorDate: date(2025, 1, 31)},
+			want:       []time.Time{date(2025, 1, 31), date(2025, 2, 28), date(2025, 3, 31), date(2025, 4, 30), date(2025, 5, 31)},
+		},
+		{
+			name:       "monthly on the 31st in a leap year",
+			recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, AnchorDate: date(2024, 1, 31)},
+			want:       []time.Time{date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31)},
+		},
+		{
+			name:       "monthly on the 30th every other month",
+			recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 2, AnchorDate: date(2023, 12, 30)},
+			want:       []time.Time{date(2023, 12, 30), date(2024, 2, 29), date(2024, 4, 30)},
+		},
+		{
+			name:       "yearly on a leap day",
+			recurrence: Recurrence{Frequency: FrequencyYearly, Interval: 1, AnchorDate: date(2024, 2, 29)},
+			want:       []time.Time{date(2024, 2, 29), date(2025, 2, 28), date(2026, 2, 28), date(2027, 2, 28), date(2028, 2, 29)},
+		},
+		{
+			name: "re-anchored on a clamped date keeps the anchor day",
+			recurrence: Recurrence{
+				Frequency:   FrequencyMonthly,
+				Interval:    2,
+				AnchorDate:  date(2025, 2, 28),
+				AnchorIndex: 1,
+				AnchorDay:   31,
+			},
+			want: []time.Time{date(2024, 12, 31), date(2025, 2, 28), date(2025, 4, 30), date(2025, 6, 30), date(2025, 8, 31)},
+		},
+		{
+			name: "yearly re-anchored on Feb 28 returns to the leap day",
+			recurrence: Recurrence{
+				Frequency:   FrequencyYearly,
+				Interval:    1,
+				AnchorDate:  date(2025, 2, 28),
+				AnchorIndex: 1,
+				AnchorDay:   29,
+			},
+			want: []time.Time{date(2024, 2, 29), date(2025, 2, 28), date(2026, 2, 28), date(2027, 2, 28), date(2028, 2, 29)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for index, want := range tt.want {
+				got := tt.recurrence.OccurrenceDate(index)
+				if !got.Equal(want) {
+					t.Errorf("OccurrenceDate(%d) = %s, want %s",
+						index, got.Format("2006-01-02"), want.Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestDayOfMonth(t *testing.T) {
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		want       int
+	}{
+		{name: "anchor date's day", recurrence: Recurrence{AnchorDate: date(2025, 2, 28)}, want: 28},
+		{name: "explicit anchor day", recurrence: Recurrence{AnchorDate: date(2025, 2, 28), AnchorDay: 31}, want: 31},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.recurrence.DayOfMonth(); got != tt.want {
+				t.Errorf("DayOfMonth() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasOccurrence(t *testing.T) {
+	count := 3
+	end := date(2025, 4, 30)
+
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		index      int
+		want       bool
+	}{
+		{name: "negative index", recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, AnchorDate: date(2025, 1, 31)}, index: -1, want: false},
+		{name: "unbounded", recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, AnchorDate: date(2025, 1, 31)}, index: 100, want: true},
+		{name: "within count", recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, AnchorDate: date(2025, 1, 31), MaxOccurrences: &count}, index: 2, want: true},
+		{name: "past count", recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, AnchorDate: date(2025, 1, 31), MaxOccurrences: &count}, index: 3, want: false},
+		{name: "clamped date on the end date", recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, AnchorDate: date(2025, 1, 31), EndDate: &end}, index: 3, want: true},
+		{name: "after the end date", recurrence: Recurrence{Frequency: FrequencyMonthly, Interval: 1, AnchorDate: date(2025, 1, 31), EndDate: &end}, index: 4, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.recurrence.HasOccurrence(tt.index); got != tt.want {
+				t.Errorf("HasOccurrence(%d) = %v, want %v", tt.index, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// Session is one login. Its refresh tokens form a single family: each refresh
+// rotates the token, and revoking the session invalidates all of them along
+// with any access token issued for it.
+type Session struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active reports whether the session can still be used at now.
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken is a single-use token in a session's rotation chain. Only the
+// SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID        uint       `json:"id"`
+	SessionID uint       `json:"session_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// TwoFactor is a user's TOTP enrollment. It only protects logins once
+// EnabledAt is set, after the user has confirmed a code from their app.
+type TwoFactor struct {
+	UserID    uint       `json:"user_id"`
+	Secret    string     `json:"-"`
+	EnabledAt *time.Time `json:"enabled_at"`
+	LastStep  int64      `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Enabled reports whether logins require a second factor.
+func (t TwoFactor) Enabled() bool {
+	return t.EnabledAt != nil
+}
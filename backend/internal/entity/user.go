@@ -4,14 +4,27 @@ import (
 	"time"
 )
 
+// User is an account. EmailVerifiedAt is when the user confirmed their email
+// address, or nil; notifications are not emailed to unverified addresses.
+// DeletionScheduledAt is set while a deleted account is in its grace period.
 type User struct {
-	ID             uint      `json:"id"`
-	Email          string    `json:"email"`
-	Password       string    `json:"-"`
-	Name           string    `json:"name"`
-	TelegramChatID string    `json:"telegram_chat_id"`
-	EmailNotify    bool      `json:"email_notify"`
-	TelegramNotify bool      `json:"telegram_notify"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID                  uint       `json:"id"`
+	Email               string     `json:"email"`
+	Password            string     `json:"-"`
+	Name                string     `json:"name"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// EmailVerified reports whether the user has confirmed their email address.
+func (u User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// PendingDeletion reports whether the account has been deleted and is waiting
+// out its grace period.
+func (u User) PendingDeletion() bool {
+	return u.DeletionScheduledAt != nil
 }
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// User token purposes.
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+	UserTokenTwoFactorLogin    = "two_factor_login"
+	UserTokenEmailChange       = "email_change"
+	UserTokenTelegramLink      = "telegram_link"
+)
+
+// UserToken is a single-use, expiring token sent to a user, for example in a
+// password reset or email verification link. Only the SHA-256 hash of the token is stored.
+type UserToken struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	Attempts  int        `json:"attempts"`
+	Payload   string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Bill events delivered to webhooks.
+const (
+	BillEventCreated = "bill.created"
+	BillEventUpdated = "bill.updated"
+	BillEventDeleted = "bill.deleted"
+	BillEventDueSoon = "bill.due_soon"
+	BillEventOverdue = "bill.overdue"
+	BillEventPaid    = "bill.paid"
+	// WebhookEventPing is sent when a user tests an endpoint. Every endpoint
+	// receives it regardless of its events.
+	WebhookEventPing = "ping"
+)
+
+// BillEvents lists the events an endpoint can subscribe to.
+var BillEvents = []string{
+	BillEventCreated, BillEventUpdated, BillEventDeleted, BillEventDueSoon, BillEventOverdue, BillEventPaid,
+}
+
+// IsValidBillEvent reports whether event is one of BillEvents.
+func IsValidBillEvent(event string) bool {
+	for _, known := range BillEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook is an endpoint that receives the user's bill events as signed JSON
+// POST requests. It is disabled automatically after too many consecutive
+// failed deliveries, at DisabledAt, until the user enables it again.
+type Webhook struct {
+	ID                  uint       `json:"id"`
+	UserID              uint       `json:"user_id"`
+	URL                 string     `json:"url"`
+	Secret              string     `json:"-"`
+	Events              []string   `json:"events"`
+	Description         string     `json:"description"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// Subscribed reports whether the endpoint wants event.
+func (w Webhook) Subscribed(event string) bool {
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery states of a webhook event.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySending   = "sending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event queued for, or delivered to, an endpoint.
+// ResponseStatus and ResponseBody are from the endpoint's latest answer.
+type WebhookDelivery struct {
+	ID             uint            `json:"id"`
+	WebhookID      uint            `json:"webhook_id"`
+	UserID         uint            `json:"-"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	MaxAttempts    int             `json:"max_attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LockedUntil    *time.Time      `json:"-"`
+	ResponseStatus *int            `json:"response_status"`
+	ResponseBody   string          `json:"response_body"`
+	LastError      string          `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
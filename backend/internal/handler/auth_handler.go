@@ -2,7 +2,6 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 
 	"financebroke/backend/internal/dto"
 	"financebroke/backend/internal/usecase"
@@ -20,17 +19,11 @@ func NewAuthHandler(authUsecase usecase.AuthUsecase) *AuthHandler {
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
-	logger := utils.GetLogger()
-	logger.Info("[HANDLER] Register attempt")
+	logger := utils.FromContext(c.Request.Context())
+	logger.Debug("[HANDLER] Register attempt")
 
 	var req dto.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError("AUTH_HANDLER_BIND", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":      "Invalid request format",
-			"details":    err.Error(),
-			"error_code": "INVALID_REQUEST",
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -39,14 +32,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		"name":  req.Name,
 	})
 
-	response, err := h.authUsecase.Register(&req)
+	response, err := h.authUsecase.Register(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
-		utils.LogError("AUTH_HANDLER_REGISTER", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":      "Failed to create user",
-			"details":    err.Error(),
-			"error_code": "USER_CREATION_FAILED",
-		})
+		respondError(c, err, "Failed to create user")
 		return
 	}
 
@@ -59,37 +47,201 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request format",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	response, err := h.authUsecase.Login(&req)
+	response, err := h.authUsecase.Login(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Invalid credentials",
-			"details": err.Error(),
-		})
+		respondError(c, err, "Failed to log in")
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.authUsecase.LoginTwoFactor(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		respondError(c, err, "Failed to complete login")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.authUsecase.Refresh(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err, "Failed to refresh token")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.authUsecase.Logout(c.Request.Context(), c.GetUint("user_id"), c.GetUint("session_id")); err != nil {
+		respondError(c, err, "Failed to log out")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	if err := h.authUsecase.LogoutAll(c.Request.Context(), c.GetUint("user_id")); err != nil {
+		respondError(c, err, "Failed to log out")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions successfully"})
+}
+
+// ForgotPassword always answers the same way so that it cannot be used to
+// find out which email addresses are registered.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	h.authUsecase.ForgotPassword(c.Request.Context(), &req)
+
+	c.JSON(http.StatusOK, gin.H{"message": "If an account exists for this email, a password reset link has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authUsecase.ResetPassword(c.Request.Context(), &req); err != nil {
+		respondError(c, err, "Failed to reset password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authUsecase.VerifyEmail(c.Request.Context(), &req); err != nil {
+		respondError(c, err, "Failed to verify email")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	err := h.authUsecase.ResendVerification(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to send verification email")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+func clientInfo(c *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
 func (h *AuthHandler) GetProfile(c *gin.Context) {
-	userIDStr := c.GetUint("user_id")
-	userID, err := strconv.ParseUint(strconv.FormatUint(uint64(userIDStr), 10), 10, 32)
+	user, err := h.authUsecase.GetProfile(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	var req dto.UpdateProfileRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.authUsecase.UpdateProfile(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to update profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	err := h.authUsecase.ChangePassword(c.Request.Context(), c.GetUint("user_id"), c.GetUint("session_id"), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		respondError(c, err, "Failed to change password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+func (h *AuthHandler) ChangeEmail(c *gin.Context) {
+	var req dto.ChangeEmailRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.authUsecase.ChangeEmail(c.Request.Context(), c.GetUint("user_id"), &req); err != nil {
+		respondError(c, err, "Failed to change email")
 		return
 	}
 
-	user, err := h.authUsecase.GetProfile(uint(userID))
+	c.JSON(http.StatusOK, gin.H{"message": "Confirmation link sent to the new email address"})
+}
+
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	var req dto.PasswordConfirmRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.authUsecase.DeleteAccount(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to delete account")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":               "Account scheduled for deletion",
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+	})
+}
+
+func (h *AuthHandler) RestoreAccount(c *gin.Context) {
+	user, err := h.authUsecase.RestoreAccount(c.Request.Context(), c.GetUint("user_id"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		respondError(c, err, "Failed to restore account")
 		return
 	}
 
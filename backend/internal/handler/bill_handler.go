@@ -2,7 +2,8 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
+
+	"financebroke/backend/internal/apperror"
 
 	"financebroke/backend/internal/dto"
 	"financebroke/backend/internal/usecase"
@@ -10,6 +11,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+var errInvalidScope = apperror.BadRequest("INVALID_SCOPE", "scope must be 'this' or 'future'")
+
 type BillHandler struct {
 	billUsecase usecase.BillUsecase
 }
@@ -22,14 +25,13 @@ func (h *BillHandler) CreateBill(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	var req dto.BillCreateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	bill, err := h.billUsecase.CreateBill(userID, &req)
+	bill, err := h.billUsecase.CreateBill(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bill"})
+		respondError(c, err, "Failed to create bill")
 		return
 	}
 
@@ -39,9 +41,14 @@ func (h *BillHandler) CreateBill(c *gin.Context) {
 func (h *BillHandler) GetBills(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
-	bills, err := h.billUsecase.GetUserBills(userID)
+	var query dto.BillListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	bills, err := h.billUsecase.GetUserBills(c.Request.Context(), userID, &query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bills"})
+		respondError(c, err, "Failed to fetch bills")
 		return
 	}
 
@@ -50,15 +57,14 @@ func (h *BillHandler) GetBills(c *gin.Context) {
 
 func (h *BillHandler) GetBill(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	billID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bill ID"})
+	billID, ok := idParam(c, "id", "bill")
+	if !ok {
 		return
 	}
 
-	bill, err := h.billUsecase.GetBill(userID, uint(billID))
+	bill, err := h.billUsecase.GetBill(c.Request.Context(), userID, billID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Bill not found"})
+		respondError(c, err, "Failed to fetch bill")
 		return
 	}
 
@@ -67,21 +73,24 @@ func (h *BillHandler) GetBill(c *gin.Context) {
 
 func (h *BillHandler) UpdateBill(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	billID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bill ID"})
+	billID, ok := idParam(c, "id", "bill")
+	if !ok {
+		return
+	}
+
+	scope, ok := editScope(c)
+	if !ok {
 		return
 	}
 
 	var req dto.BillUpdateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	bill, err := h.billUsecase.UpdateBill(userID, uint(billID), &req)
+	bill, err := h.billUsecase.UpdateBill(c.Request.Context(), userID, billID, scope, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bill"})
+		respondError(c, err, "Failed to update bill")
 		return
 	}
 
@@ -90,15 +99,19 @@ func (h *BillHandler) UpdateBill(c *gin.Context) {
 
 func (h *BillHandler) DeleteBill(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	billID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bill ID"})
+	billID, ok := idParam(c, "id", "bill")
+	if !ok {
+		return
+	}
+
+	scope, ok := editScope(c)
+	if !ok {
 		return
 	}
 
-	err = h.billUsecase.DeleteBill(userID, uint(billID))
+	err := h.billUsecase.DeleteBill(c.Request.Context(), userID, billID, scope)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bill"})
+		respondError(c, err, "Failed to delete bill")
 		return
 	}
 
@@ -108,11 +121,22 @@ func (h *BillHandler) DeleteBill(c *gin.Context) {
 func (h *BillHandler) GetUpcomingBills(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
-	bills, err := h.billUsecase.GetUpcomingBills(userID)
+	bills, err := h.billUsecase.GetUpcomingBills(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch upcoming bills"})
+		respondError(c, err, "Failed to fetch upcoming bills")
 		return
 	}
 
 	c.JSON(http.StatusOK, bills)
-}
\ No newline at end of file
+}
+
+// editScope reads the "scope" query parameter used when editing recurring
+// bills. It answers 400 and returns false for unknown scopes.
+func editScope(c *gin.Context) (string, bool) {
+	scope := c.DefaultQuery("scope", dto.EditScopeThis)
+	if scope != dto.EditScopeThis && scope != dto.EditScopeFuture {
+		respondError(c, errInvalidScope, "")
+		return "", false
+	}
+	return scope, true
+}
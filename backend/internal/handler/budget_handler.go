@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidMonth = apperror.BadRequest("INVALID_MONTH", "Invalid month, expected YYYY-MM")
+
+type BudgetHandler struct {
+	budgetUsecase usecase.BudgetUsecase
+}
+
+func NewBudgetHandler(budgetUsecase usecase.BudgetUsecase) *BudgetHandler {
+	return &BudgetHandler{budgetUsecase: budgetUsecase}
+}
+
+// GetBudgets returns budget-vs-actual for the month given as ?month=YYYY-MM,
+// defaulting to the current month.
+func (h *BudgetHandler) GetBudgets(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	month := time.Now()
+	if value := c.Query("month"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01", value, time.Local)
+		if err != nil {
+			respondError(c, errInvalidMonth, "")
+			return
+		}
+		month = parsed
+	}
+
+	budgets, err := h.budgetUsecase.GetBudgetStatuses(c.Request.Context(), userID, month)
+	if err != nil {
+		respondError(c, err, "Failed to fetch budgets")
+		return
+	}
+
+	c.JSON(http.StatusOK, budgets)
+}
+
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req dto.BudgetCreateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	budget, err := h.budgetUsecase.CreateBudget(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to create budget")
+		return
+	}
+
+	c.JSON(http.StatusCreated, budget)
+}
+
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	budgetID, ok := idParam(c, "id", "budget")
+	if !ok {
+		return
+	}
+
+	var req dto.BudgetUpdateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	budget, err := h.budgetUsecase.UpdateBudget(c.Request.Context(), userID, budgetID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to update budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	budgetID, ok := idParam(c, "id", "budget")
+	if !ok {
+		return
+	}
+
+	err := h.budgetUsecase.DeleteBudget(c.Request.Context(), userID, budgetID)
+	if err != nil {
+		respondError(c, err, "Failed to delete budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Budget deleted successfully"})
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryUsecase usecase.CategoryUsecase
+}
+
+func NewCategoryHandler(categoryUsecase usecase.CategoryUsecase) *CategoryHandler {
+	return &CategoryHandler{categoryUsecase: categoryUsecase}
+}
+
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req dto.CategoryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	category, err := h.categoryUsecase.CreateCategory(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to create category")
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	categories, err := h.categoryUsecase.GetCategories(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch categories")
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	categoryID, ok := idParam(c, "id", "category")
+	if !ok {
+		return
+	}
+
+	var req dto.CategoryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	category, err := h.categoryUsecase.UpdateCategory(c.Request.Context(), userID, categoryID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to update category")
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	categoryID, ok := idParam(c, "id", "category")
+	if !ok {
+		return
+	}
+
+	err := h.categoryUsecase.DeleteCategory(c.Request.Context(), userID, categoryID)
+	if err != nil {
+		respondError(c, err, "Failed to delete category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+func (h *CategoryHandler) CreateTag(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req dto.TagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tag, err := h.categoryUsecase.CreateTag(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (h *CategoryHandler) GetTags(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	tags, err := h.categoryUsecase.GetTags(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *CategoryHandler) UpdateTag(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	tagID, ok := idParam(c, "id", "tag")
+	if !ok {
+		return
+	}
+
+	var req dto.TagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tag, err := h.categoryUsecase.UpdateTag(c.Request.Context(), userID, tagID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to update tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *CategoryHandler) DeleteTag(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	tagID, ok := idParam(c, "id", "tag")
+	if !ok {
+		return
+	}
+
+	err := h.categoryUsecase.DeleteTag(c.Request.Context(), userID, tagID)
+	if err != nil {
+		respondError(c, err, "Failed to delete tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
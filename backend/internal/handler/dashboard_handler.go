@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"financebroke/backend/internal/usecase"
 
@@ -9,19 +10,26 @@ import (
 )
 
 type DashboardHandler struct {
-	billUsecase usecase.BillUsecase
+	billUsecase   usecase.BillUsecase
+	budgetUsecase usecase.BudgetUsecase
 }
 
-func NewDashboardHandler(billUsecase usecase.BillUsecase) *DashboardHandler {
-	return &DashboardHandler{billUsecase: billUsecase}
+func NewDashboardHandler(billUsecase usecase.BillUsecase, budgetUsecase usecase.BudgetUsecase) *DashboardHandler {
+	return &DashboardHandler{billUsecase: billUsecase, budgetUsecase: budgetUsecase}
 }
 
 func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
-	dashboard, err := h.billUsecase.GetDashboard(userID)
+	dashboard, err := h.billUsecase.GetDashboard(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dashboard"})
+		respondError(c, err, "Failed to fetch dashboard")
+		return
+	}
+
+	dashboard.Budgets, err = h.budgetUsecase.GetBudgetStatuses(c.Request.Context(), userID, time.Now())
+	if err != nil {
+		respondError(c, err, "Failed to fetch dashboard")
 		return
 	}
 
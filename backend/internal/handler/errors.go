@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	errInvalidRequest   = apperror.BadRequest("INVALID_REQUEST", "Invalid request format")
+	errValidationFailed = apperror.Validation("VALIDATION_FAILED", "Request validation failed")
+	errInvalidID        = apperror.BadRequest("INVALID_ID", "Invalid ID")
+)
+
+// moneyErrors are returned while decoding amounts and describe the problem
+// well enough to be shown as is.
+var moneyErrors = []error{
+	entity.ErrInvalidMoney, entity.ErrNegativeMoney, entity.ErrMoneyTooPrecise, entity.ErrMoneyOutOfRange,
+}
+
+func init() {
+	// Report validation failures under the JSON field names clients send
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// respondError hands err to middleware.ErrorHandler, which writes the
+// response. fallback is the message shown when err is not a domain error.
+func respondError(c *gin.Context, err error, fallback string) {
+	_ = c.Error(err).SetMeta(fallback)
+	c.Abort()
+}
+
+// bindJSON decodes the request body into obj. Malformed bodies are answered
+// with a 400 and values failing validation with a 422 listing the fields.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		respondError(c, bindingError(err), "")
+		return false
+	}
+	return true
+}
+
+// bindQuery is bindJSON for query parameters.
+func bindQuery(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		respondError(c, bindingError(err), "")
+		return false
+	}
+	return true
+}
+
+// idParam reads a numeric path parameter, answering 400 when it is invalid.
+// label names the resource in the error message.
+func idParam(c *gin.Context, name, label string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		respondError(c, errInvalidID.WithMessage("Invalid %s ID", label), "")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func bindingError(err error) error {
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		details := make(map[string]string, len(fieldErrors))
+		for _, fieldError := range fieldErrors {
+			details[fieldError.Field()] = validationMessage(fieldError)
+		}
+		return errValidationFailed.WithDetails(details)
+	}
+
+	for _, moneyErr := range moneyErrors {
+		if errors.Is(err, moneyErr) {
+			return errValidationFailed.WithMessage("%v", moneyErr)
+		}
+	}
+
+	return errInvalidRequest.WithDetails(map[string]string{"request": err.Error()})
+}
+
+func validationMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "gt":
+		return "must be greater than " + param
+	case "min", "max":
+		bound := "at least"
+		if fieldError.Tag() == "max" {
+			bound = "at most"
+		}
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be %s %s characters long", bound, param)
+		}
+		return fmt.Sprintf("must be %s %s", bound, param)
+	default:
+		return "is invalid"
+	}
+}
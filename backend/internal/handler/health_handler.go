@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler serves the liveness and readiness probes. Readiness fails as
+// soon as shutdown begins, so the proxy stops routing new requests here while
+// the ones already in flight are drained.
+type HealthHandler struct {
+	db           *sql.DB
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(db *sql.DB) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// MarkShuttingDown makes every later readiness check fail.
+func (h *HealthHandler) MarkShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"service": "financebroke-api",
+		"version": "1.0.0",
+	})
+}
+
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "not ready",
+			"error":  "shutting down",
+		})
+		return
+	}
+
+	// Check database connection
+	if err := h.db.PingContext(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "not ready",
+			"error":  "database not connected",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "ready",
+		"database": "connected",
+	})
+}
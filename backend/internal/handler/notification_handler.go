@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 
 	"financebroke/backend/internal/dto"
@@ -18,38 +17,61 @@ func NewNotificationHandler(notificationUsecase usecase.NotificationUsecase) *No
 	return &NotificationHandler{notificationUsecase: notificationUsecase}
 }
 
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	var query dto.NotificationListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	response, err := h.notificationUsecase.GetHistory(c.Request.Context(), c.GetUint("user_id"), &query)
+	if err != nil {
+		respondError(c, err, "Failed to fetch notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *NotificationHandler) GetNotificationSettings(c *gin.Context) {
+	settings, err := h.notificationUsecase.GetSettings(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"channels": settings})
+}
+
 func (h *NotificationHandler) UpdateNotificationSettings(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	var req dto.NotificationSettingsRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	user, err := h.notificationUsecase.UpdateSettings(userID, &req)
+	settings, err := h.notificationUsecase.UpdateSettings(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+		respondError(c, err, "Failed to update settings")
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, gin.H{"channels": settings})
 }
 
-func (h *NotificationHandler) TestTelegram(c *gin.Context) {
+func (h *NotificationHandler) SendTest(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
-	var req dto.TestTelegramRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.TestNotificationRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	err := h.notificationUsecase.TestTelegram(userID, &req)
+	err := h.notificationUsecase.SendTest(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to send telegram message: %v", err)})
+		respondError(c, err, "Failed to send test notification")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Telegram message sent successfully"})
-}
\ No newline at end of file
+	c.JSON(http.StatusOK, gin.H{"message": "Test notification sent successfully"})
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	billUsecase usecase.BillUsecase
+}
+
+func NewPaymentHandler(billUsecase usecase.BillUsecase) *PaymentHandler {
+	return &PaymentHandler{billUsecase: billUsecase}
+}
+
+func (h *PaymentHandler) RecordPayment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	billID, ok := idParam(c, "id", "bill")
+	if !ok {
+		return
+	}
+
+	var req dto.PaymentCreateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.billUsecase.RecordPayment(c.Request.Context(), userID, billID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to record payment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *PaymentHandler) GetPayments(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	billID, ok := idParam(c, "id", "bill")
+	if !ok {
+		return
+	}
+
+	payments, err := h.billUsecase.GetPayments(c.Request.Context(), userID, billID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch payments")
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+func (h *PaymentHandler) VoidPayment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	billID, ok := idParam(c, "id", "bill")
+	if !ok {
+		return
+	}
+	paymentID, ok := idParam(c, "paymentId", "payment")
+	if !ok {
+		return
+	}
+
+	var req dto.PaymentVoidRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.billUsecase.VoidPayment(c.Request.Context(), userID, billID, paymentID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to void payment")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecurrenceHandler struct {
+	recurrenceUsecase usecase.RecurrenceUsecase
+}
+
+func NewRecurrenceHandler(recurrenceUsecase usecase.RecurrenceUsecase) *RecurrenceHandler {
+	return &RecurrenceHandler{recurrenceUsecase: recurrenceUsecase}
+}
+
+func (h *RecurrenceHandler) GetRecurrences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	recurrences, err := h.recurrenceUsecase.GetRecurrences(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch recurrences")
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrences)
+}
+
+func (h *RecurrenceHandler) GetRecurrence(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	recurrenceID, ok := idParam(c, "id", "recurrence")
+	if !ok {
+		return
+	}
+
+	recurrence, err := h.recurrenceUsecase.GetRecurrence(c.Request.Context(), userID, recurrenceID)
+	if err != nil {
+		respondError(c, err, "Failed to fetch recurrence")
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+func (h *RecurrenceHandler) UpdateRecurrence(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	recurrenceID, ok := idParam(c, "id", "recurrence")
+	if !ok {
+		return
+	}
+
+	var req dto.RecurrenceUpdateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	recurrence, err := h.recurrenceUsecase.UpdateRecurrence(c.Request.Context(), userID, recurrenceID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to update recurrence")
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+func (h *RecurrenceHandler) DeleteRecurrence(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	recurrenceID, ok := idParam(c, "id", "recurrence")
+	if !ok {
+		return
+	}
+
+	err := h.recurrenceUsecase.StopRecurrence(c.Request.Context(), userID, recurrenceID)
+	if err != nil {
+		respondError(c, err, "Failed to stop recurrence")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recurrence stopped successfully"})
+}
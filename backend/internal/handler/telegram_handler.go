@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/services"
+	"financebroke/backend/internal/usecase"
+	"financebroke/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// telegramSecretHeader carries the secret registered with setWebhook.
+const telegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+var errInvalidTelegramSecret = apperror.Unauthorized("INVALID_TELEGRAM_SECRET", "Invalid Telegram webhook secret")
+
+type TelegramHandler struct {
+	botUsecase usecase.TelegramBotUsecase
+	secret     string
+}
+
+func NewTelegramHandler(botUsecase usecase.TelegramBotUsecase, secret string) *TelegramHandler {
+	return &TelegramHandler{botUsecase: botUsecase, secret: secret}
+}
+
+// CreateLink returns a one-time token that links a Telegram chat to the
+// account when sent to the bot.
+func (h *TelegramHandler) CreateLink(c *gin.Context) {
+	link, err := h.botUsecase.CreateLink(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to create Telegram link")
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// Webhook receives updates pushed by Telegram. Once the secret checks out the
+// update is always acknowledged, since Telegram keeps redelivering updates
+// that fail.
+func (h *TelegramHandler) Webhook(c *gin.Context) {
+	secret := c.GetHeader(telegramSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(h.secret)) != 1 {
+		respondError(c, errInvalidTelegramSecret, "")
+		return
+	}
+
+	var update services.TelegramUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	if err := h.botUsecase.HandleUpdate(c.Request.Context(), update); err != nil {
+		utils.LogErrorContext(c.Request.Context(), "TELEGRAM_HANDLER_UPDATE", err)
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUsecase usecase.TwoFactorUsecase
+}
+
+func NewTwoFactorHandler(twoFactorUsecase usecase.TwoFactorUsecase) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorUsecase: twoFactorUsecase}
+}
+
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	status, err := h.twoFactorUsecase.GetStatus(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch two-factor status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	enrollment, err := h.twoFactorUsecase.Enroll(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to start two-factor enrollment")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req dto.TwoFactorConfirmRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	codes, err := h.twoFactorUsecase.Confirm(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req dto.PasswordConfirmRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.twoFactorUsecase.Disable(c.Request.Context(), c.GetUint("user_id"), &req); err != nil {
+		respondError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled successfully"})
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.PasswordConfirmRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	codes, err := h.twoFactorUsecase.RegenerateRecoveryCodes(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookUsecase usecase.WebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{webhookUsecase: webhookUsecase}
+}
+
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookUsecase.ListWebhooks(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook registers an endpoint. The response is the only one that
+// includes the signing secret.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req dto.WebhookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	webhook, err := h.webhookUsecase.CreateWebhook(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	webhookID, ok := idParam(c, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	var req dto.WebhookUpdateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	webhook, err := h.webhookUsecase.UpdateWebhook(c.Request.Context(), userID, webhookID, &req)
+	if err != nil {
+		respondError(c, err, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	webhookID, ok := idParam(c, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	err := h.webhookUsecase.DeleteWebhook(c.Request.Context(), userID, webhookID)
+	if err != nil {
+		respondError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// TestWebhook pings the endpoint and returns the delivery, which records
+// whether the endpoint accepted it.
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	webhookID, ok := idParam(c, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookUsecase.TestWebhook(c.Request.Context(), userID, webhookID)
+	if err != nil {
+		respondError(c, err, "Failed to send test webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	webhookID, ok := idParam(c, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	var query dto.WebhookDeliveryListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	response, err := h.webhookUsecase.GetDeliveries(c.Request.Context(), userID, webhookID, &query)
+	if err != nil {
+		respondError(c, err, "Failed to fetch webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,18 +1,33 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
+	"errors"
+
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/usecase"
 	"financebroke/backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// SessionValidator reports whether the session behind an access token is
+// still active.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, userID, sessionID uint) error
+}
+
+var (
+	errMissingToken = apperror.Unauthorized("TOKEN_MISSING", "Authorization header required")
+	errInvalidToken = apperror.Unauthorized("TOKEN_INVALID", "Invalid token")
+	errSessionEnded = apperror.Unauthorized("SESSION_REVOKED", "Session expired or revoked")
+)
+
+func AuthMiddleware(tokens *utils.TokenManager, sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			AbortWithError(c, errMissingToken)
 			return
 		}
 
@@ -21,15 +36,25 @@ func AuthMiddleware() gin.HandlerFunc {
 			token = token[7:]
 		}
 
-		claims, err := utils.ValidateToken(token)
+		claims, err := tokens.ValidateToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			AbortWithError(c, errInvalidToken)
+			return
+		}
+
+		// Reject tokens whose session was logged out or revoked. Failing to
+		// look the session up is a server error, not a reason to log the
+		// user out.
+		if err := sessions.ValidateSession(c.Request.Context(), claims.UserID, claims.SessionID); err != nil {
+			if errors.Is(err, usecase.ErrSessionRevoked) {
+				err = errSessionEnded
+			}
+			AbortWithError(c, err)
 			return
 		}
 
 		c.Set("user_id", claims.UserID)
-		c.Set("email", claims.Email)
+		c.Set("session_id", claims.SessionID)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const internalErrorCode = "INTERNAL_ERROR"
+
+var kindStatus = map[apperror.Kind]int{
+	apperror.KindBadRequest:   http.StatusBadRequest,
+	apperror.KindValidation:   http.StatusUnprocessableEntity,
+	apperror.KindUnauthorized: http.StatusUnauthorized,
+	apperror.KindForbidden:    http.StatusForbidden,
+	apperror.KindNotFound:     http.StatusNotFound,
+	apperror.KindConflict:     http.StatusConflict,
+	apperror.KindRateLimited:  http.StatusTooManyRequests,
+	apperror.KindUpstream:     http.StatusBadGateway,
+}
+
+// ErrorHandler writes the response for the last error a handler attached
+// with c.Error, unless a response was already written. Domain errors map to
+// their status and code; anything else is logged and answered with a 500.
+// A string set as the error's Meta replaces the generic message of a 500.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		last := c.Errors.Last()
+		if appErr, ok := apperror.As(last.Err); ok {
+			status, ok := kindStatus[appErr.Kind]
+			if !ok {
+				status = http.StatusInternalServerError
+			}
+			if appErr.Unwrap() != nil {
+				utils.LogErrorContext(c.Request.Context(), "HTTP_"+appErr.Code, last.Err)
+			}
+			writeError(c, status, appErr.Code, appErr.Message, appErr.Details)
+			return
+		}
+
+		utils.LogErrorContext(c.Request.Context(), "HTTP_UNHANDLED", last.Err)
+		message, ok := last.Meta.(string)
+		if !ok || message == "" {
+			message = "Internal server error"
+		}
+		writeError(c, http.StatusInternalServerError, internalErrorCode, message, nil)
+	}
+}
+
+// Recovery turns a panic in a handler into a logged 500 with the usual error
+// body.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered interface{}) {
+		utils.FromContext(c.Request.Context()).Error("[HTTP] Handler panicked", map[string]interface{}{
+			"panic": recovered,
+			"stack": string(debug.Stack()),
+		})
+		writeError(c, http.StatusInternalServerError, internalErrorCode, "Internal server error", nil)
+	})
+}
+
+// NotFound answers requests for unknown routes.
+func NotFound() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeError(c, http.StatusNotFound, "ROUTE_NOT_FOUND", "Route not found", nil)
+	}
+}
+
+// AbortWithError attaches err for ErrorHandler and stops the handler chain.
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+func writeError(c *gin.Context, status int, code, message string, details map[string]string) {
+	c.AbortWithStatusJSON(status, dto.ErrorResponse{
+		Error:     message,
+		ErrorCode: code,
+		Details:   details,
+		RequestID: c.GetString("request_id"),
+	})
+}
@@ -2,45 +2,211 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
+	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func LoggingMiddleware() gin.HandlerFunc {
+const (
+	skipRequestLogKey = "logging.skip_request"
+	skipBodyLogKey    = "logging.skip_body"
+
+	redactedValue = "[REDACTED]"
+
+	// maxRedactableBytes is the largest body that is parsed for redaction.
+	// Larger bodies are never logged, since a cut-off document cannot be
+	// redacted reliably.
+	maxRedactableBytes = 64 << 10
+)
+
+// DisableRequestLogging turns off request logging for the routes it is
+// attached to, for example noisy health checks.
+func DisableRequestLogging() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(skipRequestLogKey, true)
+		c.Next()
+	}
+}
+
+// DisableBodyLogging keeps the request line in the log but leaves out the
+// body for the routes it is attached to.
+func DisableBodyLogging() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(skipBodyLogKey, true)
+		c.Next()
+	}
+}
+
+// LoggingMiddleware logs one line per request once it has been handled. When
+// body logging is enabled, JSON and form bodies are logged with the configured
+// sensitive fields redacted and the result truncated; other payloads are
+// summarised by type and size only.
+func LoggingMiddleware(cfg config.LoggingConfig) gin.HandlerFunc {
+	redact := make(map[string]bool, len(cfg.RedactFields))
+	for _, field := range cfg.RedactFields {
+		redact[strings.ToLower(field)] = true
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
-		method := c.Request.Method
-
-		// Log request
-		fmt.Printf("[REQUEST] %s %s\n", method, path)
-
-		// Log request body for POST/PUT
-		if method == "POST" || method == "PUT" {
-			if c.Request.Body != nil {
-				body, err := io.ReadAll(c.Request.Body)
-				if err == nil && len(body) > 0 {
-					fmt.Printf("[BODY] %s\n", string(body))
-					// Restore body
-					c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
-				}
-			}
+
+		var captured []byte
+		var complete bool
+		if cfg.LogBodies && c.Request.Body != nil && c.Request.ContentLength != 0 {
+			captured, complete = captureBody(c)
 		}
 
 		c.Next()
 
-		// Log response
-		duration := time.Since(start)
-		fmt.Printf("[RESPONSE] %s %s - %d (%v)\n", method, path, c.Writer.Status(), duration)
+		if c.GetBool(skipRequestLogKey) {
+			return
+		}
+
+		fields := map[string]interface{}{
+			"method":      c.Request.Method,
+			"path":        c.Request.URL.Path,
+			"status":      c.Writer.Status(),
+			"duration_ms": time.Since(start).Milliseconds(),
+			"client_ip":   c.ClientIP(),
+		}
+
+		if len(captured) > 0 && !c.GetBool(skipBodyLogKey) {
+			fields["body"] = formatBody(c.ContentType(), captured, complete, redact, cfg.MaxBodyBytes)
+		}
 
-		// Log errors
 		if len(c.Errors) > 0 {
-			for _, e := range c.Errors {
-				fmt.Printf("[ERROR] %s\n", e.Error())
+			fields["errors"] = c.Errors.String()
+		}
+
+		utils.FromContext(c.Request.Context()).Info("[HTTP] Request handled", fields)
+	}
+}
+
+// captureBody reads up to maxRedactableBytes+1 bytes of the request body and
+// puts them back in front of the rest, so handlers still see the whole body.
+// complete reports whether the captured bytes are the entire body.
+func captureBody(c *gin.Context) (captured []byte, complete bool) {
+	captured, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRedactableBytes+1))
+	c.Request.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(captured), c.Request.Body),
+		Closer: c.Request.Body,
+	}
+	if err != nil {
+		return nil, false
+	}
+
+	return captured, len(captured) <= maxRedactableBytes
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func formatBody(contentType string, body []byte, complete bool, redact map[string]bool, maxBytes int) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if !complete {
+		return fmt.Sprintf("[%s body over %d bytes omitted]", mediaTypeOrUnknown(mediaType), maxRedactableBytes)
+	}
+
+	var text string
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		redacted, err := redactJSON(body, redact)
+		if err != nil {
+			return fmt.Sprintf("[invalid JSON body, %d bytes]", len(body))
+		}
+		text = redacted
+	case mediaType == "application/x-www-form-urlencoded":
+		redacted, err := redactForm(body, redact)
+		if err != nil {
+			return fmt.Sprintf("[invalid form body, %d bytes]", len(body))
+		}
+		text = redacted
+	default:
+		// Anything else may be binary, or text with secrets in no known
+		// field, so only describe it
+		return fmt.Sprintf("[%s body, %d bytes]", mediaTypeOrUnknown(mediaType), len(body))
+	}
+
+	return truncate(text, maxBytes)
+}
+
+func mediaTypeOrUnknown(mediaType string) string {
+	if mediaType == "" {
+		return "untyped"
+	}
+	return mediaType
+}
+
+func redactJSON(body []byte, redact map[string]bool) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	out, err := json.Marshal(redactValue(value, redact))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func redactValue(value interface{}, redact map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if redact[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(item, redact)
 			}
 		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, redact)
+		}
+	}
+	return value
+}
+
+func redactForm(body []byte, redact map[string]bool) (string, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", err
+	}
+
+	for key := range values {
+		if redact[strings.ToLower(key)] {
+			values[key] = []string{redactedValue}
+		}
+	}
+	return values.Encode(), nil
+}
+
+// truncate cuts text to at most maxBytes without splitting a UTF-8 sequence.
+func truncate(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
 	}
-}
\ No newline at end of file
+	return fmt.Sprintf("%s...[truncated, %d bytes total]", text[:cut], len(text))
+}
@@ -0,0 +1,296 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testRedact = map[string]bool{"password": true, "token": true, "code": true}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "top-level fields in any case",
+			body: `{"email":"ann@example.com","Password":"hunter2","TOKEN":"abc"}`,
+			want: `{"Password":"[REDACTED]","TOKEN":"[REDACTED]","email":"ann@example.com"}`,
+		},
+		{
+			name: "nested objects",
+			body: `{"user":{"name":"Ann","credentials":{"password":"hunter2"}}}`,
+			want: `{"user":{"credentials":{"password":"[REDACTED]"},"name":"Ann"}}`,
+		},
+		{
+			name: "objects inside arrays",
+			body: `{"codes":[{"Code":"123456"},{"code":"654321","label":"spare"}]}`,
+			want: `{"codes":[{"Code":"[REDACTED]"},{"code":"[REDACTED]","label":"spare"}]}`,
+		},
+		{
+			name: "top-level array",
+			body: `[{"token":"abc"},"token",42]`,
+			want: `[{"token":"[REDACTED]"},"token",42]`,
+		},
+		{
+			name: "redacted field holding an object",
+			body: `{"password":{"old":"a","new":"b"}}`,
+			want: `{"password":"[REDACTED]"}`,
+		},
+		{
+			name: "numbers keep their precision",
+			body: `{"amount":12345678901234567890.5}`,
+			want: `{"amount":12345678901234567890.5}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := redactJSON([]byte(tt.body), testRedact)
+			if err != nil {
+				t.Fatalf("redactJSON: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("redactJSON = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactForm(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "fields in any case",
+			body: "email=ann%40example.com&Password=hunter2&token=abc",
+			want: "Password=%5BREDACTED%5D&email=ann%40example.com&token=%5BREDACTED%5D",
+		},
+		{
+			name: "repeated field is redacted once",
+			body: "code=1&code=2&next=%2Fbills",
+			want: "code=%5BREDACTED%5D&next=%2Fbills",
+		},
+		{
+			name:    "malformed escape",
+			body:    "password=%zz",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := redactForm([]byte(tt.body), testRedact)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("redactForm error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("redactForm = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		complete    bool
+		maxBytes    int
+		want        string
+	}{
+		{
+			name:        "JSON is redacted",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"password":"hunter2"}`,
+			complete:    true,
+			maxBytes:    1024,
+			want:        `{"password":"[REDACTED]"}`,
+		},
+		{
+			name:        "JSON suffix types are redacted",
+			contentType: "application/merge-patch+json",
+			body:        `{"token":"abc"}`,
+			complete:    true,
+			maxBytes:    1024,
+			want:        `{"token":"[REDACTED]"}`,
+		},
+		{
+			name:        "form is redacted",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "password=hunter2",
+			complete:    true,
+			maxBytes:    1024,
+			want:        "password=%5BREDACTED%5D",
+		},
+		{
+			name:        "body over the redaction limit is omitted",
+			contentType: "application/json",
+			body:        `{"password":"hunter2"`,
+			complete:    false,
+			maxBytes:    1024,
+			want:        "[application/json body over 65536 bytes omitted]",
+		},
+		{
+			name:        "invalid JSON is only described",
+			contentType: "application/json",
+			body:        `{"password":"hunter2"`,
+			complete:    true,
+			maxBytes:    1024,
+			want:        "[invalid JSON body, 21 bytes]",
+		},
+		{
+			name:        "other types are only described",
+			contentType: "text/plain",
+			body:        "password=hunter2",
+			complete:    true,
+			maxBytes:    1024,
+			want:        "[text/plain body, 16 bytes]",
+		},
+		{
+			name:     "untyped body is only described",
+			body:     "password=hunter2",
+			complete: true,
+			maxBytes: 1024,
+			want:     "[untyped body, 16 bytes]",
+		},
+		{
+			name:        "redacted body is truncated",
+			contentType: "application/json",
+			body:        `{"note":"abcdefghij"}`,
+			complete:    true,
+			maxBytes:    10,
+			want:        `{"note":"a...[truncated, 21 bytes total]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatBody(tt.contentType, []byte(tt.body), tt.complete, testRedact, tt.maxBytes)
+			if got != tt.want {
+				t.Errorf("formatBody = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxBytes int
+		want     string
+	}{
+		{name: "short text is kept", text: "hello", maxBytes: 5, want: "hello"},
+		{name: "ASCII is cut at the limit", text: "hello world", maxBytes: 5, want: "hello...[truncated, 11 bytes total]"},
+		{name: "cut inside a two-byte rune", text: "héllo", maxBytes: 2, want: "h...[truncated, 6 bytes total]"},
+		{name: "cut after a two-byte rune", text: "héllo", maxBytes: 3, want: "hé...[truncated, 6 bytes total]"},
+		{name: "cut inside a four-byte rune", text: "a😀b", maxBytes: 4, want: "a...[truncated, 6 bytes total]"},
+		{name: "cut inside the first rune", text: "😀", maxBytes: 3, want: "...[truncated, 4 bytes total]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.text, tt.maxBytes); got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.text, tt.maxBytes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	if err := utils.SetupLogger(&logs, "info", utils.LogFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = utils.SetupLogger(os.Stderr, "info", utils.LogFormatText) })
+
+	large := `{"note":"` + strings.Repeat("x", maxRedactableBytes) + `"}`
+
+	tests := []struct {
+		name       string
+		route      []gin.HandlerFunc
+		body       string
+		wantLogged string
+	}{
+		{
+			name:       "body is logged redacted",
+			body:       `{"email":"ann@example.com","password":"hunter2"}`,
+			wantLogged: `{"email":"ann@example.com","password":"[REDACTED]"}`,
+		},
+		{
+			name:       "large body reaches the handler whole",
+			body:       large,
+			wantLogged: "[application/json body over 65536 bytes omitted]",
+		},
+		{
+			name:  "DisableBodyLogging leaves the body out",
+			route: []gin.HandlerFunc{DisableBodyLogging()},
+			body:  `{"password":"hunter2"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+
+			var received string
+			router := gin.New()
+			router.Use(LoggingMiddleware(config.LoggingConfig{
+				LogBodies:    true,
+				MaxBodyBytes: 1024,
+				RedactFields: []string{"Password"},
+			}))
+			handlers := append(tt.route, func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				received = string(body)
+				c.Status(http.StatusNoContent)
+			})
+			router.POST("/login", handlers...)
+
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if received != tt.body {
+				t.Errorf("handler received %d bytes, want the whole %d byte body", len(received), len(tt.body))
+			}
+
+			var line map[string]interface{}
+			if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+				t.Fatalf("log line %q: %v", logs.String(), err)
+			}
+			if line["path"] != "/login" {
+				t.Errorf("logged path %v, want /login", line["path"])
+			}
+			body, logged := line["body"]
+			if tt.wantLogged == "" {
+				if logged {
+					t.Errorf("logged body %v, want none", body)
+				}
+				return
+			}
+			if body != tt.wantLogged {
+				t.Errorf("logged body %v, want %s", body, tt.wantLogged)
+			}
+		})
+	}
+}
@@ -1,30 +1,111 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"financebroke/backend/internal/entity"
+	"strings"
 	"time"
 )
 
 type BillRepository interface {
-	Create(bill entity.Bill) (entity.Bill, error)
-	FindByID(id, userID uint) (entity.Bill, error)
-	FindByUserID(userID uint) ([]entity.Bill, error)
-	FindUpcomingBills(userID uint, startDate, endDate time.Time) ([]entity.Bill, error)
-	Update(bill entity.Bill) (entity.Bill, error)
-	Delete(id, userID uint) error
-	GetDashboardStats(userID uint) (DashboardStats, error)
+	Create(ctx context.Context, bill entity.Bill) (entity.Bill, error)
+	FindByID(ctx context.Context, id, userID uint) (entity.Bill, error)
+	FindPage(ctx context.Context, filter BillFilter) ([]entity.Bill, int64, error)
+	FindRecent(ctx context.Context, userID uint, limit int) ([]entity.Bill, error)
+	FindUpcomingBills(ctx context.Context, userID uint, startDate, endDate time.Time) ([]entity.Bill, error)
+	FindDueForReminder(ctx context.Context, now time.Time) ([]entity.Bill, error)
+	MarkReminded(ctx context.Context, id uint, remindedAt time.Time) error
+	MarkOverdue(ctx context.Context, before time.Time) (int64, error)
+	MarkOverdueByUser(ctx context.Context, userID uint, before time.Time) (int64, error)
+	FindOverdueUnnotified(ctx context.Context, now time.Time) ([]entity.Bill, error)
+	MarkOverdueNotified(ctx context.Context, id uint, notifiedAt time.Time) error
+	SnoozeReminder(ctx context.Context, id, userID uint, until time.Time) error
+	FindOccurrencesAfter(ctx context.Context, recurrenceID uint, index int) ([]entity.Bill, error)
+	DeleteOccurrencesFrom(ctx context.Context, recurrenceID uint, index int) error
+	Update(ctx context.Context, bill entity.Bill) (entity.Bill, error)
+	SetTags(ctx context.Context, billID uint, tagIDs []uint) error
+	UpdateStatus(ctx context.Context, id uint, status string) (bool, error)
+	Delete(ctx context.Context, id, userID uint) error
+	GetDashboardStats(ctx context.Context, userID uint) (DashboardStats, error)
+}
+
+// Sort fields accepted by BillFilter.
+const (
+	BillSortDueDate   = "due_date"
+	BillSortAmount    = "amount"
+	BillSortName      = "name"
+	BillSortStatus    = "status"
+	BillSortCreatedAt = "created_at"
+)
+
+var billSortColumns = map[string]string{
+	BillSortDueDate:   "due_date",
+	BillSortAmount:    "amount",
+	BillSortName:      "LOWER(name)",
+	BillSortStatus:    "status",
+	BillSortCreatedAt: "created_at",
+}
+
+// BillFilter selects and orders a page of one user's bills. Nil and zero
+// fields do not restrict the result. DueBefore is exclusive, MaxAmount
+// inclusive.
+type BillFilter struct {
+	UserID     uint
+	Statuses   []string
+	DueFrom    *time.Time
+	DueBefore  *time.Time
+	MinAmount  *entity.Money
+	MaxAmount  *entity.Money
+	Search     string
+	CategoryID *uint
+	Tag        string
+	SortBy     string
+	SortDesc   bool
+	Limit      int
+	Offset     int
 }
 
 type DashboardStats struct {
-	TotalBills    int64
-	PaidBills     int64
-	UnpaidBills   int64
-	OverdueBills  int64
-	TotalAmount   float64
-	PaidAmount    float64
-	UnpaidAmount  float64
-	OverdueAmount float64
+	TotalBills         int64
+	PaidBills          int64
+	UnpaidBills        int64
+	PartiallyPaidBills int64
+	OverdueBills       int64
+	TotalAmount        entity.Money
+	PaidAmount         entity.Money
+	UnpaidAmount       entity.Money
+	OverdueAmount      entity.Money
+	Categories         []CategoryStats
+}
+
+// CategoryStats totals a user's bills in one category. Uncategorised bills are
+// reported with a nil CategoryID.
+type CategoryStats struct {
+	CategoryID   *uint
+	Name         string
+	Color        string
+	BillCount    int64
+	TotalAmount  entity.Money
+	PaidAmount   entity.Money
+	UnpaidAmount entity.Money
+}
+
+// billColumns includes the bill's paid amount, summed from its non-voided
+// payments, and its tags as a JSON array, so every query returning bills
+// reports them consistently.
+const billColumns = `id, user_id, name, amount,
+	(SELECT COALESCE(SUM(p.amount), 0) FROM payments p WHERE p.bill_id = bills.id AND p.voided_at IS NULL) AS paid_amount,
+	due_date, description, status, remind_before, reminded_at, overdue_notified_at, reminder_snoozed_until, recurrence_id,
+	occurrence_index, category_id,
+	(SELECT COALESCE(json_agg(json_build_object('id', t.id, 'name', t.name) ORDER BY t.name), '[]'::json)
+		FROM bill_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.bill_id = bills.id) AS tags,
+	created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
 type billRepository struct {
@@ -35,59 +116,53 @@ func NewBillRepository(db *sql.DB) BillRepository {
 	return &billRepository{db: db}
 }
 
-func (r *billRepository) Create(bill entity.Bill) (entity.Bill, error) {
-	query := `
-		INSERT INTO bills (user_id, name, amount, due_date, description, status, remind_before)
-		VALUES ($1, $2, $3, $4, $5, 'unpaid', $6)
-		RETURNING id, user_id, name, amount, due_date, description, status, remind_before, created_at, updated_at
-	`
-
-	err := r.db.QueryRow(query, bill.UserID, bill.Name, bill.Amount, bill.DueDate, bill.Description, bill.RemindBefore).Scan(
-		&bill.ID, &bill.UserID, &bill.Name, &bill.Amount, &bill.DueDate, &bill.Description,
-		&bill.Status, &bill.RemindBefore, &bill.CreatedAt, &bill.UpdatedAt,
+func scanBill(row rowScanner) (entity.Bill, error) {
+	var bill entity.Bill
+	var description sql.NullString
+	var remindedAt, overdueNotifiedAt, snoozedUntil sql.NullTime
+	var recurrenceID, occurrenceIndex, categoryID sql.NullInt64
+	var tags []byte
+	err := row.Scan(
+		&bill.ID, &bill.UserID, &bill.Name, &bill.Amount, &bill.PaidAmount, &bill.DueDate, &description,
+		&bill.Status, &bill.RemindBefore, &remindedAt, &overdueNotifiedAt, &snoozedUntil,
+		&recurrenceID, &occurrenceIndex, &categoryID, &tags, &bill.CreatedAt, &bill.UpdatedAt,
 	)
-
 	if err != nil {
 		return entity.Bill{}, err
 	}
 
-	return bill, nil
-}
-
-func (r *billRepository) FindByID(id, userID uint) (entity.Bill, error) {
-	query := `
-		SELECT id, user_id, name, amount, due_date, description, status, remind_before, created_at, updated_at
-		FROM bills
-		WHERE id = $1 AND user_id = $2
-	`
-
-	var bill entity.Bill
-	var description sql.NullString
-	err := r.db.QueryRow(query, id, userID).Scan(
-		&bill.ID, &bill.UserID, &bill.Name, &bill.Amount, &bill.DueDate, &description,
-		&bill.Status, &bill.RemindBefore, &bill.CreatedAt, &bill.UpdatedAt,
-	)
-
-	if err != nil {
+	if err := json.Unmarshal(tags, &bill.Tags); err != nil {
 		return entity.Bill{}, err
 	}
 
 	if description.Valid {
 		bill.Description = description.String
 	}
+	if remindedAt.Valid {
+		bill.RemindedAt = &remindedAt.Time
+	}
+	if overdueNotifiedAt.Valid {
+		bill.OverdueNotifiedAt = &overdueNotifiedAt.Time
+	}
+	bill.ReminderSnoozedUntil = nullTimePtr(snoozedUntil)
+	if recurrenceID.Valid {
+		id := uint(recurrenceID.Int64)
+		bill.RecurrenceID = &id
+	}
+	if occurrenceIndex.Valid {
+		index := int(occurrenceIndex.Int64)
+		bill.OccurrenceIndex = &index
+	}
+	if categoryID.Valid {
+		id := uint(categoryID.Int64)
+		bill.CategoryID = &id
+	}
 
 	return bill, nil
 }
 
-func (r *billRepository) FindByUserID(userID uint) ([]entity.Bill, error) {
-	query := `
-		SELECT id, user_id, name, amount, due_date, description, status, remind_before, created_at, updated_at
-		FROM bills
-		WHERE user_id = $1
-		ORDER BY due_date ASC
-	`
-
-	rows, err := r.db.Query(query, userID)
+func (r *billRepository) queryBills(ctx context.Context, query string, args ...interface{}) ([]entity.Bill, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -95,91 +170,292 @@ func (r *billRepository) FindByUserID(userID uint) ([]entity.Bill, error) {
 
 	var bills []entity.Bill
 	for rows.Next() {
-		var bill entity.Bill
-		var description sql.NullString
-		err := rows.Scan(
-			&bill.ID, &bill.UserID, &bill.Name, &bill.Amount, &bill.DueDate, &description,
-			&bill.Status, &bill.RemindBefore, &bill.CreatedAt, &bill.UpdatedAt,
-		)
+		bill, err := scanBill(rows)
 		if err != nil {
 			return nil, err
 		}
+		bills = append(bills, bill)
+	}
 
-		if description.Valid {
-			bill.Description = description.String
-		}
+	return bills, rows.Err()
+}
 
-		bills = append(bills, bill)
+// Create inserts a bill. For recurring bills an occurrence that already exists
+// is left untouched and sql.ErrNoRows is returned.
+func (r *billRepository) Create(ctx context.Context, bill entity.Bill) (entity.Bill, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO bills (user_id, name, amount, due_date, description, status, remind_before, recurrence_id, occurrence_index,
+			category_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (recurrence_id, occurrence_index) DO NOTHING
+		RETURNING ` + billColumns
+
+	status := bill.Status
+	if status == "" {
+		status = entity.BillStatusUnpaid
+	}
+
+	var recurrenceID, occurrenceIndex sql.NullInt64
+	if bill.RecurrenceID != nil && bill.OccurrenceIndex != nil {
+		recurrenceID = sql.NullInt64{Int64: int64(*bill.RecurrenceID), Valid: true}
+		occurrenceIndex = sql.NullInt64{Int64: int64(*bill.OccurrenceIndex), Valid: true}
 	}
 
-	return bills, nil
+	return scanBill(conn(ctx, r.db).QueryRowContext(ctx, query, bill.UserID, bill.Name, bill.Amount, bill.DueDate, bill.Description, status,
+		bill.RemindBefore, recurrenceID, occurrenceIndex, nullableID(bill.CategoryID)))
 }
 
-func (r *billRepository) FindUpcomingBills(userID uint, startDate, endDate time.Time) ([]entity.Bill, error) {
+func (r *billRepository) FindByID(ctx context.Context, id, userID uint) (entity.Bill, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, user_id, name, amount, due_date, description, status, remind_before, created_at, updated_at
+		SELECT ` + billColumns + `
 		FROM bills
-		WHERE user_id = $1 AND due_date BETWEEN $2 AND $3 AND status != 'paid'
-		ORDER BY due_date ASC
+		WHERE id = $1 AND user_id = $2
 	`
 
-	rows, err := r.db.Query(query, userID, startDate, endDate)
-	if err != nil {
-		return nil, err
+	return scanBill(conn(ctx, r.db).QueryRowContext(ctx, query, id, userID))
+}
+
+// FindPage returns the page of bills matching filter together with the total
+// number of matching bills.
+func (r *billRepository) FindPage(ctx context.Context, filter BillFilter) ([]entity.Bill, int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var b queryBuilder
+	b.where("user_id = ?", filter.UserID)
+	if len(filter.Statuses) > 0 {
+		b.where("status = ANY(?)", filter.Statuses)
+	}
+	if filter.DueFrom != nil {
+		b.where("due_date >= ?", *filter.DueFrom)
+	}
+	if filter.DueBefore != nil {
+		b.where("due_date < ?", *filter.DueBefore)
+	}
+	if filter.MinAmount != nil {
+		b.where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		b.where("amount <= ?", *filter.MaxAmount)
+	}
+	if filter.Search != "" {
+		b.where("name ILIKE ?", "%"+escapeLike(filter.Search)+"%")
+	}
+	if filter.CategoryID != nil {
+		b.where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.Tag != "" {
+		b.where("EXISTS (SELECT 1 FROM bill_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.bill_id = bills.id AND t.name = ?)", filter.Tag)
 	}
-	defer rows.Close()
 
-	var bills []entity.Bill
-	for rows.Next() {
-		var bill entity.Bill
-		var description sql.NullString
-		err := rows.Scan(
-			&bill.ID, &bill.UserID, &bill.Name, &bill.Amount, &bill.DueDate, &description,
-			&bill.Status, &bill.RemindBefore, &bill.CreatedAt, &bill.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
+	var total int64
+	if err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM bills `+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
 
-		if description.Valid {
-			bill.Description = description.String
-		}
+	column, ok := billSortColumns[filter.SortBy]
+	if !ok {
+		column = billSortColumns[BillSortDueDate]
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
 
-		bills = append(bills, bill)
+	query := `
+		SELECT ` + billColumns + `
+		FROM bills
+		` + b.whereClause() + `
+		ORDER BY ` + column + ` ` + direction + `, id ` + direction + `
+		LIMIT ` + b.arg(filter.Limit) + ` OFFSET ` + b.arg(filter.Offset)
+
+	bills, err := r.queryBills(ctx, query, b.args...)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return bills, nil
+	return bills, total, nil
+}
+
+// FindRecent returns the user's most recently created bills.
+func (r *billRepository) FindRecent(ctx context.Context, userID uint, limit int) ([]entity.Bill, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + billColumns + `
+		FROM bills
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+
+	return r.queryBills(ctx, query, userID, limit)
+}
+
+// escapeLike escapes the LIKE wildcards in a user-supplied search term.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (r *billRepository) FindUpcomingBills(ctx context.Context, userID uint, startDate, endDate time.Time) ([]entity.Bill, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + billColumns + `
+		FROM bills
+		WHERE user_id = $1 AND due_date BETWEEN $2 AND $3 AND status != 'paid'
+		ORDER BY due_date ASC
+	`
+
+	return r.queryBills(ctx, query, userID, startDate, endDate)
 }
 
-func (r *billRepository) Update(bill entity.Bill) (entity.Bill, error) {
+// FindDueForReminder returns unpaid bills across all users whose reminder
+// window (due_date - remind_before days) has opened and that have not been
+// reminded yet, or whose snoozed reminder is due again. Bills already past
+// their due date are left to the overdue flow.
+func (r *billRepository) FindDueForReminder(ctx context.Context, now time.Time) ([]entity.Bill, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	query := `
+		SELECT ` + billColumns + `
+		FROM bills
+		WHERE status != 'paid'
+			AND reminded_at IS NULL
+			AND (reminder_snoozed_until IS NULL OR reminder_snoozed_until <= $2)
+			AND due_date >= $1
+			AND due_date - (remind_before * INTERVAL '1 day') <= $2
+		ORDER BY due_date ASC
+	`
+
+	return r.queryBills(ctx, query, startOfDay, now)
+}
+
+func (r *billRepository) MarkReminded(ctx context.Context, id uint, remindedAt time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.execOne(ctx, `UPDATE bills SET reminded_at = $2 WHERE id = $1`, id, remindedAt)
+}
+
+// MarkOverdue moves every unpaid or partially paid bill due before the given
+// time to overdue.
+func (r *billRepository) MarkOverdue(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE bills
-		SET name = $2, amount = $3, due_date = $4, description = $5, status = $6, remind_before = $7, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND user_id = $8
-		RETURNING id, user_id, name, amount, due_date, description, status, remind_before, created_at, updated_at
+		SET status = 'overdue', updated_at = CURRENT_TIMESTAMP
+		WHERE status IN ('unpaid', 'partially_paid') AND due_date < $1
 	`
 
-	var description sql.NullString
-	if bill.Description != "" {
-		description.String = bill.Description
-		description.Valid = true
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
 	}
 
-	err := r.db.QueryRow(query, bill.ID, bill.Name, bill.Amount, bill.DueDate, description, bill.Status, bill.RemindBefore, bill.UserID).Scan(
-		&bill.ID, &bill.UserID, &bill.Name, &bill.Amount, &bill.DueDate, &bill.Description,
-		&bill.Status, &bill.RemindBefore, &bill.CreatedAt, &bill.UpdatedAt,
-	)
+	return result.RowsAffected()
+}
+
+// MarkOverdueByUser is MarkOverdue restricted to a single user's bills.
+func (r *billRepository) MarkOverdueByUser(ctx context.Context, userID uint, before time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE bills
+		SET status = 'overdue', updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND status IN ('unpaid', 'partially_paid') AND due_date < $2
+	`
 
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, userID, before)
 	if err != nil {
-		return entity.Bill{}, err
+		return 0, err
 	}
 
-	return bill, nil
+	return result.RowsAffected()
+}
+
+// FindOverdueUnnotified returns overdue bills whose owner has not been told
+// yet, leaving out those snoozed past now.
+func (r *billRepository) FindOverdueUnnotified(ctx context.Context, now time.Time) ([]entity.Bill, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + billColumns + `
+		FROM bills
+		WHERE status = 'overdue' AND overdue_notified_at IS NULL
+			AND (reminder_snoozed_until IS NULL OR reminder_snoozed_until <= $1)
+		ORDER BY due_date ASC
+	`
+
+	return r.queryBills(ctx, query, now)
+}
+
+func (r *billRepository) MarkOverdueNotified(ctx context.Context, id uint, notifiedAt time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.execOne(ctx, `UPDATE bills SET overdue_notified_at = $2 WHERE id = $1`, id, notifiedAt)
 }
 
-func (r *billRepository) Delete(id, userID uint) error {
-	query := `DELETE FROM bills WHERE id = $1 AND user_id = $2`
-	result, err := r.db.Exec(query, id, userID)
+// SnoozeReminder re-arms the reminder and overdue notice of one of the user's
+// unpaid bills, to be sent again once until has passed.
+func (r *billRepository) SnoozeReminder(ctx context.Context, id, userID uint, until time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.execOne(ctx, `
+		UPDATE bills
+		SET reminded_at = NULL, overdue_notified_at = NULL, reminder_snoozed_until = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND status != 'paid'
+	`, id, userID, until)
+}
+
+// FindOccurrencesAfter returns the not yet paid occurrences of a recurrence
+// with an index greater than the given one.
+func (r *billRepository) FindOccurrencesAfter(ctx context.Context, recurrenceID uint, index int) ([]entity.Bill, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + billColumns + `
+		FROM bills
+		WHERE recurrence_id = $1 AND occurrence_index > $2 AND status != 'paid'
+		ORDER BY occurrence_index ASC
+	`
+
+	return r.queryBills(ctx, query, recurrenceID, index)
+}
+
+// DeleteOccurrencesFrom removes the not yet paid occurrences of a recurrence
+// starting at the given index. Paid occurrences are kept as history.
+func (r *billRepository) DeleteOccurrencesFrom(ctx context.Context, recurrenceID uint, index int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `DELETE FROM bills WHERE recurrence_id = $1 AND occurrence_index >= $2 AND status != 'paid'`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, recurrenceID, index)
+	return err
+}
+
+// execOne runs a statement that is expected to touch exactly one row and
+// reports sql.ErrNoRows when it did not.
+func (r *billRepository) execOne(ctx context.Context, query string, args ...interface{}) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -196,24 +472,122 @@ func (r *billRepository) Delete(id, userID uint) error {
 	return nil
 }
 
-func (r *billRepository) GetDashboardStats(userID uint) (DashboardStats, error) {
+func (r *billRepository) Update(ctx context.Context, bill entity.Bill) (entity.Bill, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	// A new due date opens a new reminder window, so the sent markers are reset.
 	query := `
+		UPDATE bills
+		SET name = $2, amount = $3, due_date = $4, description = $5, status = $6, remind_before = $7, category_id = $9,
+			reminded_at = CASE WHEN due_date = $4 AND remind_before = $7 THEN reminded_at ELSE NULL END,
+			overdue_notified_at = CASE WHEN due_date = $4 THEN overdue_notified_at ELSE NULL END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $8
+		RETURNING ` + billColumns
+
+	var description sql.NullString
+	if bill.Description != "" {
+		description.String = bill.Description
+		description.Valid = true
+	}
+
+	return scanBill(conn(ctx, r.db).QueryRowContext(ctx, query, bill.ID, bill.Name, bill.Amount, bill.DueDate, description, bill.Status,
+		bill.RemindBefore, bill.UserID, nullableID(bill.CategoryID)))
+}
+
+// SetTags replaces the tags on a bill.
+func (r *billRepository) SetTags(ctx context.Context, billID uint, tagIDs []uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bill_tags WHERE bill_id = $1`, billID); err != nil {
+		return err
+	}
+
+	for _, tagID := range tagIDs {
+		_, err := tx.ExecContext(ctx, `INSERT INTO bill_tags (bill_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, billID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func nullableID(id *uint) sql.NullInt64 {
+	if id == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*id), Valid: true}
+}
+
+// UpdateStatus sets a bill's status and reports whether it changed. Of several
+// concurrent calls moving a bill to the same status only one sees a change.
+func (r *billRepository) UpdateStatus(ctx context.Context, id uint, status string) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE bills
+		SET status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status <> $2
+		RETURNING id
+	`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id, status).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *billRepository) Delete(ctx context.Context, id, userID uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.execOne(ctx, `DELETE FROM bills WHERE id = $1 AND user_id = $2`, id, userID)
+}
+
+// GetDashboardStats summarises a user's bills. Paid amounts come from the
+// payment ledger; unpaid and overdue amounts are the outstanding balances.
+func (r *billRepository) GetDashboardStats(ctx context.Context, userID uint) (DashboardStats, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH paid AS (
+			SELECT bill_id, SUM(amount) AS amount
+			FROM payments
+			WHERE user_id = $1 AND voided_at IS NULL
+			GROUP BY bill_id
+		)
 		SELECT
 			COUNT(*) as total_bills,
-			COUNT(CASE WHEN status = 'paid' THEN 1 END) as paid_bills,
-			COUNT(CASE WHEN status = 'unpaid' THEN 1 END) as unpaid_bills,
-			COUNT(CASE WHEN status = 'overdue' THEN 1 END) as overdue_bills,
-			COALESCE(SUM(amount), 0) as total_amount,
-			COALESCE(SUM(CASE WHEN status = 'paid' THEN amount END), 0) as paid_amount,
-			COALESCE(SUM(CASE WHEN status = 'unpaid' THEN amount END), 0) as unpaid_amount,
-			COALESCE(SUM(CASE WHEN status = 'overdue' THEN amount END), 0) as overdue_amount
-		FROM bills
-		WHERE user_id = $1
+			COUNT(CASE WHEN b.status = 'paid' THEN 1 END) as paid_bills,
+			COUNT(CASE WHEN b.status = 'unpaid' THEN 1 END) as unpaid_bills,
+			COUNT(CASE WHEN b.status = 'partially_paid' THEN 1 END) as partially_paid_bills,
+			COUNT(CASE WHEN b.status = 'overdue' THEN 1 END) as overdue_bills,
+			COALESCE(SUM(b.amount), 0) as total_amount,
+			COALESCE(SUM(p.amount), 0) as paid_amount,
+			COALESCE(SUM(CASE WHEN b.status IN ('unpaid', 'partially_paid')
+				THEN GREATEST(b.amount - COALESCE(p.amount, 0), 0) END), 0) as unpaid_amount,
+			COALESCE(SUM(CASE WHEN b.status = 'overdue'
+				THEN GREATEST(b.amount - COALESCE(p.amount, 0), 0) END), 0) as overdue_amount
+		FROM bills b
+		LEFT JOIN paid p ON p.bill_id = b.id
+		WHERE b.user_id = $1
 	`
 
 	var stats DashboardStats
-	err := r.db.QueryRow(query, userID).Scan(
-		&stats.TotalBills, &stats.PaidBills, &stats.UnpaidBills, &stats.OverdueBills,
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(
+		&stats.TotalBills, &stats.PaidBills, &stats.UnpaidBills, &stats.PartiallyPaidBills, &stats.OverdueBills,
 		&stats.TotalAmount, &stats.PaidAmount, &stats.UnpaidAmount, &stats.OverdueAmount,
 	)
 
@@ -221,5 +595,59 @@ func (r *billRepository) GetDashboardStats(userID uint) (DashboardStats, error)
 		return DashboardStats{}, err
 	}
 
+	stats.Categories, err = r.getCategoryStats(ctx, userID)
+	if err != nil {
+		return DashboardStats{}, err
+	}
+
 	return stats, nil
-}
\ No newline at end of file
+}
+
+// getCategoryStats totals a user's bills per category, largest first, with
+// uncategorised bills last.
+func (r *billRepository) getCategoryStats(ctx context.Context, userID uint) ([]CategoryStats, error) {
+	query := `
+		WITH paid AS (
+			SELECT bill_id, SUM(amount) AS amount
+			FROM payments
+			WHERE user_id = $1 AND voided_at IS NULL
+			GROUP BY bill_id
+		)
+		SELECT
+			c.id, COALESCE(c.name, 'Uncategorized'), COALESCE(c.color, ''),
+			COUNT(*) as bill_count,
+			COALESCE(SUM(b.amount), 0) as total_amount,
+			COALESCE(SUM(p.amount), 0) as paid_amount,
+			COALESCE(SUM(GREATEST(b.amount - COALESCE(p.amount, 0), 0)), 0) as unpaid_amount
+		FROM bills b
+		LEFT JOIN categories c ON c.id = b.category_id
+		LEFT JOIN paid p ON p.bill_id = b.id
+		WHERE b.user_id = $1
+		GROUP BY c.id, c.name, c.color
+		ORDER BY c.id IS NULL, total_amount DESC, c.name ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []CategoryStats
+	for rows.Next() {
+		var category CategoryStats
+		var categoryID sql.NullInt64
+		err := rows.Scan(&categoryID, &category.Name, &category.Color, &category.BillCount,
+			&category.TotalAmount, &category.PaidAmount, &category.UnpaidAmount)
+		if err != nil {
+			return nil, err
+		}
+		if categoryID.Valid {
+			id := uint(categoryID.Int64)
+			category.CategoryID = &id
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
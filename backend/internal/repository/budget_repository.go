@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+type BudgetRepository interface {
+	Create(ctx context.Context, budget entity.Budget) (entity.Budget, error)
+	FindByID(ctx context.Context, id, userID uint) (entity.Budget, error)
+	FindByCategory(ctx context.Context, userID, categoryID uint) (entity.Budget, error)
+	Update(ctx context.Context, budget entity.Budget) (entity.Budget, error)
+	Delete(ctx context.Context, id, userID uint) error
+	GetUsage(ctx context.Context, userID uint, periodStart, periodEnd time.Time) ([]entity.BudgetUsage, error)
+	FindAllUsage(ctx context.Context, periodStart, periodEnd time.Time) ([]entity.BudgetUsage, error)
+	RecordAlert(ctx context.Context, budgetID uint, periodStart time.Time, threshold int, sentAt time.Time) error
+}
+
+const budgetColumns = `id, user_id, category_id, amount, created_at, updated_at`
+
+type budgetRepository struct {
+	db *sql.DB
+}
+
+func NewBudgetRepository(db *sql.DB) BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+func scanBudget(row rowScanner) (entity.Budget, error) {
+	var budget entity.Budget
+	err := row.Scan(&budget.ID, &budget.UserID, &budget.CategoryID, &budget.Amount, &budget.CreatedAt, &budget.UpdatedAt)
+	return budget, err
+}
+
+func (r *budgetRepository) Create(ctx context.Context, budget entity.Budget) (entity.Budget, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO budgets (user_id, category_id, amount)
+		VALUES ($1, $2, $3)
+		RETURNING ` + budgetColumns
+
+	return scanBudget(r.db.QueryRowContext(ctx, query, budget.UserID, budget.CategoryID, budget.Amount))
+}
+
+func (r *budgetRepository) FindByID(ctx context.Context, id, userID uint) (entity.Budget, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM budgets
+		WHERE id = $1 AND user_id = $2
+	`
+
+	return scanBudget(r.db.QueryRowContext(ctx, query, id, userID))
+}
+
+func (r *budgetRepository) FindByCategory(ctx context.Context, userID, categoryID uint) (entity.Budget, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM budgets
+		WHERE user_id = $1 AND category_id = $2
+	`
+
+	return scanBudget(r.db.QueryRowContext(ctx, query, userID, categoryID))
+}
+
+func (r *budgetRepository) Update(ctx context.Context, budget entity.Budget) (entity.Budget, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE budgets
+		SET amount = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + budgetColumns
+
+	return scanBudget(r.db.QueryRowContext(ctx, query, budget.ID, budget.UserID, budget.Amount))
+}
+
+func (r *budgetRepository) Delete(ctx context.Context, id, userID uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM budgets WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetUsage compares each of the user's budgets with the bills in its category
+// due within [periodStart, periodEnd).
+func (r *budgetRepository) GetUsage(ctx context.Context, userID uint, periodStart, periodEnd time.Time) ([]entity.BudgetUsage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.queryUsage(ctx, `WHERE bu.user_id = $4`, periodStart, periodEnd, userID)
+}
+
+// FindAllUsage is GetUsage across every user.
+func (r *budgetRepository) FindAllUsage(ctx context.Context, periodStart, periodEnd time.Time) ([]entity.BudgetUsage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.queryUsage(ctx, ``, periodStart, periodEnd)
+}
+
+// queryUsage sums the bills of each budget's category for the period. Payments
+// count towards Paid up to each bill's amount.
+func (r *budgetRepository) queryUsage(ctx context.Context, where string, periodStart, periodEnd time.Time, args ...interface{}) ([]entity.BudgetUsage, error) {
+	query := `
+		WITH paid AS (
+			SELECT bill_id, SUM(amount) AS amount
+			FROM payments
+			WHERE voided_at IS NULL
+			GROUP BY bill_id
+		)
+		SELECT
+			bu.id, bu.user_id, bu.category_id, bu.amount, bu.created_at, bu.updated_at, c.name,
+			COALESCE(SUM(b.amount), 0) as spent,
+			COALESCE(SUM(LEAST(COALESCE(p.amount, 0), b.amount)), 0) as paid,
+			COALESCE((SELECT MAX(ba.threshold) FROM budget_alerts ba
+				WHERE ba.budget_id = bu.id AND ba.period_start = $3::date), 0) as alerted_threshold
+		FROM budgets bu
+		JOIN categories c ON c.id = bu.category_id
+		LEFT JOIN bills b ON b.user_id = bu.user_id AND b.category_id = bu.category_id
+			AND b.due_date >= $1 AND b.due_date < $2
+		LEFT JOIN paid p ON p.bill_id = b.id
+		` + where + `
+		GROUP BY bu.id, c.name
+		ORDER BY bu.user_id ASC, c.name ASC
+	`
+
+	args = append([]interface{}{periodStart, periodEnd, periodStart.Format("2006-01-02")}, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []entity.BudgetUsage
+	for rows.Next() {
+		usage := entity.BudgetUsage{PeriodStart: periodStart, PeriodEnd: periodEnd}
+		err := rows.Scan(
+			&usage.ID, &usage.UserID, &usage.CategoryID, &usage.Amount, &usage.CreatedAt, &usage.UpdatedAt,
+			&usage.CategoryName, &usage.Spent, &usage.Paid, &usage.AlertedThreshold,
+		)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, rows.Err()
+}
+
+// RecordAlert stores that the threshold has been announced for the period.
+func (r *budgetRepository) RecordAlert(ctx context.Context, budgetID uint, periodStart time.Time, threshold int, sentAt time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO budget_alerts (budget_id, period_start, threshold, sent_at)
+		VALUES ($1, $2::date, $3, $4)
+		ON CONFLICT (budget_id, period_start, threshold) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, budgetID, periodStart.Format("2006-01-02"), threshold, sentAt)
+	return err
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category entity.Category) (entity.Category, error)
+	FindByID(ctx context.Context, id, userID uint) (entity.Category, error)
+	FindByName(ctx context.Context, userID uint, name string) (entity.Category, error)
+	FindByUserID(ctx context.Context, userID uint) ([]entity.Category, error)
+	Update(ctx context.Context, category entity.Category) (entity.Category, error)
+	Delete(ctx context.Context, id, userID uint) error
+}
+
+const categoryColumns = `id, user_id, name, color, created_at, updated_at`
+
+type categoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func scanCategory(row rowScanner) (entity.Category, error) {
+	var category entity.Category
+	var color sql.NullString
+	err := row.Scan(&category.ID, &category.UserID, &category.Name, &color, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		return entity.Category{}, err
+	}
+
+	if color.Valid {
+		category.Color = color.String
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category entity.Category) (entity.Category, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO categories (user_id, name, color)
+		VALUES ($1, $2, NULLIF($3, ''))
+		RETURNING ` + categoryColumns
+
+	return scanCategory(r.db.QueryRowContext(ctx, query, category.UserID, category.Name, category.Color))
+}
+
+func (r *categoryRepository) FindByID(ctx context.Context, id, userID uint) (entity.Category, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE id = $1 AND user_id = $2
+	`
+
+	return scanCategory(r.db.QueryRowContext(ctx, query, id, userID))
+}
+
+// FindByName looks a category up case-insensitively.
+func (r *categoryRepository) FindByName(ctx context.Context, userID uint, name string) (entity.Category, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE user_id = $1 AND LOWER(name) = LOWER($2)
+	`
+
+	return scanCategory(r.db.QueryRowContext(ctx, query, userID, name))
+}
+
+func (r *categoryRepository) FindByUserID(ctx context.Context, userID uint) ([]entity.Category, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []entity.Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category entity.Category) (entity.Category, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE categories
+		SET name = $3, color = NULLIF($4, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + categoryColumns
+
+	return scanCategory(r.db.QueryRowContext(ctx, query, category.ID, category.UserID, category.Name, category.Color))
+}
+
+// Delete removes a category. Bills and recurrences in it become uncategorised.
+func (r *categoryRepository) Delete(ctx context.Context, id, userID uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+)
+
+type NotificationPreferenceRepository interface {
+	FindByUser(ctx context.Context, userID uint) ([]entity.NotificationPreference, error)
+	Save(ctx context.Context, userID uint, prefs []entity.NotificationPreference) error
+	SaveExclusive(ctx context.Context, userID uint, pref entity.NotificationPreference) error
+	FindUserByAddress(ctx context.Context, channel, address string) (uint, error)
+}
+
+type notificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationPreferenceRepository(db *sql.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+// FindByUser returns the channels the user has configured. Channels they
+// never configured have no entry.
+func (r *notificationPreferenceRepository) FindByUser(ctx context.Context, userID uint) ([]entity.NotificationPreference, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT channel, enabled, address
+		FROM user_notification_channels
+		WHERE user_id = $1
+		ORDER BY channel
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []entity.NotificationPreference
+	for rows.Next() {
+		var pref entity.NotificationPreference
+		if err := rows.Scan(&pref.Channel, &pref.Enabled, &pref.Address); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, pref)
+	}
+
+	return prefs, rows.Err()
+}
+
+// Save creates or replaces the user's settings for the given channels,
+// leaving other channels untouched.
+func (r *notificationPreferenceRepository) Save(ctx context.Context, userID uint, prefs []entity.NotificationPreference) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, pref := range prefs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO user_notification_channels (user_id, channel, enabled, address)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, channel) DO UPDATE
+			SET enabled = EXCLUDED.enabled, address = EXCLUDED.address, updated_at = CURRENT_TIMESTAMP
+		`, userID, pref.Channel, pref.Enabled, pref.Address)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveExclusive saves the user's setting for one channel and removes its
+// address from every other user's setting, so the address belongs to one
+// account only. Those users' channel is disabled.
+func (r *notificationPreferenceRepository) SaveExclusive(ctx context.Context, userID uint, pref entity.NotificationPreference) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE user_notification_channels
+		SET enabled = false, address = '', updated_at = CURRENT_TIMESTAMP
+		WHERE channel = $1 AND address = $2 AND user_id <> $3
+	`, pref.Channel, pref.Address, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_notification_channels (user_id, channel, enabled, address)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, channel) DO UPDATE
+		SET enabled = EXCLUDED.enabled, address = EXCLUDED.address, updated_at = CURRENT_TIMESTAMP
+	`, userID, pref.Channel, pref.Enabled, pref.Address)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindUserByAddress returns the user reached at address on a channel.
+// Unknown addresses report sql.ErrNoRows.
+func (r *notificationPreferenceRepository) FindUserByAddress(ctx context.Context, channel, address string) (uint, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var userID uint
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id
+		FROM user_notification_channels
+		WHERE channel = $1 AND address = $2
+	`, channel, address).Scan(&userID)
+	return userID, err
+}
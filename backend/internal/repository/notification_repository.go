@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+type NotificationRepository interface {
+	Enqueue(ctx context.Context, n entity.QueuedNotification) (bool, error)
+	Claim(ctx context.Context, now, lockedUntil time.Time) (entity.QueuedNotification, error)
+	MarkSent(ctx context.Context, id uint, response string, sentAt time.Time) error
+	RecordFailure(ctx context.Context, id uint, lastError, response string, retryAt *time.Time) error
+	Release(ctx context.Context, id uint) error
+	FindPage(ctx context.Context, filter NotificationFilter) ([]entity.QueuedNotification, int64, error)
+}
+
+// NotificationFilter selects a page of one user's notifications, newest
+// first. An empty Status matches every status.
+type NotificationFilter struct {
+	UserID uint
+	Status string
+	Limit  int
+	Offset int
+}
+
+const notificationColumns = `id, user_id, channel, kind, bill_id, reference, recipient, payload, status, attempts, max_attempts,
+	next_attempt_at, last_error, provider_response, sent_at, created_at, updated_at`
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func scanNotification(row rowScanner) (entity.QueuedNotification, error) {
+	var n entity.QueuedNotification
+	var billID sql.NullInt64
+	var sentAt sql.NullTime
+	var payload []byte
+	err := row.Scan(
+		&n.ID, &n.UserID, &n.Channel, &n.Kind, &billID, &n.Reference, &n.Recipient, &payload, &n.Status, &n.Attempts, &n.MaxAttempts,
+		&n.NextAttemptAt, &n.LastError, &n.ProviderResponse, &sentAt, &n.CreatedAt, &n.UpdatedAt,
+	)
+	if err != nil {
+		return entity.QueuedNotification{}, err
+	}
+
+	if billID.Valid {
+		id := uint(billID.Int64)
+		n.BillID = &id
+	}
+	n.SentAt = nullTimePtr(sentAt)
+	if err := json.Unmarshal(payload, &n.Payload); err != nil {
+		return entity.QueuedNotification{}, err
+	}
+	return n, nil
+}
+
+// Enqueue adds a pending notification. It reports false, without error, when
+// the bill already has a notification of the same kind and reference on the
+// channel.
+func (r *notificationRepository) Enqueue(ctx context.Context, n entity.QueuedNotification) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	payload, err := json.Marshal(n.Payload)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO notifications (user_id, channel, kind, bill_id, reference, recipient, payload, max_attempts, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT DO NOTHING
+	`, n.UserID, n.Channel, n.Kind, n.BillID, n.Reference, n.Recipient, payload, n.MaxAttempts, n.NextAttemptAt)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// Claim takes the next notification due for delivery at now and holds it for
+// the caller until lockedUntil. Notifications whose previous holder let its
+// hold expire are claimed again. It reports sql.ErrNoRows when none is due.
+func (r *notificationRepository) Claim(ctx context.Context, now, lockedUntil time.Time) (entity.QueuedNotification, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE notifications
+		SET status = 'sending', locked_until = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM notifications
+			WHERE (status = 'pending' AND next_attempt_at <= $1)
+				OR (status = 'sending' AND locked_until <= $1)
+			ORDER BY next_attempt_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+notificationColumns, now, lockedUntil)
+	return scanNotification(row)
+}
+
+func (r *notificationRepository) MarkSent(ctx context.Context, id uint, response string, sentAt time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = 'sent', attempts = attempts + 1, provider_response = $2, last_error = '', sent_at = $3,
+			locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, response, sentAt)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// RecordFailure counts a failed delivery. The notification is retried at
+// retryAt, or marked failed for good when retryAt is nil.
+func (r *notificationRepository) RecordFailure(ctx context.Context, id uint, lastError, response string, retryAt *time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	status := entity.NotificationFailed
+	var nextAttemptAt sql.NullTime
+	if retryAt != nil {
+		status = entity.NotificationPending
+		nextAttemptAt = sql.NullTime{Time: *retryAt, Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = $2, attempts = attempts + 1, last_error = $3, provider_response = $4,
+			next_attempt_at = COALESCE($5, next_attempt_at), locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, status, lastError, response, nextAttemptAt)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// Release returns a claimed notification to the queue without counting an
+// attempt, for deliveries interrupted by shutdown.
+func (r *notificationRepository) Release(ctx context.Context, id uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET status = 'pending', locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'sending'
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+func (r *notificationRepository) FindPage(ctx context.Context, filter NotificationFilter) ([]entity.QueuedNotification, int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var b queryBuilder
+	b.where("user_id = ?", filter.UserID)
+	if filter.Status != "" {
+		b.where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications `+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+notificationColumns+`
+		FROM notifications
+		`+b.whereClause()+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT `+b.arg(filter.Limit)+` OFFSET `+b.arg(filter.Offset), b.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var notifications []entity.QueuedNotification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, total, rows.Err()
+}
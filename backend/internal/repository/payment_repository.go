@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+// ErrPaymentExceedsBalance is returned when a payment is larger than what is
+// still owed on its bill.
+var ErrPaymentExceedsBalance = errors.New("payment exceeds the outstanding balance")
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment entity.Payment) (entity.Payment, error)
+	Settle(ctx context.Context, payment entity.Payment) (entity.Payment, error)
+	FindByID(ctx context.Context, id, userID uint) (entity.Payment, error)
+	FindByBillID(ctx context.Context, billID, userID uint) ([]entity.Payment, error)
+	Void(ctx context.Context, id, userID uint, reason string, voidedAt time.Time) (entity.Payment, error)
+}
+
+const paymentColumns = `id, bill_id, user_id, amount, paid_at, method, reference, note, voided_at, void_reason, created_at, updated_at`
+
+type paymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func scanPayment(row rowScanner) (entity.Payment, error) {
+	var payment entity.Payment
+	var reference, note, voidReason sql.NullString
+	var voidedAt sql.NullTime
+	err := row.Scan(
+		&payment.ID, &payment.BillID, &payment.UserID, &payment.Amount, &payment.PaidAt, &payment.Method,
+		&reference, &note, &voidedAt, &voidReason, &payment.CreatedAt, &payment.UpdatedAt,
+	)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+
+	payment.Reference = reference.String
+	payment.Note = note.String
+	payment.VoidReason = voidReason.String
+	if voidedAt.Valid {
+		payment.VoidedAt = &voidedAt.Time
+	}
+
+	return payment, nil
+}
+
+// Create records a payment against one of the user's bills. Payments larger
+// than the outstanding balance report ErrPaymentExceedsBalance, and unknown
+// bills sql.ErrNoRows.
+func (r *paymentRepository) Create(ctx context.Context, payment entity.Payment) (entity.Payment, error) {
+	return r.insertWithinBalance(ctx, payment, false)
+}
+
+// Settle records a payment of whatever is still owed on the bill, ignoring
+// payment.Amount. It reports ErrPaymentExceedsBalance when nothing is owed.
+func (r *paymentRepository) Settle(ctx context.Context, payment entity.Payment) (entity.Payment, error) {
+	return r.insertWithinBalance(ctx, payment, true)
+}
+
+// insertWithinBalance locks the bill while comparing the payment with its
+// balance, so concurrent payments cannot together pay more than is owed.
+func (r *paymentRepository) insertWithinBalance(ctx context.Context, payment entity.Payment, settle bool) (entity.Payment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+	defer tx.Rollback()
+
+	var amount entity.Money
+	err = tx.QueryRowContext(ctx, `SELECT amount FROM bills WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		payment.BillID, payment.UserID).Scan(&amount)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+
+	// Read after taking the lock, so payments committed meanwhile are counted
+	var paid entity.Money
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(amount), 0) FROM payments WHERE bill_id = $1 AND voided_at IS NULL`,
+		payment.BillID).Scan(&paid)
+	if err != nil {
+		return entity.Payment{}, err
+	}
+
+	outstanding := amount - paid
+	if settle {
+		payment.Amount = outstanding
+	}
+	if payment.Amount <= 0 || payment.Amount > outstanding {
+		return entity.Payment{}, ErrPaymentExceedsBalance
+	}
+
+	query := `
+		INSERT INTO payments (bill_id, user_id, amount, paid_at, method, reference, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + paymentColumns
+
+	created, err := scanPayment(tx.QueryRowContext(ctx, query, payment.BillID, payment.UserID, payment.Amount, payment.PaidAt,
+		payment.Method, payment.Reference, payment.Note))
+	if err != nil {
+		return entity.Payment{}, err
+	}
+
+	return created, tx.Commit()
+}
+
+func (r *paymentRepository) FindByID(ctx context.Context, id, userID uint) (entity.Payment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE id = $1 AND user_id = $2
+	`
+
+	return scanPayment(conn(ctx, r.db).QueryRowContext(ctx, query, id, userID))
+}
+
+func (r *paymentRepository) FindByBillID(ctx context.Context, billID, userID uint) ([]entity.Payment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE bill_id = $1 AND user_id = $2
+		ORDER BY paid_at ASC, id ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, billID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []entity.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+// Void marks a payment as voided. Voiding an already voided payment returns
+// sql.ErrNoRows.
+func (r *paymentRepository) Void(ctx context.Context, id, userID uint, reason string, voidedAt time.Time) (entity.Payment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE payments
+		SET voided_at = $3, void_reason = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND voided_at IS NULL
+		RETURNING ` + paymentColumns
+
+	return scanPayment(conn(ctx, r.db).QueryRowContext(ctx, query, id, userID, voidedAt, reason))
+}
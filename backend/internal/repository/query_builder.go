@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// queryBuilder collects WHERE conditions and their arguments, numbering the
+// PostgreSQL placeholders as conditions are added.
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// where adds a condition in which each "?" stands for the next argument.
+func (b *queryBuilder) where(condition string, args ...interface{}) {
+	for _, arg := range args {
+		b.args = append(b.args, arg)
+		condition = strings.Replace(condition, "?", b.placeholder(), 1)
+	}
+	b.conditions = append(b.conditions, condition)
+}
+
+// arg adds an argument outside of any condition, e.g. for LIMIT, and returns
+// its placeholder.
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return b.placeholder()
+}
+
+func (b *queryBuilder) placeholder() string {
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// whereClause returns the conditions joined with AND, or an empty string.
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+)
+
+type RecurrenceRepository interface {
+	Create(ctx context.Context, recurrence entity.Recurrence) (entity.Recurrence, error)
+	FindByID(ctx context.Context, id, userID uint) (entity.Recurrence, error)
+	FindByUserID(ctx context.Context, userID uint) ([]entity.Recurrence, error)
+	FindActive(ctx context.Context) ([]entity.Recurrence, error)
+	Update(ctx context.Context, recurrence entity.Recurrence) (entity.Recurrence, error)
+	AdvanceNextIndex(ctx context.Context, id uint, index int) error
+}
+
+const recurrenceColumns = `id, user_id, frequency, interval_count, anchor_date, anchor_index, anchor_day, end_date,
+	max_occurrences, next_index, name, amount, description, remind_before, active, category_id, created_at, updated_at`
+
+type recurrenceRepository struct {
+	db *sql.DB
+}
+
+func NewRecurrenceRepository(db *sql.DB) RecurrenceRepository {
+	return &recurrenceRepository{db: db}
+}
+
+func scanRecurrence(row rowScanner) (entity.Recurrence, error) {
+	var recurrence entity.Recurrence
+	var endDate sql.NullTime
+	var maxOccurrences sql.NullInt64
+	var description sql.NullString
+	var categoryID sql.NullInt64
+	err := row.Scan(
+		&recurrence.ID, &recurrence.UserID, &recurrence.Frequency, &recurrence.Interval,
+		&recurrence.AnchorDate, &recurrence.AnchorIndex, &recurrence.AnchorDay, &endDate, &maxOccurrences,
+		&recurrence.NextIndex, &recurrence.Name, &recurrence.Amount, &description,
+		&recurrence.RemindBefore, &recurrence.Active, &categoryID, &recurrence.CreatedAt, &recurrence.UpdatedAt,
+	)
+	if err != nil {
+		return entity.Recurrence{}, err
+	}
+
+	if endDate.Valid {
+		recurrence.EndDate = &endDate.Time
+	}
+	if maxOccurrences.Valid {
+		count := int(maxOccurrences.Int64)
+		recurrence.MaxOccurrences = &count
+	}
+	if description.Valid {
+		recurrence.Description = description.String
+	}
+	if categoryID.Valid {
+		id := uint(categoryID.Int64)
+		recurrence.CategoryID = &id
+	}
+
+	return recurrence, nil
+}
+
+func (r *recurrenceRepository) queryRecurrences(ctx context.Context, query string, args ...interface{}) ([]entity.Recurrence, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recurrences []entity.Recurrence
+	for rows.Next() {
+		recurrence, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, err
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+
+	return recurrences, rows.Err()
+}
+
+func nullableLimits(recurrence entity.Recurrence) (sql.NullTime, sql.NullInt64) {
+	var endDate sql.NullTime
+	if recurrence.EndDate != nil {
+		endDate = sql.NullTime{Time: *recurrence.EndDate, Valid: true}
+	}
+
+	var maxOccurrences sql.NullInt64
+	if recurrence.MaxOccurrences != nil {
+		maxOccurrences = sql.NullInt64{Int64: int64(*recurrence.MaxOccurrences), Valid: true}
+	}
+
+	return endDate, maxOccurrences
+}
+
+func (r *recurrenceRepository) Create(ctx context.Context, recurrence entity.Recurrence) (entity.Recurrence, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO bill_recurrences (user_id, frequency, interval_count, anchor_date, anchor_index, end_date,
+			max_occurrences, next_index, name, amount, description, remind_before, active, category_id, anchor_day)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING ` + recurrenceColumns
+
+	endDate, maxOccurrences := nullableLimits(recurrence)
+	return scanRecurrence(conn(ctx, r.db).QueryRowContext(ctx, query,
+		recurrence.UserID, recurrence.Frequency, recurrence.Interval, recurrence.AnchorDate, recurrence.AnchorIndex,
+		endDate, maxOccurrences, recurrence.NextIndex, recurrence.Name, recurrence.Amount, recurrence.Description,
+		recurrence.RemindBefore, recurrence.Active, nullableID(recurrence.CategoryID), recurrence.AnchorDay,
+	))
+}
+
+func (r *recurrenceRepository) FindByID(ctx context.Context, id, userID uint) (entity.Recurrence, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + recurrenceColumns + `
+		FROM bill_recurrences
+		WHERE id = $1 AND user_id = $2
+	`
+
+	return scanRecurrence(conn(ctx, r.db).QueryRowContext(ctx, query, id, userID))
+}
+
+func (r *recurrenceRepository) FindByUserID(ctx context.Context, userID uint) ([]entity.Recurrence, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + recurrenceColumns + `
+		FROM bill_recurrences
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+
+	return r.queryRecurrences(ctx, query, userID)
+}
+
+func (r *recurrenceRepository) FindActive(ctx context.Context) ([]entity.Recurrence, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + recurrenceColumns + `
+		FROM bill_recurrences
+		WHERE active
+		ORDER BY id ASC
+	`
+
+	return r.queryRecurrences(ctx, query)
+}
+
+func (r *recurrenceRepository) Update(ctx context.Context, recurrence entity.Recurrence) (entity.Recurrence, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE bill_recurrences
+		SET frequency = $3, interval_count = $4, anchor_date = $5, anchor_index = $6, end_date = $7,
+			max_occurrences = $8, name = $9, amount = $10, description = $11, remind_before = $12, active = $13,
+			category_id = $14, anchor_day = $15, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + recurrenceColumns
+
+	endDate, maxOccurrences := nullableLimits(recurrence)
+	return scanRecurrence(conn(ctx, r.db).QueryRowContext(ctx, query,
+		recurrence.ID, recurrence.UserID, recurrence.Frequency, recurrence.Interval, recurrence.AnchorDate,
+		recurrence.AnchorIndex, endDate, maxOccurrences, recurrence.Name, recurrence.Amount, recurrence.Description,
+		recurrence.RemindBefore, recurrence.Active, nullableID(recurrence.CategoryID), recurrence.AnchorDay,
+	))
+}
+
+// AdvanceNextIndex records that the occurrence with the given index has been
+// generated. It never moves the counter backwards.
+func (r *recurrenceRepository) AdvanceNextIndex(ctx context.Context, id uint, index int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE bill_recurrences SET next_index = GREATEST(next_index, $2 + 1) WHERE id = $1`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, index)
+	return err
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session entity.Session, token entity.RefreshToken) (entity.Session, error)
+	FindByID(ctx context.Context, id uint) (entity.Session, error)
+	FindRefreshToken(ctx context.Context, tokenHash string) (entity.RefreshToken, error)
+	Rotate(ctx context.Context, used entity.RefreshToken, next entity.RefreshToken, at time.Time) error
+	Revoke(ctx context.Context, id, userID uint, at time.Time) error
+	RevokeAllForUser(ctx context.Context, userID uint, at time.Time) (int64, error)
+	RevokeOthers(ctx context.Context, userID, keepID uint, at time.Time) (int64, error)
+}
+
+const sessionColumns = `id, user_id, user_agent, ip_address, expires_at, last_used_at, revoked_at, created_at`
+
+const refreshTokenColumns = `id, session_id, token_hash, expires_at, used_at, created_at`
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func scanSession(row rowScanner) (entity.Session, error) {
+	var session entity.Session
+	var revokedAt sql.NullTime
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress, &session.ExpiresAt,
+		&session.LastUsedAt, &revokedAt, &session.CreatedAt,
+	)
+	if err != nil {
+		return entity.Session{}, err
+	}
+
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return session, nil
+}
+
+func scanRefreshToken(row rowScanner) (entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	var usedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.SessionID, &token.TokenHash, &token.ExpiresAt, &usedAt, &token.CreatedAt)
+	if err != nil {
+		return entity.RefreshToken{}, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return token, nil
+}
+
+// Create starts a session together with its first refresh token.
+func (r *sessionRepository) Create(ctx context.Context, session entity.Session, token entity.RefreshToken) (entity.Session, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.Session{}, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO sessions (user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + sessionColumns
+
+	created, err := scanSession(tx.QueryRowContext(ctx, query, session.UserID, session.UserAgent, session.IPAddress, session.ExpiresAt))
+	if err != nil {
+		return entity.Session{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+		created.ID, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return entity.Session{}, err
+	}
+
+	return created, tx.Commit()
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uint) (entity.Session, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE id = $1
+	`
+
+	return scanSession(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *sessionRepository) FindRefreshToken(ctx context.Context, tokenHash string) (entity.RefreshToken, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + refreshTokenColumns + `
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	return scanRefreshToken(r.db.QueryRowContext(ctx, query, tokenHash))
+}
+
+// Rotate marks used as spent, issues next in the same session and extends the
+// session to next's expiry. It reports sql.ErrNoRows, changing nothing, when
+// used was already spent or the session has been revoked in the meantime.
+func (r *sessionRepository) Rotate(ctx context.Context, used entity.RefreshToken, next entity.RefreshToken, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL`, used.ID, at)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(result); err != nil {
+		return err
+	}
+
+	result, err = tx.ExecContext(ctx, `
+		UPDATE sessions SET expires_at = $2, last_used_at = $3
+		WHERE id = $1 AND revoked_at IS NULL
+	`, used.SessionID, next.ExpiresAt, at)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(result); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO refresh_tokens (session_id, token_hash, expires_at) VALUES ($1, $2, $3)`,
+		used.SessionID, next.TokenHash, next.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Revoke ends a session. Revoking an already revoked session is not an error.
+func (r *sessionRepository) Revoke(ctx context.Context, id, userID uint, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE id = $1 AND user_id = $2
+	`, id, userID, at)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// RevokeAllForUser ends every active session of a user and returns how many
+// were ended.
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID uint, at time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`, userID, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// RevokeOthers ends every active session of a user except keepID.
+func (r *sessionRepository) RevokeOthers(ctx context.Context, userID, keepID uint, at time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = $3
+		WHERE user_id = $1 AND id != $2 AND revoked_at IS NULL
+	`, userID, keepID, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func expectOneRow(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
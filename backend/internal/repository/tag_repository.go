@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+)
+
+type TagRepository interface {
+	Create(ctx context.Context, tag entity.Tag) (entity.Tag, error)
+	FindByID(ctx context.Context, id, userID uint) (entity.Tag, error)
+	FindByName(ctx context.Context, userID uint, name string) (entity.Tag, error)
+	FindByUserID(ctx context.Context, userID uint) ([]entity.Tag, error)
+	FindOrCreate(ctx context.Context, userID uint, names []string) ([]entity.Tag, error)
+	Update(ctx context.Context, tag entity.Tag) (entity.Tag, error)
+	Delete(ctx context.Context, id, userID uint) error
+}
+
+const tagColumns = `id, user_id, name, created_at, updated_at`
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func scanTag(row rowScanner) (entity.Tag, error) {
+	var tag entity.Tag
+	err := row.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt)
+	return tag, err
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag entity.Tag) (entity.Tag, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO tags (user_id, name)
+		VALUES ($1, $2)
+		RETURNING ` + tagColumns
+
+	return scanTag(r.db.QueryRowContext(ctx, query, tag.UserID, tag.Name))
+}
+
+func (r *tagRepository) FindByID(ctx context.Context, id, userID uint) (entity.Tag, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + tagColumns + `
+		FROM tags
+		WHERE id = $1 AND user_id = $2
+	`
+
+	return scanTag(r.db.QueryRowContext(ctx, query, id, userID))
+}
+
+func (r *tagRepository) FindByName(ctx context.Context, userID uint, name string) (entity.Tag, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + tagColumns + `
+		FROM tags
+		WHERE user_id = $1 AND name = $2
+	`
+
+	return scanTag(r.db.QueryRowContext(ctx, query, userID, name))
+}
+
+func (r *tagRepository) FindByUserID(ctx context.Context, userID uint) ([]entity.Tag, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + tagColumns + `
+		FROM tags
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []entity.Tag
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// FindOrCreate returns the user's tags with the given names, creating the
+// ones that do not exist yet.
+func (r *tagRepository) FindOrCreate(ctx context.Context, userID uint, names []string) ([]entity.Tag, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	// The no-op update makes RETURNING yield existing rows as well.
+	query := `
+		INSERT INTO tags (user_id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING ` + tagColumns
+
+	tags := make([]entity.Tag, 0, len(names))
+	for _, name := range names {
+		tag, err := scanTag(r.db.QueryRowContext(ctx, query, userID, name))
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (r *tagRepository) Update(ctx context.Context, tag entity.Tag) (entity.Tag, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE tags
+		SET name = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + tagColumns
+
+	return scanTag(r.db.QueryRowContext(ctx, query, tag.ID, tag.UserID, tag.Name))
+}
+
+// Delete removes a tag from every bill carrying it.
+func (r *tagRepository) Delete(ctx context.Context, id, userID uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tags WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
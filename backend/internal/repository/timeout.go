@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// queryTimeout bounds each repository call, including all the statements of a
+// transaction. Zero leaves calls bounded only by the caller's context.
+var queryTimeout = 5 * time.Second
+
+// SetQueryTimeout changes the time limit applied to repository calls. It is
+// meant to be called once at startup.
+func SetQueryTimeout(timeout time.Duration) {
+	queryTimeout = timeout
+}
+
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, queryTimeout)
+}
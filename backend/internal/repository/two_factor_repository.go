@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+type TwoFactorRepository interface {
+	Find(ctx context.Context, userID uint) (entity.TwoFactor, error)
+	SavePending(ctx context.Context, userID uint, secret string) error
+	Enable(ctx context.Context, userID uint, step int64, at time.Time, recoveryCodeHashes []string) error
+	UseStep(ctx context.Context, userID uint, step int64) error
+	Delete(ctx context.Context, userID uint) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) error
+	CountRecoveryCodes(ctx context.Context, userID uint) (int, error)
+}
+
+type twoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) Find(ctx context.Context, userID uint) (entity.TwoFactor, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT user_id, secret, enabled_at, last_step, created_at
+		FROM user_totp
+		WHERE user_id = $1
+	`
+
+	var twoFactor entity.TwoFactor
+	var enabledAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&twoFactor.UserID, &twoFactor.Secret, &enabledAt, &twoFactor.LastStep, &twoFactor.CreatedAt,
+	)
+	if err != nil {
+		return entity.TwoFactor{}, err
+	}
+
+	twoFactor.EnabledAt = nullTimePtr(enabledAt)
+	return twoFactor, nil
+}
+
+// SavePending starts, or restarts, an enrollment with a new secret. It
+// reports sql.ErrNoRows when two-factor authentication is already enabled.
+func (r *twoFactorRepository) SavePending(ctx context.Context, userID uint, secret string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_step = 0, created_at = CURRENT_TIMESTAMP
+		WHERE user_totp.enabled_at IS NULL
+	`, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// Enable completes an enrollment, recording step as used, and replaces the
+// user's recovery codes.
+func (r *twoFactorRepository) Enable(ctx context.Context, userID uint, step int64, at time.Time, recoveryCodeHashes []string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_totp SET enabled_at = $2, last_step = $3
+		WHERE user_id = $1 AND enabled_at IS NULL
+	`, userID, at, step)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(result); err != nil {
+		return err
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseStep records that the code for step was accepted. It reports
+// sql.ErrNoRows when that step, or a later one, was already used.
+func (r *twoFactorRepository) UseStep(ctx context.Context, userID uint, step int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE user_totp SET last_step = $2 WHERE user_id = $1 AND last_step < $2`, userID, step)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// Delete turns two-factor authentication off and discards recovery codes.
+func (r *twoFactorRepository) Delete(ctx context.Context, userID uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uint, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, codeHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UseRecoveryCode spends a recovery code. Unknown and already used codes
+// report sql.ErrNoRows.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE recovery_codes SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash, at)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has left.
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uint) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Transactor runs a unit of work in a single database transaction. Repository
+// calls made with the context passed to fn take part in that transaction, so
+// its writes are committed together or not at all.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTx commits when fn returns nil and rolls back otherwise. Called with a
+// context that already carries a transaction, it runs fn in that one.
+func (t *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is the part of *sql.DB and *sql.Tx the repositories use.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction ctx carries, or db outside of one.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// scopedTx is a transaction a repository method needs for its own statements.
+// When it joined the caller's transaction, committing and rolling back are
+// left to the caller.
+type scopedTx struct {
+	*sql.Tx
+	joined bool
+}
+
+// beginTx starts a transaction on db, or joins the one ctx carries.
+func beginTx(ctx context.Context, db *sql.DB) (scopedTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return scopedTx{Tx: tx, joined: true}, nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	return scopedTx{Tx: tx}, err
+}
+
+func (tx scopedTx) Commit() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Commit()
+}
+
+func (tx scopedTx) Rollback() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Rollback()
+}
@@ -1,16 +1,35 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"financebroke/backend/internal/entity"
 	"financebroke/backend/internal/utils"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// ErrEmailExists is returned when an email address is already used by
+// another account.
+var ErrEmailExists = errors.New("email already exists")
+
+// uniqueViolation is the PostgreSQL error code for a violated unique
+// constraint.
+const uniqueViolation = "23505"
+
 type UserRepository interface {
-	Create(user entity.User) (entity.User, error)
-	FindByEmail(email string) (entity.User, error)
-	FindByID(id uint) (entity.User, error)
-	UpdateNotificationSettings(id uint, chatID string, emailNotify, telegramNotify bool) (entity.User, error)
+	Create(ctx context.Context, user entity.User) (entity.User, error)
+	FindByEmail(ctx context.Context, email string) (entity.User, error)
+	FindByID(ctx context.Context, id uint) (entity.User, error)
+	FindPasswordHash(ctx context.Context, id uint) (string, error)
+	UpdatePassword(ctx context.Context, id uint, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id uint, at time.Time) error
+	UpdateName(ctx context.Context, id uint, name string) (entity.User, error)
+	UpdateEmail(ctx context.Context, id uint, email string, verifiedAt time.Time) error
+	ScheduleDeletion(ctx context.Context, id uint, at *time.Time) error
+	DeleteScheduled(ctx context.Context, before time.Time) (int64, error)
 }
 
 type userRepository struct {
@@ -21,8 +40,11 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user entity.User) (entity.User, error) {
-	logger := utils.GetLogger()
+func (r *userRepository) Create(ctx context.Context, user entity.User) (entity.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	logger := utils.FromContext(ctx)
 	logger.Info("[REPO] Creating user", map[string]interface{}{
 		"email": user.Email,
 	})
@@ -30,24 +52,20 @@ func (r *userRepository) Create(user entity.User) (entity.User, error) {
 	query := `
 		INSERT INTO users (email, password, name)
 		VALUES ($1, $2, $3)
-		RETURNING id, email, name, telegram_chat_id, email_notify, telegram_notify, created_at, updated_at
+		RETURNING id, email, name, email_verified_at, deletion_scheduled_at, created_at, updated_at
 	`
 
-	var telegramChatID sql.NullString
-	err := r.db.QueryRow(query, user.Email, user.Password, user.Name).Scan(
-		&user.ID, &user.Email, &user.Name, &telegramChatID,
-		&user.EmailNotify, &user.TelegramNotify, &user.CreatedAt, &user.UpdatedAt,
+	var emailVerifiedAt, deletionScheduledAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, user.Email, user.Password, user.Name).Scan(
+		&user.ID, &user.Email, &user.Name, &emailVerifiedAt, &deletionScheduledAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 
-	if telegramChatID.Valid {
-		user.TelegramChatID = telegramChatID.String
-	} else {
-		user.TelegramChatID = ""
-	}
+	user.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
+	user.DeletionScheduledAt = nullTimePtr(deletionScheduledAt)
 
 	if err != nil {
-		utils.LogError("REPO_USER_CREATE", err)
-		return entity.User{}, err
+		utils.LogErrorContext(ctx, "REPO_USER_CREATE", err)
+		return entity.User{}, emailExists(err)
 	}
 
 	logger.Info("[REPO] User created successfully", map[string]interface{}{
@@ -56,30 +74,29 @@ func (r *userRepository) Create(user entity.User) (entity.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) FindByEmail(email string) (entity.User, error) {
-	logger := utils.GetLogger()
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (entity.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	logger := utils.FromContext(ctx)
 	logger.Info("[REPO] Finding user by email", map[string]interface{}{
 		"email": email,
 	})
 
 	query := `
-		SELECT id, email, password, name, telegram_chat_id, email_notify, telegram_notify, created_at, updated_at
+		SELECT id, email, password, name, email_verified_at, deletion_scheduled_at, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
 
 	var user entity.User
-	var telegramChatID sql.NullString
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID, &user.Email, &user.Password, &user.Name, &telegramChatID,
-		&user.EmailNotify, &user.TelegramNotify, &user.CreatedAt, &user.UpdatedAt,
+	var emailVerifiedAt, deletionScheduledAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID, &user.Email, &user.Password, &user.Name, &emailVerifiedAt, &deletionScheduledAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 
-	if telegramChatID.Valid {
-		user.TelegramChatID = telegramChatID.String
-	} else {
-		user.TelegramChatID = ""
-	}
+	user.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
+	user.DeletionScheduledAt = nullTimePtr(deletionScheduledAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -87,7 +104,7 @@ func (r *userRepository) FindByEmail(email string) (entity.User, error) {
 				"email": email,
 			})
 		} else {
-			utils.LogError("REPO_USER_FIND_EMAIL", err)
+			utils.LogErrorContext(ctx, "REPO_USER_FIND_EMAIL", err)
 		}
 		return entity.User{}, err
 	}
@@ -98,26 +115,25 @@ func (r *userRepository) FindByEmail(email string) (entity.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) FindByID(id uint) (entity.User, error) {
-	logger := utils.GetLogger()
+func (r *userRepository) FindByID(ctx context.Context, id uint) (entity.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	logger := utils.FromContext(ctx)
 	query := `
-		SELECT id, email, name, telegram_chat_id, email_notify, telegram_notify, created_at, updated_at
+		SELECT id, email, name, email_verified_at, deletion_scheduled_at, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
 
 	var user entity.User
-	var telegramChatID sql.NullString
-	err := r.db.QueryRow(query, id).Scan(
-		&user.ID, &user.Email, &user.Name, &telegramChatID,
-		&user.EmailNotify, &user.TelegramNotify, &user.CreatedAt, &user.UpdatedAt,
+	var emailVerifiedAt, deletionScheduledAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&user.ID, &user.Email, &user.Name, &emailVerifiedAt, &deletionScheduledAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 
-	if telegramChatID.Valid {
-		user.TelegramChatID = telegramChatID.String
-	} else {
-		user.TelegramChatID = ""
-	}
+	user.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
+	user.DeletionScheduledAt = nullTimePtr(deletionScheduledAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -125,7 +141,7 @@ func (r *userRepository) FindByID(id uint) (entity.User, error) {
 				"user_id": id,
 			})
 		} else {
-			utils.LogError("REPO_USER_FIND_ID", err)
+			utils.LogErrorContext(ctx, "REPO_USER_FIND_ID", err)
 		}
 		return entity.User{}, err
 	}
@@ -136,23 +152,128 @@ func (r *userRepository) FindByID(id uint) (entity.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) UpdateNotificationSettings(id uint, chatID string, emailNotify, telegramNotify bool) (entity.User, error) {
-	query := `
-		UPDATE users
-		SET telegram_chat_id = $1, email_notify = $2, telegram_notify = $3, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $4
-		RETURNING id, email, name, telegram_chat_id, email_notify, telegram_notify, created_at, updated_at
-	`
+func (r *userRepository) FindPasswordHash(ctx context.Context, id uint) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
-	var user entity.User
-	err := r.db.QueryRow(query, chatID, emailNotify, telegramNotify, id).Scan(
-		&user.ID, &user.Email, &user.Name, &user.TelegramChatID,
-		&user.EmailNotify, &user.TelegramNotify, &user.CreatedAt, &user.UpdatedAt,
-	)
+	var passwordHash string
+	err := r.db.QueryRowContext(ctx, `SELECT password FROM users WHERE id = $1`, id).Scan(&passwordHash)
+	return passwordHash, err
+}
 
+func (r *userRepository) UpdatePassword(ctx context.Context, id uint, passwordHash string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET password = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id, passwordHash)
+	if err != nil {
+		utils.LogErrorContext(ctx, "REPO_USER_UPDATE_PASSWORD", err)
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// MarkEmailVerified records that the user confirmed their email address. An
+// earlier verification time is kept.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id uint, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, $2), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, at)
 	if err != nil {
+		utils.LogErrorContext(ctx, "REPO_USER_VERIFY_EMAIL", err)
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+func (r *userRepository) UpdateName(ctx context.Context, id uint, name string) (entity.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `UPDATE users SET name = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, id, name); err != nil {
+		utils.LogErrorContext(ctx, "REPO_USER_UPDATE_NAME", err)
 		return entity.User{}, err
 	}
 
-	return user, nil
-}
\ No newline at end of file
+	return r.FindByID(ctx, id)
+}
+
+// UpdateEmail changes the user's address to one already confirmed at
+// verifiedAt.
+func (r *userRepository) UpdateEmail(ctx context.Context, id uint, email string, verifiedAt time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET email = $2, email_verified_at = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, email, verifiedAt)
+	if err != nil {
+		utils.LogErrorContext(ctx, "REPO_USER_UPDATE_EMAIL", err)
+		return emailExists(err)
+	}
+
+	return expectOneRow(result)
+}
+
+// ScheduleDeletion sets when the account is to be deleted; nil cancels a
+// scheduled deletion.
+func (r *userRepository) ScheduleDeletion(ctx context.Context, id uint, at *time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var deletionAt sql.NullTime
+	if at != nil {
+		deletionAt = sql.NullTime{Time: *at, Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET deletion_scheduled_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, deletionAt)
+	if err != nil {
+		utils.LogErrorContext(ctx, "REPO_USER_SCHEDULE_DELETION", err)
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// DeleteScheduled permanently deletes accounts whose deletion time is before
+// the given time. Their bills, payments, sessions and other data go with them
+// through ON DELETE CASCADE.
+func (r *userRepository) DeleteScheduled(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $1`, before)
+	if err != nil {
+		utils.LogErrorContext(ctx, "REPO_USER_DELETE_SCHEDULED", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// emailExists reports a unique violation, which for users can only come from
+// the email column, as ErrEmailExists and leaves other errors alone.
+func emailExists(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrEmailExists
+	}
+	return err
+}
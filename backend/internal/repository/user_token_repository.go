@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, token entity.UserToken) (entity.UserToken, error)
+	Consume(ctx context.Context, purpose, tokenHash string, at time.Time) (entity.UserToken, error)
+	InvalidateAll(ctx context.Context, userID uint, purpose string, at time.Time) error
+	FindCreatedSince(ctx context.Context, userID uint, purpose string, since time.Time) ([]entity.UserToken, error)
+	FindValid(ctx context.Context, purpose, tokenHash string, at time.Time) (entity.UserToken, error)
+	RecordFailedAttempt(ctx context.Context, id uint) (int, error)
+}
+
+const userTokenColumns = `id, user_id, purpose, token_hash, expires_at, used_at, attempts, payload, created_at`
+
+type userTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func scanUserToken(row rowScanner) (entity.UserToken, error) {
+	var token entity.UserToken
+	var usedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.ExpiresAt, &usedAt, &token.Attempts, &token.Payload, &token.CreatedAt)
+	if err != nil {
+		return entity.UserToken{}, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return token, nil
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, token entity.UserToken) (entity.UserToken, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + userTokenColumns
+
+	return scanUserToken(r.db.QueryRowContext(ctx, query, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt, token.Payload))
+}
+
+// Consume marks an unused, unexpired token as used and returns it. Unknown,
+// expired and already used tokens report sql.ErrNoRows.
+func (r *userTokenRepository) Consume(ctx context.Context, purpose, tokenHash string, at time.Time) (entity.UserToken, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE user_tokens SET used_at = $3
+		WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING ` + userTokenColumns
+
+	return scanUserToken(r.db.QueryRowContext(ctx, query, purpose, tokenHash, at))
+}
+
+// FindValid returns an unused, unexpired token without using it up. Unknown,
+// expired and already used tokens report sql.ErrNoRows.
+func (r *userTokenRepository) FindValid(ctx context.Context, purpose, tokenHash string, at time.Time) (entity.UserToken, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + userTokenColumns + `
+		FROM user_tokens
+		WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > $3
+	`
+
+	return scanUserToken(r.db.QueryRowContext(ctx, query, purpose, tokenHash, at))
+}
+
+// RecordFailedAttempt counts a wrong answer against a token and returns the
+// number of failed attempts so far.
+func (r *userTokenRepository) RecordFailedAttempt(ctx context.Context, id uint) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var attempts int
+	err := r.db.QueryRowContext(ctx, `UPDATE user_tokens SET attempts = attempts + 1 WHERE id = $1 RETURNING attempts`, id).Scan(&attempts)
+	return attempts, err
+}
+
+// InvalidateAll marks every outstanding token of the purpose as used.
+func (r *userTokenRepository) InvalidateAll(ctx context.Context, userID uint, purpose string, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_tokens SET used_at = $3
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, userID, purpose, at)
+	return err
+}
+
+// FindCreatedSince lists the tokens of the purpose issued to the user at or
+// after since, newest first.
+func (r *userTokenRepository) FindCreatedSince(ctx context.Context, userID uint, purpose string, since time.Time) ([]entity.UserToken, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + userTokenColumns + `
+		FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2 AND created_at >= $3
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, purpose, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []entity.UserToken
+	for rows.Next() {
+		token, err := scanUserToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery entity.WebhookDelivery) (entity.WebhookDelivery, error)
+	FindByID(ctx context.Context, id, userID uint) (entity.WebhookDelivery, error)
+	Claim(ctx context.Context, now, lockedUntil time.Time) (entity.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id uint, responseStatus int, responseBody string, deliveredAt time.Time) error
+	RecordFailure(ctx context.Context, id uint, responseStatus *int, responseBody, lastError string, retryAt *time.Time) error
+	Release(ctx context.Context, id uint) error
+	FailPending(ctx context.Context, webhookID uint, reason string) (int64, error)
+	FindPage(ctx context.Context, filter WebhookDeliveryFilter) ([]entity.WebhookDelivery, int64, error)
+}
+
+// WebhookDeliveryFilter selects a page of one webhook's deliveries, newest
+// first. An empty Status matches every status.
+type WebhookDeliveryFilter struct {
+	WebhookID uint
+	UserID    uint
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+const webhookDeliveryColumns = `id, webhook_id, user_id, event, payload, status, attempts, max_attempts, next_attempt_at,
+	locked_until, response_status, response_body, last_error, delivered_at, created_at, updated_at`
+
+type webhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+func scanWebhookDelivery(row rowScanner) (entity.WebhookDelivery, error) {
+	var d entity.WebhookDelivery
+	var lockedUntil, deliveredAt sql.NullTime
+	var responseStatus sql.NullInt64
+	var payload []byte
+	err := row.Scan(
+		&d.ID, &d.WebhookID, &d.UserID, &d.Event, &payload, &d.Status, &d.Attempts, &d.MaxAttempts, &d.NextAttemptAt,
+		&lockedUntil, &responseStatus, &d.ResponseBody, &d.LastError, &deliveredAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	d.Payload = payload
+	d.LockedUntil = nullTimePtr(lockedUntil)
+	d.DeliveredAt = nullTimePtr(deliveredAt)
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		d.ResponseStatus = &status
+	}
+	return d, nil
+}
+
+// Create queues a delivery. Deliveries created in the sending state with
+// LockedUntil set are held by the caller, which sends them itself.
+func (r *webhookDeliveryRepository) Create(ctx context.Context, delivery entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	status := delivery.Status
+	if status == "" {
+		status = entity.WebhookDeliveryPending
+	}
+
+	return scanWebhookDelivery(r.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, user_id, event, payload, status, max_attempts, next_attempt_at, locked_until)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+webhookDeliveryColumns,
+		delivery.WebhookID, delivery.UserID, delivery.Event, []byte(delivery.Payload), status, delivery.MaxAttempts,
+		delivery.NextAttemptAt, delivery.LockedUntil))
+}
+
+func (r *webhookDeliveryRepository) FindByID(ctx context.Context, id, userID uint) (entity.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanWebhookDelivery(r.db.QueryRowContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE id = $1 AND user_id = $2
+	`, id, userID))
+}
+
+// Claim takes the next delivery due at now and holds it for the caller until
+// lockedUntil. Deliveries whose previous holder let its hold expire are
+// claimed again. It reports sql.ErrNoRows when none is due.
+func (r *webhookDeliveryRepository) Claim(ctx context.Context, now, lockedUntil time.Time) (entity.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanWebhookDelivery(r.db.QueryRowContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'sending', locked_until = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM webhook_deliveries
+			WHERE (status = 'pending' AND next_attempt_at <= $1)
+				OR (status = 'sending' AND locked_until <= $1)
+			ORDER BY next_attempt_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns, now, lockedUntil))
+}
+
+func (r *webhookDeliveryRepository) MarkDelivered(ctx context.Context, id uint, responseStatus int, responseBody string, deliveredAt time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, response_status = $2, response_body = $3, last_error = '',
+			delivered_at = $4, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, responseStatus, responseBody, deliveredAt)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// RecordFailure counts a failed attempt. The delivery is retried at retryAt,
+// or marked failed for good when retryAt is nil. responseStatus is nil when
+// the endpoint did not answer.
+func (r *webhookDeliveryRepository) RecordFailure(ctx context.Context, id uint, responseStatus *int, responseBody, lastError string, retryAt *time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	status := entity.WebhookDeliveryFailed
+	var nextAttemptAt sql.NullTime
+	if retryAt != nil {
+		status = entity.WebhookDeliveryPending
+		nextAttemptAt = sql.NullTime{Time: *retryAt, Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, response_status = $3, response_body = $4, last_error = $5,
+			next_attempt_at = COALESCE($6, next_attempt_at), locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, status, responseStatus, responseBody, lastError, nextAttemptAt)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// Release returns a claimed delivery to the queue without counting an
+// attempt, for deliveries interrupted by shutdown.
+func (r *webhookDeliveryRepository) Release(ctx context.Context, id uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries SET status = 'pending', locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'sending'
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// FailPending gives up on every delivery still waiting for the webhook,
+// recording reason as their error.
+func (r *webhookDeliveryRepository) FailPending(ctx context.Context, webhookID uint, reason string) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'failed', last_error = $2, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE webhook_id = $1 AND status = 'pending'
+	`, webhookID, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *webhookDeliveryRepository) FindPage(ctx context.Context, filter WebhookDeliveryFilter) ([]entity.WebhookDelivery, int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var b queryBuilder
+	b.where("webhook_id = ?", filter.WebhookID)
+	b.where("user_id = ?", filter.UserID)
+	if filter.Status != "" {
+		b.where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries `+b.whereClause(), b.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		`+b.whereClause()+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT `+b.arg(filter.Limit)+` OFFSET `+b.arg(filter.Offset), b.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, total, rows.Err()
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"financebroke/backend/internal/entity"
+	"time"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error)
+	FindByID(ctx context.Context, id, userID uint) (entity.Webhook, error)
+	FindByUser(ctx context.Context, userID uint) ([]entity.Webhook, error)
+	FindSubscribed(ctx context.Context, userID uint, event string) ([]entity.Webhook, error)
+	CountByUser(ctx context.Context, userID uint) (int64, error)
+	Update(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error)
+	Delete(ctx context.Context, id, userID uint) error
+	RecordSuccess(ctx context.Context, id uint) error
+	RecordFailure(ctx context.Context, id uint, disableAfter int, at time.Time) (entity.Webhook, error)
+}
+
+const webhookColumns = `id, user_id, url, secret, to_json(events), description, enabled, consecutive_failures, disabled_at,
+	created_at, updated_at`
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func scanWebhook(row rowScanner) (entity.Webhook, error) {
+	var w entity.Webhook
+	var events []byte
+	var disabledAt sql.NullTime
+	err := row.Scan(
+		&w.ID, &w.UserID, &w.URL, &w.Secret, &events, &w.Description, &w.Enabled, &w.ConsecutiveFailures, &disabledAt,
+		&w.CreatedAt, &w.UpdatedAt,
+	)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	if err := json.Unmarshal(events, &w.Events); err != nil {
+		return entity.Webhook{}, err
+	}
+	w.DisabledAt = nullTimePtr(disabledAt)
+	return w, nil
+}
+
+func (r *webhookRepository) queryWebhooks(ctx context.Context, query string, args ...interface{}) ([]entity.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []entity.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanWebhook(r.db.QueryRowContext(ctx, `
+		INSERT INTO webhooks (user_id, url, secret, events, description, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+webhookColumns,
+		webhook.UserID, webhook.URL, webhook.Secret, webhook.Events, webhook.Description, webhook.Enabled))
+}
+
+func (r *webhookRepository) FindByID(ctx context.Context, id, userID uint) (entity.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanWebhook(r.db.QueryRowContext(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE id = $1 AND user_id = $2
+	`, id, userID))
+}
+
+func (r *webhookRepository) FindByUser(ctx context.Context, userID uint) ([]entity.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.queryWebhooks(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, userID)
+}
+
+// FindSubscribed returns the user's enabled webhooks that subscribe to event.
+func (r *webhookRepository) FindSubscribed(ctx context.Context, userID uint, event string) ([]entity.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return r.queryWebhooks(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE user_id = $1 AND enabled AND $2 = ANY(events)
+		ORDER BY id
+	`, userID, event)
+}
+
+func (r *webhookRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhooks WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+// Update saves the webhook's settings and failure state. The secret cannot be
+// changed.
+func (r *webhookRepository) Update(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanWebhook(r.db.QueryRowContext(ctx, `
+		UPDATE webhooks
+		SET url = $3, events = $4, description = $5, enabled = $6, consecutive_failures = $7, disabled_at = $8,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+webhookColumns,
+		webhook.ID, webhook.UserID, webhook.URL, webhook.Events, webhook.Description, webhook.Enabled,
+		webhook.ConsecutiveFailures, webhook.DisabledAt))
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id, userID uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result)
+}
+
+// RecordSuccess clears the webhook's run of failed deliveries.
+func (r *webhookRepository) RecordSuccess(ctx context.Context, id uint) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhooks SET consecutive_failures = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND consecutive_failures > 0
+	`, id)
+	return err
+}
+
+// RecordFailure counts a failed delivery and disables the webhook, as of at,
+// once disableAfter deliveries in a row have failed. It returns the webhook as
+// updated.
+func (r *webhookRepository) RecordFailure(ctx context.Context, id uint, disableAfter int, at time.Time) (entity.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanWebhook(r.db.QueryRowContext(ctx, `
+		UPDATE webhooks
+		SET consecutive_failures = consecutive_failures + 1,
+			enabled = enabled AND consecutive_failures + 1 < $2,
+			disabled_at = CASE WHEN enabled AND consecutive_failures + 1 >= $2 THEN $3 ELSE disabled_at END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING `+webhookColumns, id, disableAfter, at))
+}
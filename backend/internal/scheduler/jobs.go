@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"financebroke/backend/internal/usecase"
+)
+
+// NewReminderJob sends reminders for bills whose reminder window has opened.
+func NewReminderJob(notificationUsecase usecase.NotificationUsecase) JobFunc {
+	return func(ctx context.Context, now time.Time) error {
+		_, err := notificationUsecase.SendDueReminders(ctx, now)
+		return err
+	}
+}
+
+// NewOverdueJob moves unpaid bills past their due date to overdue and notifies
+// the owners of any overdue bill that has not been announced yet.
+func NewOverdueJob(billUsecase usecase.BillUsecase, notificationUsecase usecase.NotificationUsecase) JobFunc {
+	return func(ctx context.Context, now time.Time) error {
+		if _, err := billUsecase.MarkOverdueBills(ctx, now); err != nil {
+			return err
+		}
+
+		_, err := notificationUsecase.SendOverdueNotices(ctx, now)
+		return err
+	}
+}
+
+// NewRecurrenceJob generates the next occurrence of recurring bills whose
+// latest occurrence is already past due.
+func NewRecurrenceJob(recurrenceUsecase usecase.RecurrenceUsecase) JobFunc {
+	return func(ctx context.Context, now time.Time) error {
+		_, err := recurrenceUsecase.GenerateDueOccurrences(ctx, now)
+		return err
+	}
+}
+
+// NewBudgetAlertJob warns users whose category budgets for the current month
+// have crossed an alert threshold.
+func NewBudgetAlertJob(notificationUsecase usecase.NotificationUsecase) JobFunc {
+	return func(ctx context.Context, now time.Time) error {
+		_, err := notificationUsecase.SendBudgetAlerts(ctx, now)
+		return err
+	}
+}
+
+// NewAccountDeletionJob permanently removes accounts whose deletion grace
+// period has ended.
+func NewAccountDeletionJob(authUsecase usecase.AuthUsecase) JobFunc {
+	return func(ctx context.Context, now time.Time) error {
+		_, err := authUsecase.PurgeDeletedAccounts(ctx, now)
+		return err
+	}
+}
+
+// NewNotificationDeliveryJob sends queued notifications that are due,
+// including retries of earlier failures.
+func NewNotificationDeliveryJob(notificationUsecase usecase.NotificationUsecase) JobFunc {
+	return func(ctx context.Context, now time.Time) error {
+		_, err := notificationUsecase.DeliverPending(ctx, now)
+		return err
+	}
+}
+
+// NewWebhookDeliveryJob posts queued webhook events that are due, including
+// retries of earlier failures.
+func NewWebhookDeliveryJob(webhookUsecase usecase.WebhookUsecase) JobFunc {
+	return func(ctx context.Context, now time.Time) error {
+		_, err := webhookUsecase.DeliverPending(ctx, now)
+		return err
+	}
+}
+
+// NewTelegramUpdatesJob long-polls the Telegram bot for commands and button
+// presses and answers them.
+func NewTelegramUpdatesJob(botUsecase usecase.TelegramBotUsecase) JobFunc {
+	return func(ctx context.Context, now time.Time) error {
+		_, err := botUsecase.PollUpdates(ctx, now)
+		return err
+	}
+}
@@ -0,0 +1,159 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"financebroke/backend/internal/utils"
+)
+
+// Clock supplies the current time to jobs so runs can be driven
+// deterministically instead of relying on the wall clock.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock returns a Clock backed by time.Now.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// JobFunc is a unit of periodic work. It receives the time of the current run
+// and a context that is cancelled when the scheduler stops.
+type JobFunc func(ctx context.Context, now time.Time) error
+
+type job struct {
+	name string
+	fn   JobFunc
+}
+
+// Scheduler runs registered jobs in-process, once at start and then on every
+// interval. Jobs run sequentially so a slow job never overlaps with itself.
+type Scheduler struct {
+	interval time.Duration
+	clock    Clock
+	jobs     []job
+
+	mu      sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func New(interval time.Duration, clock Clock) *Scheduler {
+	if clock == nil {
+		clock = SystemClock()
+	}
+
+	return &Scheduler{
+		interval: interval,
+		clock:    clock,
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start is called.
+func (s *Scheduler) Register(name string, fn JobFunc) {
+	s.jobs = append(s.jobs, job{name: name, fn: fn})
+}
+
+// RunOnce executes every registered job a single time using the scheduler's
+// clock. A failing job is logged and does not prevent the others from running.
+// Jobs not yet started when ctx is cancelled are skipped.
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	now := s.clock.Now()
+	for _, j := range s.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		s.runJob(ctx, j, now)
+	}
+}
+
+func (s *Scheduler) runJob(ctx context.Context, j job, now time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.LogError("SCHEDULER_JOB_PANIC", fmt.Errorf("job %s panicked: %v", j.name, r))
+		}
+	}()
+
+	start := time.Now()
+	if err := j.fn(ctx, now); err != nil {
+		if ctx.Err() != nil {
+			utils.GetLogger().Info("[SCHEDULER] Job cancelled", map[string]interface{}{
+				"job": j.name,
+			})
+			return
+		}
+		utils.LogError("SCHEDULER_JOB_"+j.name, err)
+		return
+	}
+
+	utils.GetLogger().Debug("[SCHEDULER] Job finished", map[string]interface{}{
+		"job":      j.name,
+		"duration": time.Since(start).String(),
+	})
+}
+
+// Start launches the scheduler loop in a background goroutine.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.running = true
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	utils.GetLogger().Info("[SCHEDULER] Starting", map[string]interface{}{
+		"interval": s.interval.String(),
+		"jobs":     len(s.jobs),
+	})
+
+	go s.loop(ctx, s.done)
+}
+
+func (s *Scheduler) loop(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.RunOnce(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop cancels the context of the running jobs, so in-flight queries are
+// abandoned, and waits for the loop to exit.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.cancel()
+	done := s.done
+	s.mu.Unlock()
+
+	<-done
+	utils.GetLogger().Info("[SCHEDULER] Stopped")
+}
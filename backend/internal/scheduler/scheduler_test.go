@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"financebroke/backend/internal/usecase"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		jobs    []JobFunc
+		cancel  bool
+		wantRan []int
+	}{
+		{
+			name:    "runs every job in order",
+			jobs:    []JobFunc{nil, nil, nil},
+			wantRan: []int{0, 1, 2},
+		},
+		{
+			name: "a failing job does not stop the others",
+			jobs: []JobFunc{
+				func(context.Context, time.Time) error { return errors.New("boom") },
+				nil,
+			},
+			wantRan: []int{1},
+		},
+		{
+			name: "a panicking job does not stop the others",
+			jobs: []JobFunc{
+				func(context.Context, time.Time) error { panic("boom") },
+				nil,
+			},
+			wantRan: []int{1},
+		},
+		{
+			name:    "jobs are skipped once the context is cancelled",
+			jobs:    []JobFunc{nil, nil},
+			cancel:  true,
+			wantRan: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(time.Minute, fixedClock(now))
+
+			var ran []int
+			for i, fn := range tt.jobs {
+				i, fn := i, fn
+				if fn == nil {
+					fn = func(_ context.Context, got time.Time) error {
+						if !got.Equal(now) {
+							t.Errorf("job %d got time %v, want the clock's %v", i, got, now)
+						}
+						ran = append(ran, i)
+						return nil
+					}
+				}
+				s.Register("job", fn)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			s.RunOnce(ctx)
+
+			if !reflect.DeepEqual(ran, tt.wantRan) {
+				t.Errorf("ran jobs %v, want %v", ran, tt.wantRan)
+			}
+		})
+	}
+}
+
+func TestStartRunsImmediatelyAndStopWaits(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)
+	s := New(time.Hour, fixedClock(now))
+
+	ran := make(chan time.Time, 1)
+	stopped := make(chan struct{})
+	s.Register("job", func(ctx context.Context, at time.Time) error {
+		ran <- at
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+
+	s.Start()
+	select {
+	case at := <-ran:
+		if !at.Equal(now) {
+			t.Errorf("job got time %v, want %v", at, now)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not run at start")
+	}
+
+	s.Stop()
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("Stop returned before the running job finished")
+	}
+}
+
+type reminderNotifications struct {
+	usecase.NotificationUsecase
+	calls []time.Time
+	err   error
+}
+
+func (n *reminderNotifications) SendDueReminders(_ context.Context, now time.Time) (int, error) {
+	n.calls = append(n.calls, now)
+	return len(n.calls), n.err
+}
+
+func TestReminderJobUsesSchedulerClock(t *testing.T) {
+	now := time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC)
+	notifications := &reminderNotifications{}
+
+	s := New(time.Minute, fixedClock(now))
+	s.Register("bill_reminders", NewReminderJob(notifications))
+	s.RunOnce(context.Background())
+	s.RunOnce(context.Background())
+
+	want := []time.Time{now, now}
+	if !reflect.DeepEqual(notifications.calls, want) {
+		t.Errorf("SendDueReminders called with %v, want %v", notifications.calls, want)
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"financebroke/backend/internal/entity"
+)
+
+// AddressValidator is implemented by notifiers whose addresses must have a
+// particular form. Addresses are checked before users save them.
+type AddressValidator interface {
+	ValidateAddress(address string) error
+}
+
+// Embed colours of each kind of notification.
+const (
+	colorReminder = 0x3B82F6
+	colorWarning  = 0xF59E0B
+	colorDanger   = 0xEF4444
+	colorNeutral  = 0x6B7280
+)
+
+// chatCard is a notification laid out for chat services that show rich
+// messages: a title linking to the app, a line of text and labelled fields.
+type chatCard struct {
+	Title  string
+	Text   string
+	Fields []chatField
+	URL    string
+	Color  int
+}
+
+type chatField struct {
+	Name  string
+	Value string
+}
+
+func newChatCard(n entity.Notification, appURL string) (chatCard, error) {
+	card := chatCard{URL: appURL + "/bills"}
+	switch n.Kind {
+	case entity.NotificationBillReminder:
+		card.Title = "💰 Bill Reminder: " + n.Bill.Name
+		card.Text = "Don't forget to pay your bill!"
+		card.Fields = billFields(n.Bill)
+		card.Color = colorReminder
+	case entity.NotificationBillOverdue:
+		card.Title = "⚠️ Bill Overdue: " + n.Bill.Name
+		card.Text = "This bill is past its due date. Please pay it as soon as possible."
+		card.Fields = billFields(n.Bill)
+		card.Color = colorDanger
+	case entity.NotificationBudgetAlert:
+		usage := n.Budget
+		card.Title = "📊 Budget Warning: " + usage.CategoryName
+		card.Color = colorWarning
+		if n.Threshold >= entity.BudgetThresholdExceeded {
+			card.Title = "🚨 Budget Exceeded: " + usage.CategoryName
+			card.Color = colorDanger
+		}
+		card.Text = fmt.Sprintf("%s is at %.0f%% of its budget for %s.",
+			usage.CategoryName, usage.PercentUsed(), usage.PeriodStart.Format("January 2006"))
+		card.Fields = []chatField{
+			{Name: "Spent", Value: "Rp" + usage.Spent.String()},
+			{Name: "Budget", Value: "Rp" + usage.Amount.String()},
+			{Name: "Remaining", Value: "Rp" + usage.Remaining().String()},
+		}
+		card.URL = appURL + "/dashboard"
+	case entity.NotificationTest:
+		card.Title = "Test notification"
+		card.Text = n.Text
+		card.Color = colorNeutral
+		card.URL = appURL + "/dashboard"
+	default:
+		return chatCard{}, fmt.Errorf("unsupported notification kind %q", n.Kind)
+	}
+	return card, nil
+}
+
+func billFields(bill *entity.Bill) []chatField {
+	return []chatField{
+		{Name: "Bill", Value: bill.Name},
+		{Name: "Amount", Value: "Rp" + bill.Amount.String()},
+		{Name: "Due Date", Value: bill.DueDate.Format("2006-01-02")},
+		{Name: "Status", Value: bill.Status},
+	}
+}
+
+// validateChatWebhookURL checks that address is an https URL on one of hosts,
+// so users cannot make the server post anywhere else or in the clear.
+func validateChatWebhookURL(address string, hosts []string) error {
+	u, err := neturl.Parse(address)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return errors.New("address must be an https incoming webhook URL")
+	}
+	for _, host := range hosts {
+		if strings.EqualFold(u.Host, host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook URL must be on %s", strings.Join(hosts, " or "))
+}
+
+// chatClient does not follow redirects, which could lead away from the
+// allowed hosts.
+var chatClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// postChatWebhook posts payload as JSON to an incoming webhook and returns the
+// response body, or the status when the body is empty.
+func postChatWebhook(ctx context.Context, service, webhookURL string, payload interface{}) (string, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := chatClient.Do(req)
+	if err != nil {
+		// The URL is the user's webhook secret, so it is left out of the error
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("%s webhook request failed: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	response := string(body)
+	if response == "" {
+		response = resp.Status
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return response, fmt.Errorf("%s webhook error: %s", service, response)
+	}
+	return response, nil
+}
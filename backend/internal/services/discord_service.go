@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/entity"
+)
+
+// DiscordService posts notifications to Discord webhooks. Each user saves the
+// webhook URL of their own server's channel as the channel address.
+type DiscordService struct {
+	enabled bool
+	hosts   []string
+	appURL  string
+}
+
+func NewDiscordService(cfg config.ChatConfig, appURL string) *DiscordService {
+	return &DiscordService{enabled: cfg.Enabled, hosts: cfg.Hosts, appURL: strings.TrimRight(appURL, "/")}
+}
+
+func (d *DiscordService) Channel() string {
+	return "discord"
+}
+
+func (d *DiscordService) IsConfigured() bool {
+	return d.enabled
+}
+
+// Recipient returns the webhook URL the user saved in their settings.
+func (d *DiscordService) Recipient(user entity.User, pref entity.NotificationPreference) (string, bool) {
+	return pref.Address, pref.Address != ""
+}
+
+func (d *DiscordService) ValidateAddress(address string) error {
+	return validateChatWebhookURL(address, d.hosts)
+}
+
+// Send posts n as an embed.
+func (d *DiscordService) Send(ctx context.Context, webhookURL string, n entity.Notification) (string, error) {
+	if err := d.ValidateAddress(webhookURL); err != nil {
+		return "", err
+	}
+
+	card, err := newChatCard(n, d.appURL)
+	if err != nil {
+		return "", err
+	}
+
+	return postChatWebhook(ctx, "discord", webhookURL, discordMessage(card, time.Now()))
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	URL         string         `json:"url"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+type discordPayload struct {
+	Username        string                 `json:"username"`
+	Embeds          []discordEmbed         `json:"embeds"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+func discordMessage(card chatCard, now time.Time) discordPayload {
+	fields := make([]discordField, len(card.Fields))
+	for i, field := range card.Fields {
+		fields[i] = discordField{Name: field.Name, Value: field.Value, Inline: true}
+	}
+
+	return discordPayload{
+		Username: "FinanceBroke",
+		Embeds: []discordEmbed{{
+			Title:       card.Title,
+			Description: card.Text,
+			URL:         card.URL,
+			Color:       card.Color,
+			Fields:      fields,
+			Timestamp:   now.UTC().Format(time.RFC3339),
+		}},
+		// Bill names must not be able to ping @everyone
+		AllowedMentions: discordAllowedMentions{Parse: []string{}},
+	}
+}
@@ -1,8 +1,14 @@
 package services
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"mime"
+	"net"
 	"net/smtp"
+	"time"
+	"financebroke/backend/internal/config"
 	"financebroke/backend/internal/entity"
 )
 
@@ -14,27 +20,61 @@ type EmailService struct {
 	fromEmail    string
 }
 
-func NewEmailService(host, port, username, password, from string) *EmailService {
+func NewEmailService(cfg config.SMTPConfig) *EmailService {
 	return &EmailService{
-		smtpHost:     host,
-		smtpPort:     port,
-		smtpUsername: username,
-		smtpPassword: password,
-		fromEmail:    from,
+		smtpHost:     cfg.Host,
+		smtpPort:     cfg.Port,
+		smtpUsername: cfg.Username,
+		smtpPassword: cfg.Password,
+		fromEmail:    cfg.From,
 	}
 }
 
-func (e *EmailService) SendReminder(bill *entity.Bill, user *entity.User) error {
-	if !user.EmailNotify {
-		return fmt.Errorf("email notification disabled")
+// IsConfigured reports whether an SMTP server has been set up.
+func (e *EmailService) IsConfigured() bool {
+	return e.smtpHost != "" && e.fromEmail != ""
+}
+
+func (e *EmailService) Channel() string {
+	return "email"
+}
+
+// Recipient returns the account's email address. Unverified addresses never
+// receive notifications.
+func (e *EmailService) Recipient(user entity.User, pref entity.NotificationPreference) (string, bool) {
+	return user.Email, user.EmailVerified()
+}
+
+// Send emails n. The SMTP client does not expose the server's reply, so the
+// response only records which server accepted the message.
+func (e *EmailService) Send(ctx context.Context, to string, n entity.Notification) (string, error) {
+	var subject, body string
+	switch n.Kind {
+	case entity.NotificationBillReminder:
+		subject, body = reminderEmail(n.Bill, &n.User)
+	case entity.NotificationBillOverdue:
+		subject, body = overdueEmail(n.Bill, &n.User)
+	case entity.NotificationBudgetAlert:
+		subject, body = budgetAlertEmail(n.Budget, n.Threshold, &n.User)
+	case entity.NotificationTest:
+		subject, body = "Test notification", n.Text
+	default:
+		return "", fmt.Errorf("unsupported notification kind %q", n.Kind)
 	}
 
+	if err := e.send(ctx, to, subject, body); err != nil {
+		return "", err
+	}
+	return "accepted by " + e.smtpHost, nil
+}
+
+func reminderEmail(bill *entity.Bill, user *entity.User) (string, string) {
 	subject := fmt.Sprintf("Bill Reminder: %s", bill.Name)
 	body := fmt.Sprintf(
 		"Hi %s,\n\n"+
 			"This is a reminder for your upcoming bill:\n\n"+
 			"Bill Name: %s\n"+
-			"Amount: Rp%.2f\n"+
+			"Amount: Rp%s\n"+
 			"Due Date: %s\n\n"+
 			"Please make sure to pay on time to avoid late fees.\n\n"+
 			"Best regards,\n"+
@@ -45,16 +85,201 @@ func (e *EmailService) SendReminder(bill *entity.Bill, user *entity.User) error
 		bill.DueDate.Format("2006-01-02"),
 	)
 
+	return subject, body
+}
+
+func overdueEmail(bill *entity.Bill, user *entity.User) (string, string) {
+	subject := fmt.Sprintf("Bill Overdue: %s", bill.Name)
+	body := fmt.Sprintf(
+		"Hi %s,\n\n"+
+			"The following bill is now overdue:\n\n"+
+			"Bill Name: %s\n"+
+			"Amount: Rp%s\n"+
+			"Was Due: %s\n\n"+
+			"Please pay it as soon as possible to avoid further late fees.\n\n"+
+			"Best regards,\n"+
+			"Finance App Team",
+		user.Name,
+		bill.Name,
+		bill.Amount,
+		bill.DueDate.Format("2006-01-02"),
+	)
+
+	return subject, body
+}
+
+func budgetAlertEmail(usage *entity.BudgetUsage, threshold int, user *entity.User) (string, string) {
+	subject := fmt.Sprintf("Budget Warning: %s", usage.CategoryName)
+	if threshold >= entity.BudgetThresholdExceeded {
+		subject = fmt.Sprintf("Budget Exceeded: %s", usage.CategoryName)
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\n"+
+			"Your %s bills for %s have reached %.0f%% of the budget:\n\n"+
+			"Spent: Rp%s\n"+
+			"Budget: Rp%s\n"+
+			"Remaining: Rp%s\n\n"+
+			"Best regards,\n"+
+			"Finance App Team",
+		user.Name,
+		usage.CategoryName,
+		usage.PeriodStart.Format("January 2006"),
+		usage.PercentUsed(),
+		usage.Spent,
+		usage.Amount,
+		usage.Remaining(),
+	)
+
+	return subject, body
+}
+
+// SendPasswordReset emails a password reset link. It is sent regardless of
+// the user's notification settings.
+func (e *EmailService) SendPasswordReset(ctx context.Context, user *entity.User, link string, validFor time.Duration) error {
+	subject := "Reset your password"
+	body := fmt.Sprintf(
+		"Hi %s,\n\n"+
+			"We received a request to reset the password for your account. "+
+			"Open the link below to choose a new password:\n\n"+
+			"%s\n\n"+
+			"The link can be used once and expires in %s. "+
+			"If you did not ask for a reset, you can ignore this email; your password will not change.\n\n"+
+			"Best regards,\n"+
+			"Finance App Team",
+		user.Name,
+		link,
+		formatValidity(validFor),
+	)
+
+	return e.send(ctx, user.Email, subject, body)
+}
+
+// SendEmailVerification emails a link that confirms the user owns the
+// address. It is sent regardless of the user's notification settings.
+func (e *EmailService) SendEmailVerification(ctx context.Context, user *entity.User, link string, validFor time.Duration) error {
+	subject := "Confirm your email address"
+	body := fmt.Sprintf(
+		"Hi %s,\n\n"+
+			"Please confirm that this is your email address by opening the link below:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s. Until the address is confirmed, bill reminders will not be sent to it.\n\n"+
+			"Best regards,\n"+
+			"Finance App Team",
+		user.Name,
+		link,
+		formatValidity(validFor),
+	)
+
+	return e.send(ctx, user.Email, subject, body)
+}
+
+// SendEmailChange emails a confirmation link to the address the user wants to
+// switch to.
+func (e *EmailService) SendEmailChange(ctx context.Context, user *entity.User, newEmail, link string, validFor time.Duration) error {
+	subject := "Confirm your new email address"
+	body := fmt.Sprintf(
+		"Hi %s,\n\n"+
+			"You asked to change the email address of your account to this one. "+
+			"Open the link below to confirm:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s. Until then your account keeps using %s.\n\n"+
+			"Best regards,\n"+
+			"Finance App Team",
+		user.Name,
+		link,
+		formatValidity(validFor),
+		user.Email,
+	)
+
+	return e.send(ctx, newEmail, subject, body)
+}
+
+// formatValidity renders a duration such as 1h0m0s as "1 hour" for email text.
+func formatValidity(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
+}
+
+// defaultSendTimeout bounds a send whose context has no deadline of its own.
+const defaultSendTimeout = 30 * time.Second
+
+// send delivers one message. The whole SMTP conversation, from dialing to
+// QUIT, is bounded by ctx, so a stalled server cannot hold up the caller.
+func (e *EmailService) send(ctx context.Context, to, subject, body string) error {
+	// Subjects carry user-supplied names; encoding them keeps line breaks
+	// from starting new headers
 	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
-		e.fromEmail, user.Email, subject, body)
+		e.fromEmail, to, mime.QEncoding.Encode("utf-8", subject), body)
 
-	auth := smtp.PlainAuth("", e.smtpUsername, e.smtpPassword, e.smtpHost)
-	addr := fmt.Sprintf("%s:%s", e.smtpHost, e.smtpPort)
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultSendTimeout)
+		defer cancel()
+	}
 
-	err := smtp.SendMail(addr, auth, e.fromEmail, []string{user.Email}, []byte(msg))
-	if err != nil {
+	if err := e.sendMail(ctx, to, []byte(msg)); err != nil {
 		return fmt.Errorf("failed to send email: %v", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// sendMail is smtp.SendMail over a connection that honours ctx.
+func (e *EmailService) sendMail(ctx context.Context, to string, msg []byte) error {
+	addr := net.JoinHostPort(e.smtpHost, e.smtpPort)
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	// Cancelling ctx before the deadline aborts the conversation too
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.smtpHost)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.smtpHost}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); ok {
+		if err := c.Auth(smtp.PlainAuth("", e.smtpUsername, e.smtpPassword, e.smtpHost)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(e.fromEmail); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
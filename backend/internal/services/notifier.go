@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+
+	"financebroke/backend/internal/entity"
+)
+
+// Notifier delivers notifications over one channel, such as email, Telegram
+// or Slack. New channels are added by implementing it and registering the
+// implementation with a NotifierRegistry.
+type Notifier interface {
+	// Channel is the name users refer to the channel by in their settings.
+	Channel() string
+	// IsConfigured reports whether the server has what the channel needs to
+	// send anything, such as credentials.
+	IsConfigured() bool
+	// Recipient returns where the user is reached on this channel given
+	// their preference, and false when they cannot be reached.
+	Recipient(user entity.User, pref entity.NotificationPreference) (string, bool)
+	// Send delivers n to the recipient and returns what the provider
+	// answered, for the delivery log. The response is returned on failure
+	// too when there is one.
+	Send(ctx context.Context, to string, n entity.Notification) (string, error)
+}
+
+var (
+	_ Notifier = (*EmailService)(nil)
+	_ Notifier = (*TelegramService)(nil)
+	_ Notifier = (*SlackService)(nil)
+	_ Notifier = (*DiscordService)(nil)
+
+	_ AddressValidator = (*SlackService)(nil)
+	_ AddressValidator = (*DiscordService)(nil)
+	_ AddressLinker    = (*TelegramService)(nil)
+)
+
+// AddressLinker is implemented by notifiers whose address also identifies the
+// user to the server, such as a Telegram chat the bot takes commands from.
+// Those addresses are only set by linking, never from the settings.
+type AddressLinker interface {
+	LinksAddress() bool
+}
+
+// NotifierRegistry holds the available notification channels in the order
+// they were registered.
+type NotifierRegistry struct {
+	notifiers []Notifier
+	defaults  map[string]bool
+}
+
+func NewNotifierRegistry() *NotifierRegistry {
+	return &NotifierRegistry{defaults: make(map[string]bool)}
+}
+
+// Register adds a channel. enabledByDefault applies to users who have not
+// saved a preference for it.
+func (r *NotifierRegistry) Register(notifier Notifier, enabledByDefault bool) {
+	r.notifiers = append(r.notifiers, notifier)
+	r.defaults[notifier.Channel()] = enabledByDefault
+}
+
+func (r *NotifierRegistry) Get(channel string) (Notifier, bool) {
+	for _, notifier := range r.notifiers {
+		if notifier.Channel() == channel {
+			return notifier, true
+		}
+	}
+	return nil, false
+}
+
+// Notifiers returns every registered channel.
+func (r *NotifierRegistry) Notifiers() []Notifier {
+	return r.notifiers
+}
+
+// DefaultPreference is the preference of a user who has not configured the
+// channel.
+func (r *NotifierRegistry) DefaultPreference(channel string) entity.NotificationPreference {
+	return entity.NotificationPreference{Channel: channel, Enabled: r.defaults[channel]}
+}
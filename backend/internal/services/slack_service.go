@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/entity"
+)
+
+// SlackService posts notifications to Slack incoming webhooks. Each user saves
+// the webhook URL of their own workspace as the channel address.
+type SlackService struct {
+	enabled bool
+	hosts   []string
+	appURL  string
+}
+
+func NewSlackService(cfg config.ChatConfig, appURL string) *SlackService {
+	return &SlackService{enabled: cfg.Enabled, hosts: cfg.Hosts, appURL: strings.TrimRight(appURL, "/")}
+}
+
+func (s *SlackService) Channel() string {
+	return "slack"
+}
+
+func (s *SlackService) IsConfigured() bool {
+	return s.enabled
+}
+
+// Recipient returns the webhook URL the user saved in their settings.
+func (s *SlackService) Recipient(user entity.User, pref entity.NotificationPreference) (string, bool) {
+	return pref.Address, pref.Address != ""
+}
+
+func (s *SlackService) ValidateAddress(address string) error {
+	return validateChatWebhookURL(address, s.hosts)
+}
+
+// Send posts n as Block Kit blocks, with plain text for clients that cannot
+// show them.
+func (s *SlackService) Send(ctx context.Context, webhookURL string, n entity.Notification) (string, error) {
+	if err := s.ValidateAddress(webhookURL); err != nil {
+		return "", err
+	}
+
+	card, err := newChatCard(n, s.appURL)
+	if err != nil {
+		return "", err
+	}
+
+	return postChatWebhook(ctx, "slack", webhookURL, slackMessage(card))
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string        `json:"type"`
+	Text     *slackText    `json:"text,omitempty"`
+	Fields   []slackText   `json:"fields,omitempty"`
+	Elements []slackButton `json:"elements,omitempty"`
+}
+
+type slackButton struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+	URL  string    `json:"url"`
+}
+
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+func slackMessage(card chatCard) slackPayload {
+	fields := make([]slackText, len(card.Fields))
+	for i, field := range card.Fields {
+		fields[i] = slackText{Type: "mrkdwn", Text: "*" + field.Name + "*\n" + slackEscape(field.Value)}
+	}
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: card.Title}},
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: slackEscape(card.Text)}},
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, slackBlock{Type: "section", Fields: fields})
+	}
+	blocks = append(blocks, slackBlock{
+		Type: "actions",
+		Elements: []slackButton{
+			{Type: "button", Text: slackText{Type: "plain_text", Text: "Open FinanceBroke"}, URL: card.URL},
+		},
+	})
+
+	return slackPayload{Text: slackEscape(card.Title + ": " + card.Text), Blocks: blocks}
+}
+
+// slackEscape escapes the characters Slack treats as markup in mrkdwn text,
+// so bill names cannot ping channels or add links.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
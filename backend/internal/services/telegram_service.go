@@ -2,35 +2,152 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"financebroke/backend/internal/config"
 	"financebroke/backend/internal/entity"
 )
 
+// Callback data prefixes of the buttons attached to bill notifications. The
+// bill ID follows the colon.
+const (
+	TelegramActionPaid   = "paid"
+	TelegramActionSnooze = "snooze"
+)
+
 type TelegramService struct {
-	botToken string
+	botToken    string
+	apiURL      string
+	interactive bool
+	client      *http.Client
 }
 
-func NewTelegramService(botToken string) *TelegramService {
-	return &TelegramService{botToken: botToken}
+// NewTelegramService creates the Telegram notifier and Bot API client. When
+// the bot receives updates, bill notifications get "Mark paid" and "Snooze"
+// buttons.
+func NewTelegramService(cfg config.TelegramConfig) *TelegramService {
+	// Long polling holds requests open for PollTimeout, so the client waits
+	// a little longer than that
+	return &TelegramService{
+		botToken:    cfg.BotToken,
+		apiURL:      strings.TrimRight(cfg.APIURL, "/"),
+		interactive: cfg.UpdateMode != "",
+		client:      &http.Client{Timeout: cfg.PollTimeout + 15*time.Second},
+	}
+}
+
+// IsConfigured reports whether a bot token has been provided.
+func (t *TelegramService) IsConfigured() bool {
+	return t.botToken != ""
 }
 
 type TelegramMessage struct {
-	ChatID string `json:"chat_id"`
-	Text   string `json:"text"`
+	ChatID      string                  `json:"chat_id"`
+	Text        string                  `json:"text"`
+	ReplyMarkup *TelegramInlineKeyboard `json:"reply_markup,omitempty"`
+}
+
+// TelegramInlineKeyboard is a grid of buttons shown under a message.
+type TelegramInlineKeyboard struct {
+	InlineKeyboard [][]TelegramInlineButton `json:"inline_keyboard"`
+}
+
+// TelegramInlineButton sends CallbackData back to the bot when pressed.
+type TelegramInlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// TelegramUpdate is an incoming update from the Bot API. Only messages and
+// button presses are used.
+type TelegramUpdate struct {
+	UpdateID      int64                   `json:"update_id"`
+	Message       *TelegramInboundMessage `json:"message,omitempty"`
+	CallbackQuery *TelegramCallbackQuery  `json:"callback_query,omitempty"`
+}
+
+type TelegramInboundMessage struct {
+	MessageID int64        `json:"message_id"`
+	Chat      TelegramChat `json:"chat"`
+	Text      string       `json:"text"`
+}
+
+type TelegramChat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+// TelegramCallbackQuery is a press of an inline button. Message is the message
+// the button was attached to.
+type TelegramCallbackQuery struct {
+	ID      string                  `json:"id"`
+	Data    string                  `json:"data"`
+	Message *TelegramInboundMessage `json:"message,omitempty"`
 }
 
-func (t *TelegramService) SendReminder(bill *entity.Bill, user *entity.User) error {
-	if user.TelegramChatID == "" || !user.TelegramNotify {
-		return fmt.Errorf("telegram notification disabled or chat ID not set")
+func (t *TelegramService) Channel() string {
+	return "telegram"
+}
+
+// LinksAddress reports that chat IDs are only saved by linking a chat through
+// the bot, since the bot acts for whoever the chat belongs to.
+func (t *TelegramService) LinksAddress() bool {
+	return true
+}
+
+// Recipient returns the chat ID the user saved in their settings.
+func (t *TelegramService) Recipient(user entity.User, pref entity.NotificationPreference) (string, bool) {
+	return pref.Address, pref.Address != ""
+}
+
+func (t *TelegramService) Send(ctx context.Context, chatID string, n entity.Notification) (string, error) {
+	var message string
+	var keyboard *TelegramInlineKeyboard
+	switch n.Kind {
+	case entity.NotificationBillReminder:
+		message = reminderMessage(n.Bill)
+		keyboard = t.billKeyboard(n.Bill)
+	case entity.NotificationBillOverdue:
+		message = overdueMessage(n.Bill)
+		keyboard = t.billKeyboard(n.Bill)
+	case entity.NotificationBudgetAlert:
+		message = budgetAlertMessage(n.Budget, n.Threshold)
+	case entity.NotificationTest:
+		message = n.Text
+	default:
+		return "", fmt.Errorf("unsupported notification kind %q", n.Kind)
+	}
+
+	return t.sendMessage(ctx, TelegramMessage{ChatID: chatID, Text: message, ReplyMarkup: keyboard})
+}
+
+// billKeyboard returns the buttons for acting on a bill from the chat, or nil
+// when the bot does not receive the presses.
+func (t *TelegramService) billKeyboard(bill *entity.Bill) *TelegramInlineKeyboard {
+	if !t.interactive {
+		return nil
 	}
+	id := strconv.FormatUint(uint64(bill.ID), 10)
+	return &TelegramInlineKeyboard{InlineKeyboard: [][]TelegramInlineButton{{
+		{Text: "✅ Mark paid", CallbackData: TelegramActionPaid + ":" + id},
+		{Text: "⏰ Snooze 1 day", CallbackData: TelegramActionSnooze + ":" + id},
+	}}}
+}
 
-	message := fmt.Sprintf(
+func reminderMessage(bill *entity.Bill) string {
+	return fmt.Sprintf(
 		"💰 *Bill Reminder*\n\n"+
 			"*%s*\n"+
-			"Amount: *Rp%.2f*\n"+
+			"Amount: *Rp%s*\n"+
 			"Due Date: *%s*\n"+
 			"Status: *%s*\n\n"+
 			"Don't forget to pay your bill! 💳",
@@ -39,56 +156,177 @@ func (t *TelegramService) SendReminder(bill *entity.Bill, user *entity.User) err
 		bill.DueDate.Format("2006-01-02"),
 		bill.Status,
 	)
+}
 
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.botToken)
+func overdueMessage(bill *entity.Bill) string {
+	return fmt.Sprintf(
+		"⚠️ *Bill Overdue*\n\n"+
+			"*%s*\n"+
+			"Amount: *Rp%s*\n"+
+			"Was due: *%s*\n\n"+
+			"This bill is past its due date. Please pay it as soon as possible.",
+		bill.Name,
+		bill.Amount,
+		bill.DueDate.Format("2006-01-02"),
+	)
+}
 
-	msg := TelegramMessage{
-		ChatID: user.TelegramChatID,
-		Text:   message,
+func budgetAlertMessage(usage *entity.BudgetUsage, threshold int) string {
+	title := "📊 *Budget Warning*"
+	if threshold >= entity.BudgetThresholdExceeded {
+		title = "🚨 *Budget Exceeded*"
 	}
 
-	jsonData, err := json.Marshal(msg)
-	if err != nil {
-		return err
-	}
+	return fmt.Sprintf(
+		"%s\n\n"+
+			"*%s* is at *%.0f%%* of its budget for %s.\n"+
+			"Spent: *Rp%s*\n"+
+			"Budget: *Rp%s*\n"+
+			"Remaining: *Rp%s*",
+		title,
+		usage.CategoryName,
+		usage.PercentUsed(),
+		usage.PeriodStart.Format("January 2006"),
+		usage.Spent,
+		usage.Amount,
+		usage.Remaining(),
+	)
+}
+
+// maxResponseBytes caps how much of an API response is kept for the
+// delivery log.
+const maxResponseBytes = 4096
+
+// SendMessage sends a reply to a chat.
+func (t *TelegramService) SendMessage(ctx context.Context, msg TelegramMessage) error {
+	_, err := t.sendMessage(ctx, msg)
+	return err
+}
+
+// sendMessage posts a message and returns the API's response body.
+func (t *TelegramService) sendMessage(ctx context.Context, msg TelegramMessage) (string, error) {
+	body, err := t.post(ctx, "sendMessage", msg)
+	return truncate(body), err
+}
+
+// AnswerCallbackQuery stops the loading indicator on a pressed button and
+// shows text to the user, if any.
+func (t *TelegramService) AnswerCallbackQuery(ctx context.Context, queryID, text string) error {
+	return t.call(ctx, "answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": queryID,
+		"text":              text,
+	}, nil)
+}
+
+// RemoveKeyboard removes the buttons from a message once they have been used.
+func (t *TelegramService) RemoveKeyboard(ctx context.Context, chatID, messageID int64) error {
+	return t.call(ctx, "editMessageReplyMarkup", map[string]interface{}{
+		"chat_id":      chatID,
+		"message_id":   messageID,
+		"reply_markup": TelegramInlineKeyboard{InlineKeyboard: [][]TelegramInlineButton{}},
+	}, nil)
+}
+
+// GetUpdates long-polls for updates after offset, waiting up to timeout for
+// one to arrive.
+func (t *TelegramService) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]TelegramUpdate, error) {
+	var updates []TelegramUpdate
+	err := t.call(ctx, "getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message", "callback_query"},
+	}, &updates)
+	return updates, err
+}
+
+// SetWebhook asks Telegram to push updates to url, sending secret in the
+// X-Telegram-Bot-Api-Secret-Token header of each request.
+func (t *TelegramService) SetWebhook(ctx context.Context, url, secret string) error {
+	return t.call(ctx, "setWebhook", map[string]interface{}{
+		"url":             url,
+		"secret_token":    secret,
+		"allowed_updates": []string{"message", "callback_query"},
+	}, nil)
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewReader(jsonData))
+// DeleteWebhook stops pushed updates, which Telegram requires before
+// getUpdates can be used.
+func (t *TelegramService) DeleteWebhook(ctx context.Context) error {
+	return t.call(ctx, "deleteWebhook", map[string]interface{}{}, nil)
+}
+
+// telegramResponse is the envelope of every Bot API response.
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// call invokes a Bot API method and decodes its result into result, when
+// result is not nil.
+func (t *TelegramService) call(ctx context.Context, method string, payload, result interface{}) error {
+	body, err := t.post(ctx, method, payload)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("telegram API error: %s", string(body))
+	if result == nil {
+		return nil
 	}
-
-	return nil
+	var envelope telegramResponse
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("telegram API %s: invalid response: %w", method, err)
+	}
+	return json.Unmarshal(envelope.Result, result)
 }
 
-func (t *TelegramService) SendTestMessage(chatID, message string) error {
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.botToken)
+// post sends payload to a Bot API method and returns the response body. Any
+// answer other than a successful one is an error.
+func (t *TelegramService) post(ctx context.Context, method string, payload interface{}) ([]byte, error) {
+	url := fmt.Sprintf("%s/bot%s/%s", t.apiURL, t.botToken, method)
 
-	msg := TelegramMessage{
-		ChatID: chatID,
-		Text:   message,
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
 	}
 
-	jsonData, err := json.Marshal(msg)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.Post(url, "application/json", bytes.NewReader(jsonData))
+	resp, err := t.client.Do(req)
 	if err != nil {
-		return err
+		// The URL contains the bot token, so it is left out of the error
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("telegram API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxUpdateBytes))
+	if err != nil {
+		return nil, fmt.Errorf("telegram API request failed: %w", err)
+	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("telegram API error: %s", string(body))
+		var envelope telegramResponse
+		if json.Unmarshal(body, &envelope) == nil && envelope.Description != "" {
+			return body, fmt.Errorf("telegram API %s error: %s", method, envelope.Description)
+		}
+		return body, fmt.Errorf("telegram API %s error: %s", method, truncate(body))
 	}
+	return body, nil
+}
+
+// maxUpdateBytes caps a Bot API response. A batch of updates can be far
+// larger than the part kept for the delivery log.
+const maxUpdateBytes = 4 << 20
 
-	return nil
-}
\ No newline at end of file
+func truncate(body []byte) string {
+	if len(body) > maxResponseBytes {
+		body = body[:maxResponseBytes]
+	}
+	return string(body)
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"financebroke/backend/internal/config"
+)
+
+// Headers sent with every webhook delivery. The signature covers the
+// timestamp and the body, so receivers can reject replayed requests.
+const (
+	WebhookEventHeader     = "X-FinanceBroke-Event"
+	WebhookDeliveryHeader  = "X-FinanceBroke-Delivery"
+	WebhookTimestampHeader = "X-FinanceBroke-Timestamp"
+	WebhookSignatureHeader = "X-FinanceBroke-Signature"
+)
+
+// ErrPrivateAddress is returned for webhook URLs that resolve to loopback,
+// private or link-local addresses while those are not allowed.
+var ErrPrivateAddress = errors.New("webhook address is not publicly routable")
+
+// sharedAddressSpace is the carrier-grade NAT range, which net.IP.IsPrivate
+// does not cover.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type WebhookService struct {
+	client       *http.Client
+	allowPrivate bool
+}
+
+func NewWebhookService(cfg config.WebhookConfig) *WebhookService {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		// Checked on the resolved address of every connection, so a public
+		// host name pointing at an internal address is refused too
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would make the connection checks above meaningless
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookService{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+			// Redirects are reported as they are instead of followed, so an
+			// endpoint cannot bounce deliveries to another address
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		allowPrivate: cfg.AllowPrivateNetworks,
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() && !sharedAddressSpace.Contains(ip)
+}
+
+// ValidateURL checks that rawURL is an absolute http(s) URL a webhook can be
+// delivered to. Host names are only resolved when delivering.
+func (s *WebhookService) ValidateURL(rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return errors.New("webhook URL is not valid")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("webhook URL must start with http:// or https://")
+	}
+	if u.Hostname() == "" {
+		return errors.New("webhook URL must have a host")
+	}
+	if u.User != nil {
+		return errors.New("webhook URL must not contain credentials")
+	}
+
+	if !s.allowPrivate {
+		host := strings.ToLower(u.Hostname())
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return ErrPrivateAddress
+		}
+		if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the signature header value for a delivery:
+// "sha256=" followed by the hex HMAC-SHA256, keyed with the endpoint's secret,
+// of the Unix timestamp, a dot and the request body.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookRequest is one signed delivery of Payload to URL.
+type WebhookRequest struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID uint
+	Payload    []byte
+}
+
+// WebhookResponse is what the endpoint answered. StatusCode is zero when no
+// response was received.
+type WebhookResponse struct {
+	StatusCode int
+	Body       string
+}
+
+// Deliver posts the payload and returns the endpoint's response. Any status
+// outside 2xx is an error.
+func (s *WebhookService) Deliver(ctx context.Context, r WebhookRequest) (WebhookResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Payload))
+	if err != nil {
+		return WebhookResponse{}, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FinanceBroke-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, r.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(r.DeliveryID), 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(r.Secret, timestamp, r.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return WebhookResponse{}, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	response := WebhookResponse{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return response, fmt.Errorf("webhook endpoint answered %s", resp.Status)
+	}
+	return response, nil
+}
@@ -1,46 +1,153 @@
 package usecase
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"financebroke/backend/internal/apperror"
 	"financebroke/backend/internal/dto"
 	"financebroke/backend/internal/entity"
 	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/services"
 	"financebroke/backend/internal/utils"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type AuthUsecase interface {
-	Register(req *dto.RegisterRequest) (*dto.AuthResponse, error)
-	Login(req *dto.LoginRequest) (*dto.AuthResponse, error)
-	GetProfile(userID uint) (*entity.User, error)
+	Register(ctx context.Context, req *dto.RegisterRequest, client dto.ClientInfo) (*dto.AuthResponse, error)
+	Login(ctx context.Context, req *dto.LoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
+	LoginTwoFactor(ctx context.Context, req *dto.TwoFactorLoginRequest, client dto.ClientInfo) (*dto.AuthResponse, error)
+	Refresh(ctx context.Context, req *dto.RefreshRequest) (*dto.AuthResponse, error)
+	Logout(ctx context.Context, userID, sessionID uint) error
+	LogoutAll(ctx context.Context, userID uint) error
+	ValidateSession(ctx context.Context, userID, sessionID uint) error
+	ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest)
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error
+	ResendVerification(ctx context.Context, userID uint) error
+	GetProfile(ctx context.Context, userID uint) (*entity.User, error)
+	UpdateProfile(ctx context.Context, userID uint, req *dto.UpdateProfileRequest) (*entity.User, error)
+	ChangePassword(ctx context.Context, userID, sessionID uint, req *dto.ChangePasswordRequest) error
+	ChangeEmail(ctx context.Context, userID uint, req *dto.ChangeEmailRequest) error
+	DeleteAccount(ctx context.Context, userID uint, req *dto.PasswordConfirmRequest) (*entity.User, error)
+	RestoreAccount(ctx context.Context, userID uint) (*entity.User, error)
+	PurgeDeletedAccounts(ctx context.Context, now time.Time) (int64, error)
+	// Shutdown waits for emails still being sent in the background.
+	Shutdown(ctx context.Context) error
 }
 
+var (
+	// ErrInvalidRefreshToken is returned for refresh tokens that are unknown,
+	// expired, already used or belong to a revoked session.
+	ErrInvalidRefreshToken = apperror.Unauthorized("INVALID_REFRESH_TOKEN", "invalid or expired refresh token")
+	// ErrSessionRevoked is returned for access tokens whose session has been
+	// logged out or has expired.
+	ErrSessionRevoked = apperror.Unauthorized("SESSION_REVOKED", "session revoked")
+	// ErrInvalidResetToken is returned for password reset tokens that are
+	// unknown, expired or already used.
+	ErrInvalidResetToken = apperror.BadRequest("INVALID_RESET_TOKEN", "invalid or expired reset token")
+	// ErrPasswordMismatch is returned when a password and its confirmation
+	// differ.
+	ErrPasswordMismatch = apperror.Validation("PASSWORD_MISMATCH", "passwords do not match")
+	// ErrInvalidVerificationToken is returned for email verification tokens
+	// that are unknown, expired or already used.
+	ErrInvalidVerificationToken = apperror.BadRequest("INVALID_VERIFICATION_TOKEN", "invalid or expired verification token")
+	// ErrEmailAlreadyVerified is returned when asking for a verification
+	// email for an address that is already verified.
+	ErrEmailAlreadyVerified = apperror.Conflict("EMAIL_ALREADY_VERIFIED", "email already verified")
+	// ErrVerificationThrottled is returned when verification emails are
+	// requested too often.
+	ErrVerificationThrottled = apperror.RateLimited("VERIFICATION_THROTTLED", "verification email requested too often, try again later")
+	// ErrInvalidChallenge is returned for two-factor login challenges that
+	// are unknown, expired, already completed or failed too often.
+	ErrInvalidChallenge = apperror.Unauthorized("INVALID_LOGIN_CHALLENGE", "invalid or expired login challenge, sign in again")
+	// ErrEmailTaken is returned when changing to an address that belongs to
+	// another account.
+	ErrEmailTaken = apperror.Conflict("EMAIL_TAKEN", "email already registered")
+	// ErrSameEmail is returned when asking to change to the current address.
+	ErrSameEmail = apperror.Validation("SAME_EMAIL", "new email is the same as the current one")
+	// ErrInvalidCredentials is returned by Login for an unknown email or a
+	// wrong password alike.
+	ErrInvalidCredentials = apperror.Unauthorized("INVALID_CREDENTIALS", "invalid credentials")
+	// ErrEmailUnavailable is returned for actions that need to send an email
+	// when the server has no email service configured.
+	ErrEmailUnavailable = apperror.Validation("EMAIL_UNAVAILABLE", "email is not configured on this server")
+)
+
+const (
+	// passwordResetTTL is how long a password reset link stays valid.
+	passwordResetTTL = time.Hour
+	// emailVerificationTTL is how long an email verification link stays valid.
+	emailVerificationTTL = 24 * time.Hour
+
+	// Verification emails are sent at most once per
+	// verificationResendInterval and verificationHourlyLimit times an hour.
+	verificationResendInterval = time.Minute
+	verificationHourlyLimit    = 5
+
+	// twoFactorChallengeTTL is how long the second login step may take, and
+	// twoFactorMaxAttempts how many wrong codes it accepts.
+	twoFactorChallengeTTL = 5 * time.Minute
+	twoFactorMaxAttempts  = 5
+
+	// accountDeletionGrace is how long a deleted account can still be
+	// restored before it and all its data are removed for good.
+	accountDeletionGrace = 30 * 24 * time.Hour
+)
+
 type authUsecase struct {
-	userRepo repository.UserRepository
+	userRepo      repository.UserRepository
+	sessionRepo   repository.SessionRepository
+	userTokenRepo repository.UserTokenRepository
+	twoFactorRepo repository.TwoFactorRepository
+	tokens        *utils.TokenManager
+	emailSvc      *services.EmailService
+	appURL        string
+	background    backgroundTasks
 }
 
-func NewAuthUsecase(userRepo repository.UserRepository) AuthUsecase {
-	return &authUsecase{userRepo: userRepo}
+func NewAuthUsecase(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	userTokenRepo repository.UserTokenRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	tokens *utils.TokenManager,
+	emailSvc *services.EmailService,
+	appURL string,
+) AuthUsecase {
+	return &authUsecase{
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		userTokenRepo: userTokenRepo,
+		twoFactorRepo: twoFactorRepo,
+		tokens:        tokens,
+		emailSvc:      emailSvc,
+		appURL:        strings.TrimRight(appURL, "/"),
+	}
 }
 
-func (u *authUsecase) Register(req *dto.RegisterRequest) (*dto.AuthResponse, error) {
-	logger := utils.GetLogger()
+func (u *authUsecase) Register(ctx context.Context, req *dto.RegisterRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	logger := utils.FromContext(ctx)
 	logger.Info("[USECASE] Starting registration", map[string]interface{}{
 		"email": req.Email,
 	})
 
 	if req.Password != req.ConfirmPassword {
-		err := errors.New("passwords do not match")
-		utils.LogError("AUTH_USECASE_REGISTER", err)
+		err := ErrPasswordMismatch
+		utils.LogErrorContext(ctx, "AUTH_USECASE_REGISTER", err)
 		return nil, err
 	}
 
 	logger.Info("[USECASE] Checking if email exists", map[string]interface{}{
 		"email": req.Email,
 	})
-	existingUser, err := u.userRepo.FindByEmail(req.Email)
+	existingUser, err := u.userRepo.FindByEmail(ctx, req.Email)
 	if err == nil && existingUser.ID != 0 {
-		err := errors.New("email already registered")
-		utils.LogError("AUTH_USECASE_REGISTER", err)
+		err := ErrEmailTaken
+		utils.LogErrorContext(ctx, "AUTH_USECASE_REGISTER", err)
 		return nil, err
 	}
 
@@ -49,69 +156,714 @@ func (u *authUsecase) Register(req *dto.RegisterRequest) (*dto.AuthResponse, err
 	})
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		utils.LogError("AUTH_USECASE_HASH", err)
-		return nil, errors.New("failed to hash password")
+		utils.LogErrorContext(ctx, "AUTH_USECASE_HASH", err)
+		return nil, fmt.Errorf("hash password: %w", err)
 	}
 
 	user := entity.User{
-		Email:       req.Email,
-		Password:    hashedPassword,
-		Name:        req.Name,
-		EmailNotify: true,
+		Email:    req.Email,
+		Password: hashedPassword,
+		Name:     req.Name,
 	}
 
 	logger.Info("[USECASE] Creating user in database", map[string]interface{}{
 		"email": req.Email,
 	})
-	createdUser, err := u.userRepo.Create(user)
+	createdUser, err := u.userRepo.Create(ctx, user)
 	if err != nil {
-		utils.LogError("AUTH_USECASE_CREATE", err)
-		return nil, errors.New("failed to create user")
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CREATE", err)
+		// Registered by someone else since the check above
+		if errors.Is(err, repository.ErrEmailExists) {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("create user: %w", err)
 	}
 
 	logger.Info("[USECASE] User created successfully", map[string]interface{}{
 		"user_id": createdUser.ID,
 	})
-	token, err := utils.GenerateToken(createdUser.ID)
+	// The email outlives the request, so it must not be cancelled with it
+	background := context.WithoutCancel(ctx)
+	u.background.Go(func() {
+		if err := u.sendVerification(background, createdUser); err != nil {
+			utils.LogErrorContext(background, "AUTH_USECASE_VERIFICATION_SEND", err)
+		}
+	})
+
+	response, err := u.startSession(ctx, createdUser, client)
 	if err != nil {
-		utils.LogError("AUTH_USECASE_TOKEN", err)
-		return nil, errors.New("failed to generate token")
+		utils.LogErrorContext(ctx, "AUTH_USECASE_TOKEN", err)
+		return nil, fmt.Errorf("start session: %w", err)
 	}
 
 	logger.Info("[USECASE] Registration completed successfully", map[string]interface{}{
 		"email": req.Email,
 	})
-	return &dto.AuthResponse{
-		Token: token,
-		User:  createdUser,
-	}, nil
+	return response, nil
 }
 
-func (u *authUsecase) Login(req *dto.LoginRequest) (*dto.AuthResponse, error) {
-	user, err := u.userRepo.FindByEmail(req.Email)
+// Login checks the user's password. Without two-factor authentication it
+// starts a session right away; otherwise it returns a challenge token to be
+// completed with LoginTwoFactor.
+func (u *authUsecase) Login(ctx context.Context, req *dto.LoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	user, err := u.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	if !utils.CheckPassword(req.Password, user.Password) {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
-	token, err := utils.GenerateToken(user.ID)
+	twoFactor, err := u.twoFactorRepo.Find(ctx, user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_LOGIN", err)
+		return nil, err
+	}
+	if err == nil && twoFactor.Enabled() {
+		return u.startTwoFactorChallenge(ctx, user)
+	}
+
+	response, err := u.startSession(ctx, user, client)
 	if err != nil {
-		return nil, errors.New("failed to generate token")
+		utils.LogErrorContext(ctx, "AUTH_USECASE_TOKEN", err)
+		return nil, fmt.Errorf("start session: %w", err)
 	}
 
-	return &dto.AuthResponse{
-		Token: token,
-		User:  user,
+	return &dto.LoginResponse{AuthResponse: response}, nil
+}
+
+func (u *authUsecase) startTwoFactorChallenge(ctx context.Context, user entity.User) (*dto.LoginResponse, error) {
+	challenge, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := u.userTokenRepo.Create(ctx, entity.UserToken{
+		UserID:    user.ID,
+		Purpose:   entity.UserTokenTwoFactorLogin,
+		TokenHash: utils.HashToken(challenge),
+		ExpiresAt: time.Now().Add(twoFactorChallengeTTL),
+	})
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_LOGIN", err)
+		return nil, err
+	}
+
+	return &dto.LoginResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     challenge,
+		ChallengeExpiresAt: &token.ExpiresAt,
 	}, nil
 }
 
-func (u *authUsecase) GetProfile(userID uint) (*entity.User, error) {
-	user, err := u.userRepo.FindByID(userID)
+// LoginTwoFactor completes a login challenge with a code from the user's
+// authenticator app or a recovery code. A challenge is abandoned after
+// twoFactorMaxAttempts wrong codes.
+func (u *authUsecase) LoginTwoFactor(ctx context.Context, req *dto.TwoFactorLoginRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	now := time.Now()
+	challengeHash := utils.HashToken(req.ChallengeToken)
+
+	challenge, err := u.userTokenRepo.FindValid(ctx, entity.UserTokenTwoFactorLogin, challengeHash, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+
+	twoFactor, err := u.twoFactorRepo.Find(ctx, challenge.UserID)
+	if err != nil || !twoFactor.Enabled() {
+		return nil, ErrInvalidChallenge
+	}
+
+	if req.Code != "" {
+		err = verifyTOTP(ctx, u.twoFactorRepo, twoFactor, req.Code, now)
+	} else {
+		err = u.twoFactorRepo.UseRecoveryCode(ctx, challenge.UserID, utils.HashRecoveryCode(req.RecoveryCode), now)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrInvalidTwoFactorCode
+		}
+	}
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		u.recordFailedChallenge(ctx, challenge, now)
+		return nil, err
+	}
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_LOGIN_2FA", err)
+		return nil, err
+	}
+
+	if _, err := u.userTokenRepo.Consume(ctx, entity.UserTokenTwoFactorLogin, challengeHash, now); err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := u.userRepo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	response, err := u.startSession(ctx, user, client)
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_TOKEN", err)
+		return nil, fmt.Errorf("start session: %w", err)
+	}
+
+	return response, nil
+}
+
+func (u *authUsecase) recordFailedChallenge(ctx context.Context, challenge entity.UserToken, now time.Time) {
+	attempts, err := u.userTokenRepo.RecordFailedAttempt(ctx, challenge.ID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_LOGIN_2FA", err)
+		return
+	}
+
+	if attempts >= twoFactorMaxAttempts {
+		if _, err := u.userTokenRepo.Consume(ctx, challenge.Purpose, challenge.TokenHash, now); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			utils.LogErrorContext(ctx, "AUTH_USECASE_LOGIN_2FA", err)
+		}
+	}
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token; the old refresh token cannot be used again. Presenting a refresh
+// token that was already used means it has leaked, so the whole session is
+// revoked and every token descended from the same login stops working.
+func (u *authUsecase) Refresh(ctx context.Context, req *dto.RefreshRequest) (*dto.AuthResponse, error) {
+	now := time.Now()
+
+	token, err := u.sessionRepo.FindRefreshToken(ctx, utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			utils.LogErrorContext(ctx, "AUTH_USECASE_REFRESH", err)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := u.sessionRepo.FindByID(ctx, token.SessionID)
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_REFRESH", err)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil {
+		u.revokeReusedSession(ctx, session, now)
+		return nil, ErrInvalidRefreshToken
+	}
+	if !session.Active(now) || !now.Before(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := u.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_TOKEN", err)
+		return nil, fmt.Errorf("generate refresh token: %w", err)
+	}
+
+	next := entity.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: now.Add(u.tokens.RefreshTTL()),
+	}
+	if err := u.sessionRepo.Rotate(ctx, token, next, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another request spent the same token first.
+			u.revokeReusedSession(ctx, session, now)
+			return nil, ErrInvalidRefreshToken
+		}
+		utils.LogErrorContext(ctx, "AUTH_USECASE_REFRESH", err)
+		return nil, err
+	}
+
+	return u.issueTokens(user, session.ID, refreshToken)
+}
+
+// Logout revokes the session the access token was issued for.
+func (u *authUsecase) Logout(ctx context.Context, userID, sessionID uint) error {
+	err := u.sessionRepo.Revoke(ctx, sessionID, userID, time.Now())
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_LOGOUT", err)
+		return err
+	}
+	return nil
+}
+
+// LogoutAll revokes every session of the user, including the current one.
+func (u *authUsecase) LogoutAll(ctx context.Context, userID uint) error {
+	revoked, err := u.sessionRepo.RevokeAllForUser(ctx, userID, time.Now())
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_LOGOUT_ALL", err)
+		return err
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] All sessions revoked", map[string]interface{}{
+		"user_id":  userID,
+		"sessions": revoked,
+	})
+	return nil
+}
+
+// ValidateSession checks that the session an access token belongs to is
+// still active, so that logging out takes effect before the token expires.
+func (u *authUsecase) ValidateSession(ctx context.Context, userID, sessionID uint) error {
+	session, err := u.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+
+	if session.UserID != userID || !session.Active(time.Now()) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// ForgotPassword emails a one-time password reset link if the address belongs
+// to an account. The work happens in the background and nothing is reported
+// back, so callers cannot tell whether the address is registered, neither
+// from the response nor from how long it takes.
+func (u *authUsecase) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) {
+	background := context.WithoutCancel(ctx)
+	u.background.Go(func() {
+		u.sendPasswordReset(background, req.Email)
+	})
+}
+
+func (u *authUsecase) Shutdown(ctx context.Context) error {
+	return u.background.Wait(ctx)
+}
+
+func (u *authUsecase) sendPasswordReset(ctx context.Context, email string) {
+	if !u.emailSvc.IsConfigured() {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_FORGOT", ErrEmailUnavailable)
+		return
+	}
+
+	user, err := u.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_FORGOT", err)
+		return
+	}
+
+	_, err = u.userTokenRepo.Create(ctx, entity.UserToken{
+		UserID:    user.ID,
+		Purpose:   entity.UserTokenPasswordReset,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	})
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_FORGOT", err)
+		return
+	}
+
+	link := u.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	if err := u.emailSvc.SendPasswordReset(ctx, &user, link, passwordResetTTL); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_FORGOT_SEND", err)
+		return
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Password reset link sent", map[string]interface{}{
+		"user_id": user.ID,
+	})
+}
+
+// ResetPassword sets a new password using a reset token. The token and any
+// other outstanding reset links stop working, and every session of the user
+// is revoked so that whoever knew the old password is logged out.
+func (u *authUsecase) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	if req.Password != req.ConfirmPassword {
+		return ErrPasswordMismatch
+	}
+
+	now := time.Now()
+	token, err := u.userTokenRepo.Consume(ctx, entity.UserTokenPasswordReset, utils.HashToken(req.Token), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		utils.LogErrorContext(ctx, "AUTH_USECASE_RESET", err)
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_HASH", err)
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	if err := u.userRepo.UpdatePassword(ctx, token.UserID, hashedPassword); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_RESET", err)
+		return err
+	}
+
+	if err := u.userTokenRepo.InvalidateAll(ctx, token.UserID, entity.UserTokenPasswordReset, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_RESET", err)
+	}
+
+	if _, err := u.sessionRepo.RevokeAllForUser(ctx, token.UserID, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_RESET", err)
+		return err
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Password reset", map[string]interface{}{
+		"user_id": token.UserID,
+	})
+	return nil
+}
+
+// VerifyEmail confirms the user's email address with a verification token,
+// or completes an email change with a token sent to the new address.
+func (u *authUsecase) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error {
+	now := time.Now()
+	tokenHash := utils.HashToken(req.Token)
+	token, err := u.userTokenRepo.Consume(ctx, entity.UserTokenEmailVerification, tokenHash, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		return u.confirmEmailChange(ctx, tokenHash, now)
+	}
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_VERIFY", err)
+		return err
+	}
+
+	if err := u.userRepo.MarkEmailVerified(ctx, token.UserID, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_VERIFY", err)
+		return err
+	}
+
+	if err := u.userTokenRepo.InvalidateAll(ctx, token.UserID, entity.UserTokenEmailVerification, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_VERIFY", err)
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Email verified", map[string]interface{}{
+		"user_id": token.UserID,
+	})
+	return nil
+}
+
+// ResendVerification emails a new verification link, subject to throttling.
+// Links sent earlier keep working until they expire.
+func (u *authUsecase) ResendVerification(ctx context.Context, userID uint) error {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	now := time.Now()
+	recent, err := u.userTokenRepo.FindCreatedSince(ctx, userID, entity.UserTokenEmailVerification, now.Add(-time.Hour))
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_RESEND_VERIFICATION", err)
+		return err
+	}
+	if len(recent) >= verificationHourlyLimit ||
+		(len(recent) > 0 && now.Sub(recent[0].CreatedAt) < verificationResendInterval) {
+		return ErrVerificationThrottled
+	}
+
+	if err := u.sendVerification(ctx, user); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_VERIFICATION_SEND", err)
+		return err
+	}
+	return nil
+}
+
+func (u *authUsecase) sendVerification(ctx context.Context, user entity.User) error {
+	if !u.emailSvc.IsConfigured() {
+		return ErrEmailUnavailable
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = u.userTokenRepo.Create(ctx, entity.UserToken{
+		UserID:    user.ID,
+		Purpose:   entity.UserTokenEmailVerification,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := u.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	return u.emailSvc.SendEmailVerification(ctx, &user, link, emailVerificationTTL)
+}
+
+func (u *authUsecase) GetProfile(ctx context.Context, userID uint) (*entity.User, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	return &user, nil
+}
+
+func (u *authUsecase) UpdateProfile(ctx context.Context, userID uint, req *dto.UpdateProfileRequest) (*entity.User, error) {
+	user, err := u.userRepo.UpdateName(ctx, userID, strings.TrimSpace(req.Name))
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 	return &user, nil
 }
+
+// ChangePassword sets a new password after checking the current one. Every
+// other session of the user is logged out; the one making the change stays.
+func (u *authUsecase) ChangePassword(ctx context.Context, userID, sessionID uint, req *dto.ChangePasswordRequest) error {
+	if req.NewPassword != req.ConfirmPassword {
+		return ErrPasswordMismatch
+	}
+
+	if err := confirmPassword(ctx, u.userRepo, userID, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_HASH", err)
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	if err := u.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_PASSWORD", err)
+		return err
+	}
+
+	now := time.Now()
+	if err := u.userTokenRepo.InvalidateAll(ctx, userID, entity.UserTokenPasswordReset, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_PASSWORD", err)
+	}
+
+	if _, err := u.sessionRepo.RevokeOthers(ctx, userID, sessionID, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_PASSWORD", err)
+		return err
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Password changed", map[string]interface{}{
+		"user_id": userID,
+	})
+	return nil
+}
+
+// ChangeEmail sends a confirmation link to the new address. The account keeps
+// its current address until the link is opened through VerifyEmail.
+func (u *authUsecase) ChangeEmail(ctx context.Context, userID uint, req *dto.ChangeEmailRequest) error {
+	if err := confirmPassword(ctx, u.userRepo, userID, req.Password); err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return ErrSameEmail
+	}
+	if existing, err := u.userRepo.FindByEmail(ctx, newEmail); err == nil && existing.ID != 0 {
+		return ErrEmailTaken
+	}
+
+	if !u.emailSvc.IsConfigured() {
+		return ErrEmailUnavailable
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := u.userTokenRepo.InvalidateAll(ctx, userID, entity.UserTokenEmailChange, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_EMAIL", err)
+	}
+
+	_, err = u.userTokenRepo.Create(ctx, entity.UserToken{
+		UserID:    userID,
+		Purpose:   entity.UserTokenEmailChange,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(emailVerificationTTL),
+		Payload:   newEmail,
+	})
+	if err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_EMAIL", err)
+		return err
+	}
+
+	link := u.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	if err := u.emailSvc.SendEmailChange(ctx, &user, newEmail, link, emailVerificationTTL); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_EMAIL", err)
+		return err
+	}
+	return nil
+}
+
+func (u *authUsecase) confirmEmailChange(ctx context.Context, tokenHash string, now time.Time) error {
+	token, err := u.userTokenRepo.FindValid(ctx, entity.UserTokenEmailChange, tokenHash, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidVerificationToken
+		}
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_EMAIL", err)
+		return err
+	}
+
+	// The address may have been registered since the link was sent. The
+	// link is left unused, so it still works should the address be freed.
+	if existing, err := u.userRepo.FindByEmail(ctx, token.Payload); err == nil && existing.ID != token.UserID {
+		return ErrEmailTaken
+	}
+
+	token, err = u.userTokenRepo.Consume(ctx, entity.UserTokenEmailChange, tokenHash, now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidVerificationToken
+		}
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_EMAIL", err)
+		return err
+	}
+
+	if err := u.userRepo.UpdateEmail(ctx, token.UserID, token.Payload, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_CHANGE_EMAIL", err)
+		if errors.Is(err, repository.ErrEmailExists) {
+			return ErrEmailTaken
+		}
+		return err
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Email changed", map[string]interface{}{
+		"user_id": token.UserID,
+	})
+	return nil
+}
+
+// DeleteAccount schedules the account for deletion after a grace period and
+// logs it out everywhere. Signing in again and calling RestoreAccount during
+// the grace period undoes it.
+func (u *authUsecase) DeleteAccount(ctx context.Context, userID uint, req *dto.PasswordConfirmRequest) (*entity.User, error) {
+	if err := confirmPassword(ctx, u.userRepo, userID, req.Password); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deletionAt := now.Add(accountDeletionGrace)
+	if err := u.userRepo.ScheduleDeletion(ctx, userID, &deletionAt); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_DELETE_ACCOUNT", err)
+		return nil, err
+	}
+
+	if _, err := u.sessionRepo.RevokeAllForUser(ctx, userID, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_DELETE_ACCOUNT", err)
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Account scheduled for deletion", map[string]interface{}{
+		"user_id":     userID,
+		"deletion_at": deletionAt,
+	})
+	return u.GetProfile(ctx, userID)
+}
+
+func (u *authUsecase) RestoreAccount(ctx context.Context, userID uint) (*entity.User, error) {
+	if err := u.userRepo.ScheduleDeletion(ctx, userID, nil); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_RESTORE_ACCOUNT", err)
+		return nil, err
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Account deletion cancelled", map[string]interface{}{
+		"user_id": userID,
+	})
+	return u.GetProfile(ctx, userID)
+}
+
+// PurgeDeletedAccounts permanently removes accounts whose grace period has
+// ended, together with all their data.
+func (u *authUsecase) PurgeDeletedAccounts(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := u.userRepo.DeleteScheduled(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	if deleted > 0 {
+		utils.FromContext(ctx).Info("[USECASE] Deleted accounts purged", map[string]interface{}{
+			"accounts": deleted,
+		})
+	}
+	return deleted, nil
+}
+
+func (u *authUsecase) startSession(ctx context.Context, user entity.User, client dto.ClientInfo) (*dto.AuthResponse, error) {
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(u.tokens.RefreshTTL())
+	session, err := u.sessionRepo.Create(
+		ctx,
+		entity.Session{
+			UserID:    user.ID,
+			UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+			IPAddress: client.IPAddress,
+			ExpiresAt: expiresAt,
+		},
+		entity.RefreshToken{TokenHash: utils.HashToken(refreshToken), ExpiresAt: expiresAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.issueTokens(user, session.ID, refreshToken)
+}
+
+func (u *authUsecase) issueTokens(user entity.User, sessionID uint, refreshToken string) (*dto.AuthResponse, error) {
+	token, expiresAt, err := u.tokens.GenerateToken(user.ID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+		User:         user,
+	}, nil
+}
+
+func (u *authUsecase) revokeReusedSession(ctx context.Context, session entity.Session, now time.Time) {
+	utils.FromContext(ctx).Info("[USECASE] Refresh token reuse detected, revoking session", map[string]interface{}{
+		"user_id":    session.UserID,
+		"session_id": session.ID,
+	})
+
+	if err := u.sessionRepo.Revoke(ctx, session.ID, session.UserID, now); err != nil {
+		utils.LogErrorContext(ctx, "AUTH_USECASE_REUSE", err)
+	}
+}
+
+// maxUserAgentLength matches the sessions.user_agent column.
+const maxUserAgentLength = 512
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
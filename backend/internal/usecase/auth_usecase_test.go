@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/utils"
+)
+
+// fakeUserRepo keeps users by ID and fails writes that would reuse an email,
+// the way the unique constraint does.
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uint]entity.User
+	// registered is an address taken by a concurrent request that lookups
+	// do not see yet
+	registered string
+}
+
+func (r *fakeUserRepo) FindByEmail(_ context.Context, email string) (entity.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return entity.User{}, sql.ErrNoRows
+}
+
+func (r *fakeUserRepo) Create(_ context.Context, user entity.User) (entity.User, error) {
+	if user.Email == r.registered {
+		return entity.User{}, repository.ErrEmailExists
+	}
+	return entity.User{}, errors.New("unexpected create")
+}
+
+func TestRegisterEmailTakenConcurrently(t *testing.T) {
+	users := &fakeUserRepo{registered: "ann@example.com"}
+	u := NewAuthUsecase(users, nil, nil, nil, nil, nil, "")
+
+	_, err := u.Register(context.Background(), &dto.RegisterRequest{
+		Name:            "Ann",
+		Email:           "ann@example.com",
+		Password:        "secret1",
+		ConfirmPassword: "secret1",
+	}, dto.ClientInfo{})
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Register error = %v, want %v", err, ErrEmailTaken)
+	}
+}
+
+func (r *fakeUserRepo) UpdateEmail(_ context.Context, id uint, email string, _ time.Time) error {
+	if email == r.registered {
+		return repository.ErrEmailExists
+	}
+	user := r.users[id]
+	user.Email = email
+	r.users[id] = user
+	return nil
+}
+
+// fakeTokenRepo holds email change tokens by hash.
+type fakeTokenRepo struct {
+	repository.UserTokenRepository
+	tokens map[string]entity.UserToken
+}
+
+func (r *fakeTokenRepo) find(purpose, tokenHash string) (entity.UserToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil {
+		return entity.UserToken{}, sql.ErrNoRows
+	}
+	return token, nil
+}
+
+func (r *fakeTokenRepo) FindValid(_ context.Context, purpose, tokenHash string, _ time.Time) (entity.UserToken, error) {
+	return r.find(purpose, tokenHash)
+}
+
+func (r *fakeTokenRepo) Consume(_ context.Context, purpose, tokenHash string, at time.Time) (entity.UserToken, error) {
+	token, err := r.find(purpose, tokenHash)
+	if err != nil {
+		return token, err
+	}
+	token.UsedAt = &at
+	r.tokens[tokenHash] = token
+	return token, nil
+}
+
+func TestVerifyEmailChange(t *testing.T) {
+	const link = "change-link"
+
+	tests := []struct {
+		name         string
+		otherEmail   string
+		registered   string
+		wantErr      error
+		wantEmail    string
+		wantConsumed bool
+	}{
+		{
+			name:         "address is changed",
+			wantEmail:    "new@example.com",
+			wantConsumed: true,
+		},
+		{
+			name:       "address taken by another account keeps the link",
+			otherEmail: "new@example.com",
+			wantErr:    ErrEmailTaken,
+			wantEmail:  "old@example.com",
+		},
+		{
+			name:         "address taken concurrently",
+			registered:   "new@example.com",
+			wantErr:      ErrEmailTaken,
+			wantEmail:    "old@example.com",
+			wantConsumed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{
+				users: map[uint]entity.User{
+					1: {ID: 1, Email: "old@example.com"},
+					2: {ID: 2, Email: tt.otherEmail},
+				},
+				registered: tt.registered,
+			}
+			tokens := &fakeTokenRepo{tokens: map[string]entity.UserToken{
+				utils.HashToken(link): {ID: 9, UserID: 1, Purpose: entity.UserTokenEmailChange, Payload: "new@example.com"},
+			}}
+			u := NewAuthUsecase(users, nil, tokens, nil, nil, nil, "")
+
+			err := u.VerifyEmail(context.Background(), &dto.VerifyEmailRequest{Token: link})
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("VerifyEmail error = %v, want %v", err, tt.wantErr)
+			}
+			if email := users.users[1].Email; email != tt.wantEmail {
+				t.Errorf("email = %q, want %q", email, tt.wantEmail)
+			}
+			if consumed := tokens.tokens[utils.HashToken(link)].UsedAt != nil; consumed != tt.wantConsumed {
+				t.Errorf("link used up = %v, want %v", consumed, tt.wantConsumed)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+)
+
+// backgroundTasks tracks work that outlives the request that started it, such
+// as sending emails, so shutdown can wait for it instead of cutting it off.
+type backgroundTasks struct {
+	wg sync.WaitGroup
+}
+
+func (t *backgroundTasks) Go(fn func()) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		fn()
+	}()
+}
+
+// Wait blocks until every task has finished or ctx is done.
+func (t *backgroundTasks) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,32 +1,87 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"financebroke/backend/internal/apperror"
 	"financebroke/backend/internal/dto"
 	"financebroke/backend/internal/entity"
 	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/utils"
+	"sort"
+	"strings"
 	"time"
 )
 
 type BillUsecase interface {
-	CreateBill(userID uint, req *dto.BillCreateRequest) (entity.Bill, error)
-	GetBill(userID, billID uint) (entity.Bill, error)
-	GetUserBills(userID uint) ([]entity.Bill, error)
-	GetUpcomingBills(userID uint) ([]entity.Bill, error)
-	UpdateBill(userID, billID uint, req *dto.BillUpdateRequest) (entity.Bill, error)
-	DeleteBill(userID, billID uint) error
-	GetDashboard(userID uint) (*dto.DashboardResponse, error)
+	CreateBill(ctx context.Context, userID uint, req *dto.BillCreateRequest) (entity.Bill, error)
+	GetBill(ctx context.Context, userID, billID uint) (entity.Bill, error)
+	GetUserBills(ctx context.Context, userID uint, query *dto.BillListQuery) (*dto.BillListResponse, error)
+	GetUpcomingBills(ctx context.Context, userID uint) ([]entity.Bill, error)
+	UpdateBill(ctx context.Context, userID, billID uint, scope string, req *dto.BillUpdateRequest) (entity.Bill, error)
+	DeleteBill(ctx context.Context, userID, billID uint, scope string) error
+	GetDashboard(ctx context.Context, userID uint) (*dto.DashboardResponse, error)
+	MarkOverdueBills(ctx context.Context, now time.Time) (int64, error)
+	RecordPayment(ctx context.Context, userID, billID uint, req *dto.PaymentCreateRequest) (*dto.PaymentResponse, error)
+	GetPayments(ctx context.Context, userID, billID uint) ([]entity.Payment, error)
+	VoidPayment(ctx context.Context, userID, billID, paymentID uint, req *dto.PaymentVoidRequest) (*dto.PaymentResponse, error)
+	SnoozeReminder(ctx context.Context, userID, billID uint, until time.Time) (entity.Bill, error)
 }
 
 type billUsecase struct {
-	billRepo repository.BillRepository
+	billRepo       repository.BillRepository
+	recurrenceRepo repository.RecurrenceRepository
+	paymentRepo    repository.PaymentRepository
+	categoryRepo   repository.CategoryRepository
+	tagRepo        repository.TagRepository
+	tx             repository.Transactor
+	events         BillEventPublisher
+	generator      occurrenceGenerator
 }
 
-func NewBillUsecase(billRepo repository.BillRepository) BillUsecase {
-	return &billUsecase{billRepo: billRepo}
+func NewBillUsecase(
+	billRepo repository.BillRepository,
+	recurrenceRepo repository.RecurrenceRepository,
+	paymentRepo repository.PaymentRepository,
+	categoryRepo repository.CategoryRepository,
+	tagRepo repository.TagRepository,
+	tx repository.Transactor,
+	events BillEventPublisher,
+) BillUsecase {
+	return &billUsecase{
+		billRepo:       billRepo,
+		recurrenceRepo: recurrenceRepo,
+		paymentRepo:    paymentRepo,
+		categoryRepo:   categoryRepo,
+		tagRepo:        tagRepo,
+		tx:             tx,
+		events:         events,
+		generator:      occurrenceGenerator{billRepo: billRepo, recurrenceRepo: recurrenceRepo, events: events},
+	}
 }
 
-func (u *billUsecase) CreateBill(userID uint, req *dto.BillCreateRequest) (entity.Bill, error) {
-	dueDate, err := time.Parse("2006-01-02", req.DueDate)
+// ErrInvalidBillQuery is returned for malformed GET /bills query parameters.
+var ErrInvalidBillQuery = apperror.BadRequest("INVALID_BILL_QUERY", "invalid bill query")
+
+const (
+	defaultBillPageSize = 20
+	recentBillsLimit    = 5
+)
+
+var (
+	errNotRecurring    = apperror.Validation("BILL_NOT_RECURRING", "bill is not part of a recurrence")
+	errStatusDerived   = apperror.Validation("BILL_STATUS_DERIVED", "bill status is derived from its payments; record or void a payment instead")
+	errInvalidStatus   = apperror.Validation("INVALID_BILL_STATUS", "invalid bill status")
+	errPaymentExceeds  = apperror.Validation("PAYMENT_EXCEEDS_BALANCE", "payment exceeds the outstanding balance")
+	errTagTooLong      = apperror.Validation("TAG_TOO_LONG", "tag names must be at most 50 characters")
+	errInvalidPaidAt   = apperror.Validation("INVALID_DATE", "paid_at must be an RFC 3339 time or YYYY-MM-DD")
+	errBillAlreadyPaid = apperror.Validation("BILL_ALREADY_PAID", "bill is already paid")
+)
+
+const maxTagNameLength = 50
+
+func (u *billUsecase) CreateBill(ctx context.Context, userID uint, req *dto.BillCreateRequest) (entity.Bill, error) {
+	dueDate, err := parseDate("due_date", req.DueDate)
 	if err != nil {
 		return entity.Bill{}, err
 	}
@@ -42,33 +97,368 @@ func (u *billUsecase) CreateBill(userID uint, req *dto.BillCreateRequest) (entit
 		Amount:       req.Amount,
 		DueDate:      dueDate,
 		Description:  req.Description,
-		Status:       "unpaid",
+		Status:       entity.BillStatusUnpaid,
 		RemindBefore: remindBefore,
 	}
+	bill.Status = deriveBillStatus(bill.Amount, 0, bill.DueDate, time.Now())
+
+	categoryID, err := u.resolveCategory(ctx, userID, req.CategoryID)
+	if err != nil {
+		return entity.Bill{}, err
+	}
+	bill.CategoryID = categoryID
+
+	tags, err := u.resolveTags(ctx, userID, req.Tags)
+	if err != nil {
+		return entity.Bill{}, err
+	}
+
+	if req.Recurrence != nil {
+		bill, err = u.createRecurringBill(ctx, bill, req.Recurrence)
+	} else {
+		bill, err = u.billRepo.Create(ctx, bill)
+	}
+	if err != nil {
+		return bill, err
+	}
+	if len(tags) > 0 {
+		if err := u.setTags(ctx, &bill, tags); err != nil {
+			return bill, err
+		}
+	}
 
-	return u.billRepo.Create(bill)
+	u.events.PublishBillEvent(ctx, entity.BillEventCreated, bill)
+	return bill, nil
 }
 
-func (u *billUsecase) GetBill(userID, billID uint) (entity.Bill, error) {
-	return u.billRepo.FindByID(billID, userID)
+// createRecurringBill stores the recurrence described by req with bill as its
+// template and generates bill as the first occurrence.
+func (u *billUsecase) createRecurringBill(ctx context.Context, bill entity.Bill, req *dto.RecurrenceRequest) (entity.Bill, error) {
+	recurrence := entity.Recurrence{
+		UserID:       bill.UserID,
+		Frequency:    req.Frequency,
+		Interval:     req.Interval,
+		AnchorDate:   bill.DueDate,
+		Name:         bill.Name,
+		Amount:       bill.Amount,
+		Description:  bill.Description,
+		RemindBefore: bill.RemindBefore,
+		Active:       true,
+		CategoryID:   bill.CategoryID,
+	}
+	if recurrence.Interval == 0 {
+		recurrence.Interval = 1
+	}
+	if req.EndDate != "" {
+		endDate, err := parseDate("end_date", req.EndDate)
+		if err != nil {
+			return entity.Bill{}, err
+		}
+		recurrence.EndDate = &endDate
+	}
+	if req.Count > 0 {
+		count := req.Count
+		recurrence.MaxOccurrences = &count
+	}
+
+	if err := recurrence.Validate(); err != nil {
+		return entity.Bill{}, errInvalidSchedule.WithMessage("%v", err)
+	}
+
+	recurrence, err := u.recurrenceRepo.Create(ctx, recurrence)
+	if err != nil {
+		return entity.Bill{}, err
+	}
+
+	first, _, err := u.generator.generate(ctx, recurrence, 0, time.Now())
+	return first, err
+}
+
+func (u *billUsecase) GetBill(ctx context.Context, userID, billID uint) (entity.Bill, error) {
+	u.refreshOverdue(ctx, userID)
+	return u.findBill(ctx, userID, billID)
+}
+
+// findBill loads one of the user's bills, reporting ErrBillNotFound when it
+// does not exist.
+func (u *billUsecase) findBill(ctx context.Context, userID, billID uint) (entity.Bill, error) {
+	bill, err := u.billRepo.FindByID(ctx, billID, userID)
+	return bill, notFound(err, ErrBillNotFound)
+}
+
+func (u *billUsecase) GetUserBills(ctx context.Context, userID uint, query *dto.BillListQuery) (*dto.BillListResponse, error) {
+	filter, err := billFilter(userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	u.refreshOverdue(ctx, userID)
+	bills, total, err := u.billRepo.FindPage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if bills == nil {
+		bills = []entity.Bill{}
+	}
+
+	return &dto.BillListResponse{
+		Data: bills,
+		Pagination: dto.Pagination{
+			Page:       filter.Offset/filter.Limit + 1,
+			PageSize:   filter.Limit,
+			Total:      total,
+			TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+		},
+	}, nil
 }
 
-func (u *billUsecase) GetUserBills(userID uint) ([]entity.Bill, error) {
-	return u.billRepo.FindByUserID(userID)
+// billFilter turns the query parameters of GET /bills into a repository
+// filter. Dates are inclusive YYYY-MM-DD days.
+func billFilter(userID uint, query *dto.BillListQuery) (repository.BillFilter, error) {
+	filter := repository.BillFilter{
+		UserID:   userID,
+		Search:   strings.TrimSpace(query.Search),
+		Tag:      normalizeTagName(query.Tag),
+		SortBy:   strings.TrimPrefix(query.Sort, "-"),
+		SortDesc: strings.HasPrefix(query.Sort, "-"),
+		Limit:    defaultBillPageSize,
+	}
+
+	if query.Status != "" {
+		for _, status := range strings.Split(query.Status, ",") {
+			status = strings.TrimSpace(status)
+			if !entity.IsValidBillStatus(status) {
+				return filter, ErrInvalidBillQuery.WithMessage("invalid bill query: unknown status %q", status)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if query.DueFrom != "" {
+		dueFrom, err := time.Parse("2006-01-02", query.DueFrom)
+		if err != nil {
+			return filter, ErrInvalidBillQuery.WithMessage("invalid bill query: due_from must be YYYY-MM-DD")
+		}
+		filter.DueFrom = &dueFrom
+	}
+	if query.DueTo != "" {
+		dueTo, err := time.Parse("2006-01-02", query.DueTo)
+		if err != nil {
+			return filter, ErrInvalidBillQuery.WithMessage("invalid bill query: due_to must be YYYY-MM-DD")
+		}
+		dueBefore := dueTo.AddDate(0, 0, 1)
+		filter.DueBefore = &dueBefore
+	}
+
+	if query.MinAmount != "" {
+		minAmount, err := entity.ParseMoney(query.MinAmount)
+		if err != nil {
+			return filter, ErrInvalidBillQuery.WithMessage("invalid bill query: min_amount: %v", err)
+		}
+		filter.MinAmount = &minAmount
+	}
+	if query.MaxAmount != "" {
+		maxAmount, err := entity.ParseMoney(query.MaxAmount)
+		if err != nil {
+			return filter, ErrInvalidBillQuery.WithMessage("invalid bill query: max_amount: %v", err)
+		}
+		filter.MaxAmount = &maxAmount
+	}
+
+	if query.CategoryID != 0 {
+		categoryID := query.CategoryID
+		filter.CategoryID = &categoryID
+	}
+
+	if query.PageSize > 0 {
+		filter.Limit = query.PageSize
+	}
+	if query.Page > 1 {
+		filter.Offset = (query.Page - 1) * filter.Limit
+	}
+
+	return filter, nil
 }
 
-func (u *billUsecase) GetUpcomingBills(userID uint) ([]entity.Bill, error) {
+func (u *billUsecase) GetUpcomingBills(ctx context.Context, userID uint) ([]entity.Bill, error) {
+	u.refreshOverdue(ctx, userID)
 	now := time.Now()
 	oneMonthLater := now.AddDate(0, 1, 0)
-	return u.billRepo.FindUpcomingBills(userID, now, oneMonthLater)
+	return u.billRepo.FindUpcomingBills(ctx, userID, now, oneMonthLater)
 }
 
-func (u *billUsecase) UpdateBill(userID, billID uint, req *dto.BillUpdateRequest) (entity.Bill, error) {
-	bill, err := u.billRepo.FindByID(billID, userID)
+// UpdateBill edits a bill. For recurring bills, scope "future" also applies the
+// changes to the recurrence template and every later unpaid occurrence.
+func (u *billUsecase) UpdateBill(ctx context.Context, userID, billID uint, scope string, req *dto.BillUpdateRequest) (entity.Bill, error) {
+	bill, err := u.findBill(ctx, userID, billID)
 	if err != nil {
 		return entity.Bill{}, err
 	}
 
+	if scope == dto.EditScopeFuture && bill.RecurrenceID == nil {
+		return entity.Bill{}, errNotRecurring
+	}
+
+	wasPaid := bill.Status == entity.BillStatusPaid
+	previousDueDate := bill.DueDate
+
+	if req.CategoryID != nil {
+		if bill.CategoryID, err = u.resolveCategory(ctx, userID, req.CategoryID); err != nil {
+			return entity.Bill{}, err
+		}
+	}
+
+	var tags []entity.Tag
+	if req.Tags != nil {
+		if tags, err = u.resolveTags(ctx, userID, *req.Tags); err != nil {
+			return entity.Bill{}, err
+		}
+	}
+
+	if err := applyBillChanges(&bill, req); err != nil {
+		return entity.Bill{}, err
+	}
+	if req.Status != "" && !entity.IsValidBillStatus(req.Status) {
+		return entity.Bill{}, errInvalidStatus.WithMessage("invalid bill status: %s", req.Status)
+	}
+	now := time.Now()
+	bill.Status = deriveBillStatus(bill.Amount, bill.PaidAmount, bill.DueDate, now)
+	if req.Status != "" && req.Status != entity.BillStatusPaid && req.Status != bill.Status {
+		return entity.Bill{}, errStatusDerived
+	}
+
+	// The bill, its tags, the later occurrences and the settling payment are
+	// written together; events go out once they are committed.
+	var (
+		updated entity.Bill
+		future  []entity.Bill
+		paid    bool
+	)
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = u.billRepo.Update(ctx, bill); err != nil {
+			return err
+		}
+
+		if req.Tags != nil {
+			if err := u.setTags(ctx, &updated, tags); err != nil {
+				return err
+			}
+		}
+
+		if scope == dto.EditScopeFuture {
+			future, err = u.updateFutureOccurrences(ctx, updated, req, tags, !updated.DueDate.Equal(previousDueDate), now)
+			if err != nil {
+				return err
+			}
+		}
+
+		if req.Status == entity.BillStatusPaid && updated.Status != entity.BillStatusPaid {
+			// Marking a bill paid settles whatever is still outstanding. A
+			// payment made meanwhile may already have settled it.
+			_, err := u.paymentRepo.Settle(ctx, entity.Payment{
+				BillID: updated.ID,
+				UserID: userID,
+				PaidAt: now,
+				Method: entity.PaymentMethodOther,
+				Note:   "Marked as paid",
+			})
+			if err != nil && !errors.Is(err, repository.ErrPaymentExceedsBalance) {
+				return err
+			}
+			updated, paid, err = u.syncStatus(ctx, userID, updated.ID, now)
+			return err
+		}
+
+		paid = !wasPaid && updated.Status == entity.BillStatusPaid
+		return nil
+	})
+	if err != nil {
+		return entity.Bill{}, err
+	}
+
+	for _, occurrence := range future {
+		u.events.PublishBillEvent(ctx, entity.BillEventUpdated, occurrence)
+	}
+	if paid {
+		u.onPaid(ctx, updated, now)
+	}
+	u.events.PublishBillEvent(ctx, entity.BillEventUpdated, updated)
+	return updated, nil
+}
+
+// updateFutureOccurrences carries the edit of bill, including its category
+// and any new tags, over to its recurrence and to the unpaid occurrences after
+// it, and returns the occurrences it updated. A changed due date re-anchors
+// the schedule on bill so later occurrences shift with it.
+func (u *billUsecase) updateFutureOccurrences(ctx context.Context, bill entity.Bill, req *dto.BillUpdateRequest, tags []entity.Tag, dueDateChanged bool, now time.Time) ([]entity.Bill, error) {
+	recurrence, err := u.recurrenceRepo.FindByID(ctx, *bill.RecurrenceID, bill.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		recurrence.Name = req.Name
+	}
+	if req.Amount > 0 {
+		recurrence.Amount = req.Amount
+	}
+	if req.Description != "" {
+		recurrence.Description = req.Description
+	}
+	if req.RemindBefore > 0 {
+		recurrence.RemindBefore = req.RemindBefore
+	}
+	if req.CategoryID != nil {
+		recurrence.CategoryID = bill.CategoryID
+	}
+	if dueDateChanged {
+		recurrence.AnchorDate = bill.DueDate
+		recurrence.AnchorIndex = *bill.OccurrenceIndex
+		recurrence.AnchorDay = 0
+	}
+
+	recurrence, err = u.recurrenceRepo.Update(ctx, recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	future, err := u.billRepo.FindOccurrencesAfter(ctx, recurrence.ID, *bill.OccurrenceIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, occurrence := range future {
+		futureReq := *req
+		futureReq.DueDate = ""
+		if err := applyBillChanges(&occurrence, &futureReq); err != nil {
+			return nil, err
+		}
+		if dueDateChanged {
+			occurrence.DueDate = recurrence.OccurrenceDate(*occurrence.OccurrenceIndex)
+		}
+		if req.CategoryID != nil {
+			occurrence.CategoryID = bill.CategoryID
+		}
+		occurrence.Status = deriveBillStatus(occurrence.Amount, occurrence.PaidAmount, occurrence.DueDate, now)
+
+		updated, err := u.billRepo.Update(ctx, occurrence)
+		if err != nil {
+			return nil, err
+		}
+		if req.Tags != nil {
+			if err := u.setTags(ctx, &updated, tags); err != nil {
+				return nil, err
+			}
+		}
+		future[i] = updated
+	}
+
+	return future, nil
+}
+
+// applyBillChanges copies the non-empty fields of req, except status, onto bill.
+func applyBillChanges(bill *entity.Bill, req *dto.BillUpdateRequest) error {
 	if req.Name != "" {
 		bill.Name = req.Name
 	}
@@ -76,59 +466,358 @@ func (u *billUsecase) UpdateBill(userID, billID uint, req *dto.BillUpdateRequest
 		bill.Amount = req.Amount
 	}
 	if req.DueDate != "" {
-		dueDate, err := time.Parse("2006-01-02", req.DueDate)
+		dueDate, err := parseDate("due_date", req.DueDate)
 		if err != nil {
-			return entity.Bill{}, err
+			return err
 		}
 		bill.DueDate = dueDate
 	}
 	if req.Description != "" {
 		bill.Description = req.Description
 	}
-	if req.Status != "" {
-		bill.Status = req.Status
-	}
 	if req.RemindBefore > 0 {
 		bill.RemindBefore = req.RemindBefore
 	}
+	return nil
+}
+
+// resolveCategory checks that the category belongs to the user. A nil or zero
+// ID means no category.
+func (u *billUsecase) resolveCategory(ctx context.Context, userID uint, categoryID *uint) (*uint, error) {
+	if categoryID == nil || *categoryID == 0 {
+		return nil, nil
+	}
+
+	category, err := u.categoryRepo.FindByID(ctx, *categoryID, userID)
+	if err != nil {
+		return nil, notFound(err, ErrCategoryNotFound)
+	}
+	return &category.ID, nil
+}
 
-	return u.billRepo.Update(bill)
+// resolveTags returns the user's tags with the given names, creating the
+// missing ones.
+func (u *billUsecase) resolveTags(ctx context.Context, userID uint, names []string) ([]entity.Tag, error) {
+	names = normalizeTagNames(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	for _, name := range names {
+		if len(name) > maxTagNameLength {
+			return nil, errTagTooLong
+		}
+	}
+	return u.tagRepo.FindOrCreate(ctx, userID, names)
 }
 
-func (u *billUsecase) DeleteBill(userID, billID uint) error {
-	return u.billRepo.Delete(billID, userID)
+// setTags replaces the tags on bill, both stored and in memory.
+func (u *billUsecase) setTags(ctx context.Context, bill *entity.Bill, tags []entity.Tag) error {
+	tagIDs := make([]uint, len(tags))
+	billTags := make([]entity.BillTag, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+		billTags[i] = entity.BillTag{ID: tag.ID, Name: tag.Name}
+	}
+
+	if err := u.billRepo.SetTags(ctx, bill.ID, tagIDs); err != nil {
+		return err
+	}
+
+	sort.Slice(billTags, func(i, j int) bool { return billTags[i].Name < billTags[j].Name })
+	bill.Tags = billTags
+	return nil
 }
 
-func (u *billUsecase) GetDashboard(userID uint) (*dto.DashboardResponse, error) {
-	stats, err := u.billRepo.GetDashboardStats(userID)
+// DeleteBill removes a bill. For recurring bills, scope "future" also ends the
+// recurrence and removes the unpaid occurrences after this one.
+func (u *billUsecase) DeleteBill(ctx context.Context, userID, billID uint, scope string) error {
+	bill, err := u.findBill(ctx, userID, billID)
+	if err != nil {
+		return err
+	}
+
+	if scope != dto.EditScopeFuture {
+		if err := u.billRepo.Delete(ctx, billID, userID); err != nil {
+			return notFound(err, ErrBillNotFound)
+		}
+		u.events.PublishBillEvent(ctx, entity.BillEventDeleted, bill)
+		return nil
+	}
+
+	if bill.RecurrenceID == nil {
+		return errNotRecurring
+	}
+
+	var future []entity.Bill
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		recurrence, err := u.recurrenceRepo.FindByID(ctx, *bill.RecurrenceID, userID)
+		if err != nil {
+			return err
+		}
+		recurrence.Active = false
+		if _, err := u.recurrenceRepo.Update(ctx, recurrence); err != nil {
+			return err
+		}
+
+		future, err = u.billRepo.FindOccurrencesAfter(ctx, recurrence.ID, *bill.OccurrenceIndex)
+		if err != nil {
+			return err
+		}
+		if err := u.billRepo.DeleteOccurrencesFrom(ctx, recurrence.ID, *bill.OccurrenceIndex+1); err != nil {
+			return err
+		}
+
+		return u.billRepo.Delete(ctx, billID, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, occurrence := range future {
+		u.events.PublishBillEvent(ctx, entity.BillEventDeleted, occurrence)
+	}
+	u.events.PublishBillEvent(ctx, entity.BillEventDeleted, bill)
+	return nil
+}
+
+func (u *billUsecase) GetDashboard(ctx context.Context, userID uint) (*dto.DashboardResponse, error) {
+	u.refreshOverdue(ctx, userID)
+
+	stats, err := u.billRepo.GetDashboardStats(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	upcomingBills, err := u.billRepo.FindUpcomingBills(userID, time.Now(), time.Now().AddDate(0, 1, 0))
+	upcomingBills, err := u.billRepo.FindUpcomingBills(ctx, userID, time.Now(), time.Now().AddDate(0, 1, 0))
 	if err != nil {
 		return nil, err
 	}
 
-	recentBills, err := u.billRepo.FindByUserID(userID)
+	recentBills, err := u.billRepo.FindRecent(ctx, userID, recentBillsLimit)
 	if err != nil {
 		return nil, err
 	}
 
 	response := &dto.DashboardResponse{
-		TotalBills:    stats.TotalBills,
-		PaidBills:     stats.PaidBills,
-		UnpaidBills:   stats.UnpaidBills,
-		OverdueBills:  stats.OverdueBills,
-		UpcomingBills: upcomingBills,
-		RecentBills:   recentBills,
+		TotalBills:         stats.TotalBills,
+		PaidBills:          stats.PaidBills,
+		UnpaidBills:        stats.UnpaidBills,
+		PartiallyPaidBills: stats.PartiallyPaidBills,
+		OverdueBills:       stats.OverdueBills,
+		UpcomingBills:      upcomingBills,
+		RecentBills:        recentBills,
 		Summary: dto.BillSummary{
-			TotalAmount:    stats.TotalAmount,
-			PaidAmount:     stats.PaidAmount,
-			UnpaidAmount:   stats.UnpaidAmount,
-			OverdueAmount:  stats.OverdueAmount,
+			TotalAmount:   stats.TotalAmount,
+			PaidAmount:    stats.PaidAmount,
+			UnpaidAmount:  stats.UnpaidAmount,
+			OverdueAmount: stats.OverdueAmount,
 		},
+		Categories: make([]dto.CategorySummary, 0, len(stats.Categories)),
+	}
+
+	for _, category := range stats.Categories {
+		response.Categories = append(response.Categories, dto.CategorySummary{
+			CategoryID:   category.CategoryID,
+			Name:         category.Name,
+			Color:        category.Color,
+			BillCount:    category.BillCount,
+			TotalAmount:  category.TotalAmount,
+			PaidAmount:   category.PaidAmount,
+			UnpaidAmount: category.UnpaidAmount,
+		})
 	}
 
 	return response, nil
-}
\ No newline at end of file
+}
+
+// MarkOverdueBills moves every unpaid bill whose due date is before today to
+// overdue. It is run periodically by the scheduler.
+func (u *billUsecase) MarkOverdueBills(ctx context.Context, now time.Time) (int64, error) {
+	count, err := u.billRepo.MarkOverdue(ctx, startOfDay(now))
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		utils.FromContext(ctx).Info("[USECASE] Bills marked overdue", map[string]interface{}{
+			"count": count,
+		})
+	}
+	return count, nil
+}
+
+// refreshOverdue applies the overdue transition for a single user before their
+// bills are read, so responses are correct even between scheduler runs.
+func (u *billUsecase) refreshOverdue(ctx context.Context, userID uint) {
+	if _, err := u.billRepo.MarkOverdueByUser(ctx, userID, startOfDay(time.Now())); err != nil {
+		utils.LogErrorContext(ctx, "BILL_USECASE_REFRESH_OVERDUE", err)
+	}
+}
+
+// RecordPayment adds a payment to the bill's ledger and re-derives its status.
+func (u *billUsecase) RecordPayment(ctx context.Context, userID, billID uint, req *dto.PaymentCreateRequest) (*dto.PaymentResponse, error) {
+	bill, err := u.findBill(ctx, userID, billID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Checked again by the repository while the bill is locked
+	if req.Amount > bill.Amount-bill.PaidAmount {
+		return nil, errPaymentExceeds
+	}
+
+	now := time.Now()
+	paidAt := now
+	if req.PaidAt != "" {
+		paidAt, err = parsePaidAt(req.PaidAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	method := req.Method
+	if method == "" {
+		method = entity.PaymentMethodOther
+	}
+
+	payment, err := u.paymentRepo.Create(ctx, entity.Payment{
+		BillID:    bill.ID,
+		UserID:    userID,
+		Amount:    req.Amount,
+		PaidAt:    paidAt,
+		Method:    method,
+		Reference: req.Reference,
+		Note:      req.Note,
+	})
+	if errors.Is(err, repository.ErrPaymentExceedsBalance) {
+		return nil, errPaymentExceeds
+	}
+	if err != nil {
+		return nil, notFound(err, ErrBillNotFound)
+	}
+
+	bill, paid, err := u.syncStatus(ctx, userID, billID, now)
+	if err != nil {
+		return nil, err
+	}
+	if paid {
+		u.onPaid(ctx, bill, now)
+	}
+
+	return &dto.PaymentResponse{Payment: payment, Bill: bill}, nil
+}
+
+func (u *billUsecase) GetPayments(ctx context.Context, userID, billID uint) ([]entity.Payment, error) {
+	if _, err := u.findBill(ctx, userID, billID); err != nil {
+		return nil, err
+	}
+	return u.paymentRepo.FindByBillID(ctx, billID, userID)
+}
+
+// VoidPayment removes a payment from the bill's totals while keeping it in the
+// ledger, then re-derives the bill's status.
+func (u *billUsecase) VoidPayment(ctx context.Context, userID, billID, paymentID uint, req *dto.PaymentVoidRequest) (*dto.PaymentResponse, error) {
+	payment, err := u.paymentRepo.FindByID(ctx, paymentID, userID)
+	if err != nil {
+		return nil, notFound(err, ErrPaymentNotFound)
+	}
+	// A payment addressed through another bill does not exist at this URL
+	if payment.BillID != billID {
+		return nil, ErrPaymentNotFound
+	}
+
+	now := time.Now()
+	payment, err = u.paymentRepo.Void(ctx, paymentID, userID, req.Reason, now)
+	if err != nil {
+		return nil, err
+	}
+
+	bill, paid, err := u.syncStatus(ctx, userID, billID, now)
+	if err != nil {
+		return nil, err
+	}
+	if paid {
+		u.onPaid(ctx, bill, now)
+	}
+
+	return &dto.PaymentResponse{Payment: payment, Bill: bill}, nil
+}
+
+// SnoozeReminder holds back the bill's reminder, or its overdue notice once it
+// is overdue, until the given time and then sends it again.
+func (u *billUsecase) SnoozeReminder(ctx context.Context, userID, billID uint, until time.Time) (entity.Bill, error) {
+	bill, err := u.findBill(ctx, userID, billID)
+	if err != nil {
+		return entity.Bill{}, err
+	}
+	if bill.Status == entity.BillStatusPaid {
+		return entity.Bill{}, errBillAlreadyPaid
+	}
+
+	if err := u.billRepo.SnoozeReminder(ctx, billID, userID, until); err != nil {
+		return entity.Bill{}, notFound(err, ErrBillNotFound)
+	}
+
+	return u.findBill(ctx, userID, billID)
+}
+
+// syncStatus re-derives a bill's status from its payments and due date and
+// persists it when it changed. paid reports whether this call is the one that
+// moved the bill to paid; the caller then runs onPaid, so concurrent payments
+// settling the same bill publish bill.paid once.
+func (u *billUsecase) syncStatus(ctx context.Context, userID, billID uint, now time.Time) (bill entity.Bill, paid bool, err error) {
+	bill, err = u.billRepo.FindByID(ctx, billID, userID)
+	if err != nil {
+		return entity.Bill{}, false, err
+	}
+
+	status := deriveBillStatus(bill.Amount, bill.PaidAmount, bill.DueDate, now)
+	if status == bill.Status {
+		return bill, false, nil
+	}
+
+	changed, err := u.billRepo.UpdateStatus(ctx, bill.ID, status)
+	if err != nil {
+		return entity.Bill{}, false, err
+	}
+
+	bill.Status = status
+	return bill, changed && status == entity.BillStatusPaid, nil
+}
+
+// onPaid runs the follow-up work for a bill that has just become fully paid.
+func (u *billUsecase) onPaid(ctx context.Context, bill entity.Bill, now time.Time) {
+	u.events.PublishBillEvent(ctx, entity.BillEventPaid, bill)
+	if err := u.generator.generateAfter(ctx, bill, now); err != nil {
+		utils.LogErrorContext(ctx, "BILL_USECASE_GENERATE_NEXT", err)
+	}
+}
+
+// deriveBillStatus computes a bill's status from the amount paid towards it
+// and its due date: fully paid bills are paid, past-due bills with a balance
+// are overdue, and the rest are partially paid or unpaid.
+func deriveBillStatus(amount, paid entity.Money, dueDate, now time.Time) string {
+	switch {
+	case paid > 0 && paid >= amount:
+		return entity.BillStatusPaid
+	case dueDate.Before(startOfDay(now)):
+		return entity.BillStatusOverdue
+	case paid > 0:
+		return entity.BillStatusPartiallyPaid
+	}
+	return entity.BillStatusUnpaid
+}
+
+func parsePaidAt(value string) (time.Time, error) {
+	if paidAt, err := time.Parse(time.RFC3339, value); err == nil {
+		return paidAt, nil
+	}
+	if paidAt, err := time.Parse("2006-01-02", value); err == nil {
+		return paidAt, nil
+	}
+	return time.Time{}, errInvalidPaidAt
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
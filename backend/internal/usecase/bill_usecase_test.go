@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+)
+
+// fakeTransactor runs the unit of work directly and counts how it ended.
+type fakeTransactor struct {
+	committed, rolledBack int
+}
+
+func (t *fakeTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		t.rolledBack++
+		return err
+	}
+	t.committed++
+	return nil
+}
+
+type paymentBillRepo struct {
+	repository.BillRepository
+	bill          entity.Bill
+	statusChanged bool
+	future        []entity.Bill
+	deleteErr     error
+}
+
+func (r *paymentBillRepo) FindByID(_ context.Context, id, userID uint) (entity.Bill, error) {
+	return r.bill, nil
+}
+
+func (r *paymentBillRepo) UpdateStatus(_ context.Context, id uint, status string) (bool, error) {
+	return r.statusChanged, nil
+}
+
+func (r *paymentBillRepo) FindOccurrencesAfter(context.Context, uint, int) ([]entity.Bill, error) {
+	return r.future, nil
+}
+
+func (r *paymentBillRepo) DeleteOccurrencesFrom(context.Context, uint, int) error {
+	return nil
+}
+
+func (r *paymentBillRepo) Delete(context.Context, uint, uint) error {
+	return r.deleteErr
+}
+
+type fakePaymentRepo struct {
+	repository.PaymentRepository
+	onCreate func()
+}
+
+func (r *fakePaymentRepo) Create(_ context.Context, payment entity.Payment) (entity.Payment, error) {
+	r.onCreate()
+	return payment, nil
+}
+
+func TestRecordPaymentPublishesPaidOnce(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusChanged bool
+		wantEvents    []string
+	}{
+		{name: "this payment settled the bill", statusChanged: true, wantEvents: []string{entity.BillEventPaid}},
+		{name: "a concurrent payment settled it first", statusChanged: false, wantEvents: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bills := &paymentBillRepo{
+				bill:          entity.Bill{ID: 5, UserID: 1, Amount: 1000, PaidAmount: 400, Status: entity.BillStatusPartiallyPaid},
+				statusChanged: tt.statusChanged,
+			}
+			payments := &fakePaymentRepo{onCreate: func() { bills.bill.PaidAmount = bills.bill.Amount }}
+			events := &recordedEvents{}
+			u := NewBillUsecase(bills, nil, payments, nil, nil, &fakeTransactor{}, events)
+
+			response, err := u.RecordPayment(context.Background(), 1, 5, &dto.PaymentCreateRequest{Amount: 600})
+			if err != nil {
+				t.Fatalf("RecordPayment: %v", err)
+			}
+			if response.Bill.Status != entity.BillStatusPaid {
+				t.Errorf("bill status = %q, want paid", response.Bill.Status)
+			}
+			if !reflect.DeepEqual(events.events, tt.wantEvents) {
+				t.Errorf("published %v, want %v", events.events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestDeleteFutureOccurrencesRollsBack(t *testing.T) {
+	recurrenceID, index := uint(7), 2
+	bills := &paymentBillRepo{
+		bill:      entity.Bill{ID: 5, UserID: 3, RecurrenceID: &recurrenceID, OccurrenceIndex: &index},
+		future:    []entity.Bill{{ID: 6, UserID: 3}},
+		deleteErr: errors.New("connection reset"),
+	}
+	recurrences := &fakeRecurrenceRepo{recurrence: entity.Recurrence{ID: 7, UserID: 3, Active: true}}
+	tx := &fakeTransactor{}
+	events := &recordedEvents{}
+	u := NewBillUsecase(bills, recurrences, nil, nil, nil, tx, events)
+
+	err := u.DeleteBill(context.Background(), 3, 5, dto.EditScopeFuture)
+	if !errors.Is(err, bills.deleteErr) {
+		t.Fatalf("DeleteBill error = %v, want %v", err, bills.deleteErr)
+	}
+	if tx.rolledBack != 1 || tx.committed != 0 {
+		t.Errorf("transaction committed %d and rolled back %d times, want a single rollback", tx.committed, tx.rolledBack)
+	}
+	if len(events.events) != 0 {
+		t.Errorf("published %v for a delete that was rolled back", events.events)
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"math"
+	"time"
+)
+
+type BudgetUsecase interface {
+	CreateBudget(ctx context.Context, userID uint, req *dto.BudgetCreateRequest) (entity.Budget, error)
+	UpdateBudget(ctx context.Context, userID, budgetID uint, req *dto.BudgetUpdateRequest) (entity.Budget, error)
+	DeleteBudget(ctx context.Context, userID, budgetID uint) error
+	GetBudgetStatuses(ctx context.Context, userID uint, month time.Time) ([]dto.BudgetStatusResponse, error)
+}
+
+type budgetUsecase struct {
+	budgetRepo   repository.BudgetRepository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewBudgetUsecase(budgetRepo repository.BudgetRepository, categoryRepo repository.CategoryRepository) BudgetUsecase {
+	return &budgetUsecase{budgetRepo: budgetRepo, categoryRepo: categoryRepo}
+}
+
+var errBudgetExists = apperror.Conflict("BUDGET_EXISTS", "category already has a budget")
+
+func (u *budgetUsecase) CreateBudget(ctx context.Context, userID uint, req *dto.BudgetCreateRequest) (entity.Budget, error) {
+	if _, err := u.categoryRepo.FindByID(ctx, req.CategoryID, userID); err != nil {
+		return entity.Budget{}, notFound(err, ErrCategoryNotFound)
+	}
+
+	if existing, err := u.budgetRepo.FindByCategory(ctx, userID, req.CategoryID); err == nil && existing.ID != 0 {
+		return entity.Budget{}, errBudgetExists
+	}
+
+	return u.budgetRepo.Create(ctx, entity.Budget{
+		UserID:     userID,
+		CategoryID: req.CategoryID,
+		Amount:     req.Amount,
+	})
+}
+
+func (u *budgetUsecase) UpdateBudget(ctx context.Context, userID, budgetID uint, req *dto.BudgetUpdateRequest) (entity.Budget, error) {
+	budget, err := u.budgetRepo.FindByID(ctx, budgetID, userID)
+	if err != nil {
+		return entity.Budget{}, notFound(err, ErrBudgetNotFound)
+	}
+
+	budget.Amount = req.Amount
+	return u.budgetRepo.Update(ctx, budget)
+}
+
+func (u *budgetUsecase) DeleteBudget(ctx context.Context, userID, budgetID uint) error {
+	return notFound(u.budgetRepo.Delete(ctx, budgetID, userID), ErrBudgetNotFound)
+}
+
+// GetBudgetStatuses reports spent and remaining amounts of each of the user's
+// budgets for the calendar month containing month.
+func (u *budgetUsecase) GetBudgetStatuses(ctx context.Context, userID uint, month time.Time) ([]dto.BudgetStatusResponse, error) {
+	periodStart, periodEnd := budgetPeriod(month)
+	usages, err := u.budgetRepo.GetUsage(ctx, userID, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]dto.BudgetStatusResponse, 0, len(usages))
+	for _, usage := range usages {
+		statuses = append(statuses, dto.BudgetStatusResponse{
+			ID:           usage.ID,
+			CategoryID:   usage.CategoryID,
+			CategoryName: usage.CategoryName,
+			PeriodStart:  usage.PeriodStart,
+			PeriodEnd:    usage.PeriodEnd,
+			Amount:       usage.Amount,
+			Spent:        usage.Spent,
+			Paid:         usage.Paid,
+			Remaining:    usage.Remaining(),
+			PercentUsed:  math.Round(usage.PercentUsed()*10) / 10,
+			Threshold:    usage.CrossedThreshold(),
+		})
+	}
+
+	return statuses, nil
+}
+
+// budgetPeriod returns the calendar month containing t as [start, end).
+func budgetPeriod(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 1, 0)
+}
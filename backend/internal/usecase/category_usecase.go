@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"strings"
+)
+
+type CategoryUsecase interface {
+	CreateCategory(ctx context.Context, userID uint, req *dto.CategoryRequest) (entity.Category, error)
+	GetCategories(ctx context.Context, userID uint) ([]entity.Category, error)
+	UpdateCategory(ctx context.Context, userID, categoryID uint, req *dto.CategoryRequest) (entity.Category, error)
+	DeleteCategory(ctx context.Context, userID, categoryID uint) error
+	CreateTag(ctx context.Context, userID uint, req *dto.TagRequest) (entity.Tag, error)
+	GetTags(ctx context.Context, userID uint) ([]entity.Tag, error)
+	UpdateTag(ctx context.Context, userID, tagID uint, req *dto.TagRequest) (entity.Tag, error)
+	DeleteTag(ctx context.Context, userID, tagID uint) error
+}
+
+type categoryUsecase struct {
+	categoryRepo repository.CategoryRepository
+	tagRepo      repository.TagRepository
+}
+
+func NewCategoryUsecase(categoryRepo repository.CategoryRepository, tagRepo repository.TagRepository) CategoryUsecase {
+	return &categoryUsecase{categoryRepo: categoryRepo, tagRepo: tagRepo}
+}
+
+var (
+	errCategoryExists = apperror.Conflict("CATEGORY_EXISTS", "category already exists")
+	errTagExists      = apperror.Conflict("TAG_EXISTS", "tag already exists")
+	errEmptyName      = apperror.Validation("NAME_REQUIRED", "name must not be empty")
+)
+
+func (u *categoryUsecase) CreateCategory(ctx context.Context, userID uint, req *dto.CategoryRequest) (entity.Category, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return entity.Category{}, errEmptyName
+	}
+
+	if existing, err := u.categoryRepo.FindByName(ctx, userID, name); err == nil && existing.ID != 0 {
+		return entity.Category{}, errCategoryExists
+	}
+
+	return u.categoryRepo.Create(ctx, entity.Category{
+		UserID: userID,
+		Name:   name,
+		Color:  strings.TrimSpace(req.Color),
+	})
+}
+
+func (u *categoryUsecase) GetCategories(ctx context.Context, userID uint) ([]entity.Category, error) {
+	return u.categoryRepo.FindByUserID(ctx, userID)
+}
+
+func (u *categoryUsecase) UpdateCategory(ctx context.Context, userID, categoryID uint, req *dto.CategoryRequest) (entity.Category, error) {
+	category, err := u.categoryRepo.FindByID(ctx, categoryID, userID)
+	if err != nil {
+		return entity.Category{}, notFound(err, ErrCategoryNotFound)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return entity.Category{}, errEmptyName
+	}
+
+	if existing, err := u.categoryRepo.FindByName(ctx, userID, name); err == nil && existing.ID != category.ID {
+		return entity.Category{}, errCategoryExists
+	}
+
+	category.Name = name
+	category.Color = strings.TrimSpace(req.Color)
+	return u.categoryRepo.Update(ctx, category)
+}
+
+func (u *categoryUsecase) DeleteCategory(ctx context.Context, userID, categoryID uint) error {
+	return notFound(u.categoryRepo.Delete(ctx, categoryID, userID), ErrCategoryNotFound)
+}
+
+func (u *categoryUsecase) CreateTag(ctx context.Context, userID uint, req *dto.TagRequest) (entity.Tag, error) {
+	name := normalizeTagName(req.Name)
+	if name == "" {
+		return entity.Tag{}, errEmptyName
+	}
+
+	if existing, err := u.tagRepo.FindByName(ctx, userID, name); err == nil && existing.ID != 0 {
+		return entity.Tag{}, errTagExists
+	}
+
+	return u.tagRepo.Create(ctx, entity.Tag{UserID: userID, Name: name})
+}
+
+func (u *categoryUsecase) GetTags(ctx context.Context, userID uint) ([]entity.Tag, error) {
+	return u.tagRepo.FindByUserID(ctx, userID)
+}
+
+func (u *categoryUsecase) UpdateTag(ctx context.Context, userID, tagID uint, req *dto.TagRequest) (entity.Tag, error) {
+	tag, err := u.tagRepo.FindByID(ctx, tagID, userID)
+	if err != nil {
+		return entity.Tag{}, notFound(err, ErrTagNotFound)
+	}
+
+	name := normalizeTagName(req.Name)
+	if name == "" {
+		return entity.Tag{}, errEmptyName
+	}
+
+	if existing, err := u.tagRepo.FindByName(ctx, userID, name); err == nil && existing.ID != tag.ID {
+		return entity.Tag{}, errTagExists
+	}
+
+	tag.Name = name
+	return u.tagRepo.Update(ctx, tag)
+}
+
+func (u *categoryUsecase) DeleteTag(ctx context.Context, userID, tagID uint) error {
+	return notFound(u.tagRepo.Delete(ctx, tagID, userID), ErrTagNotFound)
+}
+
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTagNames normalizes names and drops empty and duplicate entries,
+// keeping the first occurrence's position.
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"financebroke/backend/internal/apperror"
+)
+
+// Lookups of resources the user does not own fail the same way as lookups of
+// resources that do not exist.
+var (
+	ErrUserNotFound       = apperror.NotFound("USER_NOT_FOUND", "user not found")
+	ErrBillNotFound       = apperror.NotFound("BILL_NOT_FOUND", "bill not found")
+	ErrPaymentNotFound    = apperror.NotFound("PAYMENT_NOT_FOUND", "payment not found")
+	ErrRecurrenceNotFound = apperror.NotFound("RECURRENCE_NOT_FOUND", "recurrence not found")
+	ErrCategoryNotFound   = apperror.NotFound("CATEGORY_NOT_FOUND", "category not found")
+	ErrTagNotFound        = apperror.NotFound("TAG_NOT_FOUND", "tag not found")
+	ErrBudgetNotFound     = apperror.NotFound("BUDGET_NOT_FOUND", "budget not found")
+	ErrWebhookNotFound    = apperror.NotFound("WEBHOOK_NOT_FOUND", "webhook not found")
+)
+
+var (
+	// errInvalidDate is returned for dates that are not YYYY-MM-DD.
+	errInvalidDate = apperror.Validation("INVALID_DATE", "dates must be YYYY-MM-DD")
+
+	// errInvalidSchedule carries the reason a recurrence failed validation.
+	errInvalidSchedule = apperror.Validation("INVALID_RECURRENCE", "invalid recurrence")
+)
+
+// notFound replaces the sql.ErrNoRows that repositories report for missing
+// rows with the given domain error and leaves other errors alone.
+func notFound(err error, domainErr *apperror.Error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return domainErr
+	}
+	return err
+}
+
+// parseDate parses a YYYY-MM-DD request field.
+func parseDate(field, value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errInvalidDate.WithMessage("%s must be YYYY-MM-DD", field)
+	}
+	return date, nil
+}
@@ -1,77 +1,545 @@
 package usecase
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/config"
 	"financebroke/backend/internal/dto"
 	"financebroke/backend/internal/entity"
 	"financebroke/backend/internal/repository"
 	"financebroke/backend/internal/services"
+	"financebroke/backend/internal/utils"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	errUnknownChannel     = apperror.Validation("UNKNOWN_CHANNEL", "unknown notification channel")
+	errChannelUnavailable = apperror.Validation("CHANNEL_UNAVAILABLE", "notification channel is not configured on this server")
+	errChannelNotLinked   = apperror.Validation("CHANNEL_NOT_LINKED", "set an address for the channel in the notification settings first")
+	errInvalidAddress     = apperror.Validation("INVALID_CHANNEL_ADDRESS", "invalid notification channel address")
+	errAddressLinked      = apperror.Validation("CHANNEL_ADDRESS_LINKED", "the channel's address is set by linking it, not in the settings")
+	errAddressInUse       = apperror.Conflict("CHANNEL_ADDRESS_IN_USE", "the address is already used by another account")
+	errNotificationFailed = apperror.Upstream("NOTIFICATION_SEND_FAILED", "failed to send notification")
 )
 
 type NotificationUsecase interface {
-	UpdateSettings(userID uint, req *dto.NotificationSettingsRequest) (*entity.User, error)
-	TestTelegram(userID uint, req *dto.TestTelegramRequest) error
-	SendBillReminder(bill entity.Bill, user entity.User) error
+	GetSettings(ctx context.Context, userID uint) ([]dto.NotificationChannelResponse, error)
+	UpdateSettings(ctx context.Context, userID uint, req *dto.NotificationSettingsRequest) ([]dto.NotificationChannelResponse, error)
+	SendTest(ctx context.Context, userID uint, req *dto.TestNotificationRequest) error
+	SendBillReminder(ctx context.Context, bill entity.Bill, user entity.User) error
+	SendOverdueNotice(ctx context.Context, bill entity.Bill, user entity.User) error
+	SendDueReminders(ctx context.Context, now time.Time) (int, error)
+	SendOverdueNotices(ctx context.Context, now time.Time) (int, error)
+	SendBudgetAlert(ctx context.Context, usage entity.BudgetUsage, threshold int, user entity.User) error
+	SendBudgetAlerts(ctx context.Context, now time.Time) (int, error)
+	DeliverPending(ctx context.Context, now time.Time) (int, error)
+	GetHistory(ctx context.Context, userID uint, query *dto.NotificationListQuery) (*dto.NotificationListResponse, error)
+}
+
+// defaultNotificationPageSize is the page size of GET /notifications.
+const defaultNotificationPageSize = 20
+
+// ChannelErrors holds, per channel, why a notification could not be
+// queued for it.
+type ChannelErrors map[string]error
+
+func (e ChannelErrors) Error() string {
+	channels := make([]string, 0, len(e))
+	for channel := range e {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	messages := make([]string, len(channels))
+	for i, channel := range channels {
+		messages[i] = channel + ": " + e[channel].Error()
+	}
+	return strings.Join(messages, "; ")
 }
 
 type notificationUsecase struct {
-	userRepo       repository.UserRepository
-	telegramSvc    *services.TelegramService
-	emailSvc       *services.EmailService
+	userRepo   repository.UserRepository
+	billRepo   repository.BillRepository
+	budgetRepo repository.BudgetRepository
+	prefRepo   repository.NotificationPreferenceRepository
+	notifRepo  repository.NotificationRepository
+	notifiers  *services.NotifierRegistry
+	events     BillEventPublisher
+	cfg        config.NotifyConfig
 }
 
 func NewNotificationUsecase(
 	userRepo repository.UserRepository,
-	telegramSvc *services.TelegramService,
-	emailSvc *services.EmailService,
+	billRepo repository.BillRepository,
+	budgetRepo repository.BudgetRepository,
+	prefRepo repository.NotificationPreferenceRepository,
+	notifRepo repository.NotificationRepository,
+	notifiers *services.NotifierRegistry,
+	events BillEventPublisher,
+	cfg config.NotifyConfig,
 ) NotificationUsecase {
 	return &notificationUsecase{
-		userRepo:    userRepo,
-		telegramSvc: telegramSvc,
-		emailSvc:    emailSvc,
+		userRepo:   userRepo,
+		billRepo:   billRepo,
+		budgetRepo: budgetRepo,
+		prefRepo:   prefRepo,
+		notifRepo:  notifRepo,
+		notifiers:  notifiers,
+		events:     events,
+		cfg:        cfg,
 	}
 }
 
-func (u *notificationUsecase) UpdateSettings(userID uint, req *dto.NotificationSettingsRequest) (*entity.User, error) {
-	chatID := ""
-	if req.TelegramChatID != "" {
-		chatID = req.TelegramChatID
+// GetSettings lists every channel the server offers with the user's setting
+// for it.
+func (u *notificationUsecase) GetSettings(ctx context.Context, userID uint) ([]dto.NotificationChannelResponse, error) {
+	prefs, err := u.preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	notifiers := u.notifiers.Notifiers()
+	settings := make([]dto.NotificationChannelResponse, len(notifiers))
+	for i, notifier := range notifiers {
+		pref := prefs[notifier.Channel()]
+		settings[i] = dto.NotificationChannelResponse{
+			Channel:   pref.Channel,
+			Enabled:   pref.Enabled,
+			Address:   pref.Address,
+			Available: notifier.IsConfigured(),
+		}
 	}
+	return settings, nil
+}
 
-	user, err := u.userRepo.UpdateNotificationSettings(userID, chatID, req.EmailNotify, req.TelegramNotify)
+// UpdateSettings saves the settings of the channels in the request. Channels
+// left out keep their current settings. Linked channels keep their address,
+// and an address can only belong to one account.
+func (u *notificationUsecase) UpdateSettings(ctx context.Context, userID uint, req *dto.NotificationSettingsRequest) ([]dto.NotificationChannelResponse, error) {
+	current, err := u.preferences(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &user, nil
+	prefs := make([]entity.NotificationPreference, 0, len(req.Channels))
+	for _, setting := range req.Channels {
+		notifier, ok := u.notifiers.Get(setting.Channel)
+		if !ok {
+			return nil, errUnknownChannel.WithMessage("unknown notification channel %q", setting.Channel)
+		}
+
+		address := strings.TrimSpace(setting.Address)
+		if linker, ok := notifier.(services.AddressLinker); ok && linker.LinksAddress() {
+			if address != "" && address != current[setting.Channel].Address {
+				return nil, errAddressLinked.WithMessage("%s: link the channel from the app instead of entering an address", setting.Channel)
+			}
+			address = current[setting.Channel].Address
+		}
+		if validator, ok := notifier.(services.AddressValidator); ok && address != "" {
+			if err := validator.ValidateAddress(address); err != nil {
+				return nil, errInvalidAddress.WithMessage("%s: %v", setting.Channel, err)
+			}
+		}
+		if address != "" && address != current[setting.Channel].Address {
+			owner, err := u.prefRepo.FindUserByAddress(ctx, setting.Channel, address)
+			if err == nil && owner != userID {
+				return nil, errAddressInUse.WithMessage("%s: the address is already used by another account", setting.Channel)
+			}
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+		}
+
+		prefs = append(prefs, entity.NotificationPreference{
+			Channel: setting.Channel,
+			Enabled: setting.Enabled,
+			Address: address,
+		})
+	}
+
+	if err := u.prefRepo.Save(ctx, userID, prefs); err != nil {
+		return nil, err
+	}
+
+	return u.GetSettings(ctx, userID)
 }
 
-func (u *notificationUsecase) TestTelegram(userID uint, req *dto.TestTelegramRequest) error {
-	user, err := u.userRepo.FindByID(userID)
+// SendTest sends a message over one channel, whether or not the user has
+// enabled it, so they can check their settings.
+func (u *notificationUsecase) SendTest(ctx context.Context, userID uint, req *dto.TestNotificationRequest) error {
+	notifier, ok := u.notifiers.Get(req.Channel)
+	if !ok {
+		return errUnknownChannel.WithMessage("unknown notification channel %q", req.Channel)
+	}
+	if !notifier.IsConfigured() {
+		return errChannelUnavailable
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return err
+		return notFound(err, ErrUserNotFound)
 	}
 
-	if user.TelegramChatID == "" {
+	prefs, err := u.preferences(ctx, userID)
+	if err != nil {
 		return err
 	}
 
-	return u.telegramSvc.SendTestMessage(user.TelegramChatID, req.Message)
+	to, ok := notifier.Recipient(user, prefs[req.Channel])
+	if !ok {
+		return errChannelNotLinked
+	}
+
+	n := entity.Notification{Kind: entity.NotificationTest, User: user, Text: req.Message}
+	if _, err := notifier.Send(ctx, to, n); err != nil {
+		return errNotificationFailed.Wrap(err)
+	}
+	return nil
 }
 
-func (u *notificationUsecase) SendBillReminder(bill entity.Bill, user entity.User) error {
-	if user.EmailNotify {
-		err := u.emailSvc.SendReminder(&bill, &user)
-		if err != nil {
-			return err
+// preferences returns the user's setting for every registered channel,
+// falling back to the channel's default where they have none.
+func (u *notificationUsecase) preferences(ctx context.Context, userID uint) (map[string]entity.NotificationPreference, error) {
+	saved, err := u.prefRepo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make(map[string]entity.NotificationPreference, len(saved))
+	for _, notifier := range u.notifiers.Notifiers() {
+		prefs[notifier.Channel()] = u.notifiers.DefaultPreference(notifier.Channel())
+	}
+	for _, pref := range saved {
+		if _, ok := prefs[pref.Channel]; ok {
+			prefs[pref.Channel] = pref
 		}
 	}
+	return prefs, nil
+}
 
-	if user.TelegramNotify && user.TelegramChatID != "" {
-		err := u.telegramSvc.SendReminder(&bill, &user)
+// notify queues n for every channel the user has enabled and can be reached
+// on; workers deliver it later. A bill only gets one notification of each kind
+// per channel and reference, so queueing the same one again is a no-op. A
+// channel that cannot be queued does not stop the others: the failures are
+// logged, and ChannelErrors is returned only when no channel was queued.
+func (u *notificationUsecase) notify(ctx context.Context, n entity.Notification, reference string) error {
+	// Accounts waiting out their deletion grace period are not notified
+	if n.User.PendingDeletion() {
+		return nil
+	}
+
+	prefs, err := u.preferences(ctx, n.User.ID)
+	if err != nil {
+		return err
+	}
+
+	var billID *uint
+	if n.Bill != nil {
+		billID = &n.Bill.ID
+	}
+
+	queued := 0
+	failed := ChannelErrors{}
+	for _, notifier := range u.notifiers.Notifiers() {
+		pref := prefs[notifier.Channel()]
+		if !pref.Enabled || !notifier.IsConfigured() {
+			continue
+		}
+
+		to, ok := notifier.Recipient(n.User, pref)
+		if !ok {
+			continue
+		}
+
+		_, err := u.notifRepo.Enqueue(ctx, entity.QueuedNotification{
+			UserID:        n.User.ID,
+			Channel:       notifier.Channel(),
+			Kind:          n.Kind,
+			BillID:        billID,
+			Reference:     reference,
+			Recipient:     to,
+			Payload:       n,
+			MaxAttempts:   u.cfg.MaxAttempts,
+			NextAttemptAt: time.Now(),
+		})
 		if err != nil {
-			return err
+			failed[notifier.Channel()] = err
+			continue
 		}
+		queued++
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	if queued == 0 {
+		return failed
 	}
 
+	utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_CHANNEL", failed)
 	return nil
-}
\ No newline at end of file
+}
+
+// SendBillReminder queues a reminder for the bill. Moving the due date or the
+// reminder window, or snoozing the reminder, arms a new reminder.
+func (u *notificationUsecase) SendBillReminder(ctx context.Context, bill entity.Bill, user entity.User) error {
+	reference := fmt.Sprintf("%s/%d", bill.DueDate.Format("2006-01-02"), bill.RemindBefore) + snoozeReference(bill)
+	return u.notify(ctx, entity.Notification{Kind: entity.NotificationBillReminder, User: user, Bill: &bill}, reference)
+}
+
+func (u *notificationUsecase) SendOverdueNotice(ctx context.Context, bill entity.Bill, user entity.User) error {
+	reference := bill.DueDate.Format("2006-01-02") + snoozeReference(bill)
+	return u.notify(ctx, entity.Notification{Kind: entity.NotificationBillOverdue, User: user, Bill: &bill}, reference)
+}
+
+// snoozeReference tells a snoozed reminder apart from the one before it.
+func snoozeReference(bill entity.Bill) string {
+	if bill.ReminderSnoozedUntil == nil {
+		return ""
+	}
+	return "/snoozed-" + bill.ReminderSnoozedUntil.UTC().Format(time.RFC3339)
+}
+
+func (u *notificationUsecase) SendBudgetAlert(ctx context.Context, usage entity.BudgetUsage, threshold int, user entity.User) error {
+	reference := fmt.Sprintf("%d/%s/%d", usage.ID, usage.PeriodStart.Format("2006-01"), threshold)
+	return u.notify(ctx, entity.Notification{Kind: entity.NotificationBudgetAlert, User: user, Budget: &usage, Threshold: threshold}, reference)
+}
+
+// DeliverPending sends every queued notification due at now, using up to
+// cfg.Workers sends at a time, and returns how many were delivered. It
+// returns once the queue has nothing due or ctx is cancelled.
+func (u *notificationUsecase) DeliverPending(ctx context.Context, now time.Time) (int, error) {
+	delivered, err := drainQueue(ctx, u.cfg.Workers,
+		func(ctx context.Context) (entity.QueuedNotification, error) {
+			// Hold the notification for longer than a send may take, so no
+			// other worker picks it up meanwhile
+			return u.notifRepo.Claim(ctx, now, time.Now().Add(2*u.cfg.SendTimeout))
+		},
+		u.deliver,
+	)
+
+	if delivered > 0 {
+		utils.FromContext(ctx).Info("[USECASE] Notifications delivered", map[string]interface{}{
+			"sent": delivered,
+		})
+	}
+	return delivered, err
+}
+
+// deliver sends one claimed notification and records the outcome. Failed
+// sends are retried with exponential backoff until MaxAttempts is reached;
+// sends cut short by ctx being cancelled are put back without counting an
+// attempt. It reports whether the notification was sent.
+func (u *notificationUsecase) deliver(ctx context.Context, n entity.QueuedNotification) bool {
+	// The outcome must be recorded even when ctx has been cancelled
+	record := context.WithoutCancel(ctx)
+
+	var response string
+	var err error
+	notifier, ok := u.notifiers.Get(n.Channel)
+	if !ok || !notifier.IsConfigured() {
+		err = fmt.Errorf("channel %s is not available", n.Channel)
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, u.cfg.SendTimeout)
+		response, err = notifier.Send(sendCtx, n.Recipient, n.Payload)
+		cancel()
+	}
+
+	if err == nil {
+		if err := u.notifRepo.MarkSent(record, n.ID, response, time.Now()); err != nil {
+			utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_MARK_SENT", err)
+		}
+		return true
+	}
+
+	if ctx.Err() != nil {
+		if err := u.notifRepo.Release(record, n.ID); err != nil {
+			utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_RELEASE", err)
+		}
+		return false
+	}
+
+	var retryAt *time.Time
+	attempts := n.Attempts + 1
+	if attempts < n.MaxAttempts {
+		next := time.Now().Add(backoff(u.cfg.RetryDelay, u.cfg.MaxRetryDelay, attempts))
+		retryAt = &next
+	}
+
+	utils.FromContext(ctx).Warn("[USECASE] Notification delivery failed", map[string]interface{}{
+		"notification_id": n.ID,
+		"channel":         n.Channel,
+		"attempts":        attempts,
+		"error":           err.Error(),
+		"will_retry":      retryAt != nil,
+	})
+	if err := u.notifRepo.RecordFailure(record, n.ID, err.Error(), response, retryAt); err != nil {
+		utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_RECORD_FAILURE", err)
+	}
+	return false
+}
+
+// GetHistory lists the user's notifications, newest first, with their
+// delivery status.
+func (u *notificationUsecase) GetHistory(ctx context.Context, userID uint, query *dto.NotificationListQuery) (*dto.NotificationListResponse, error) {
+	filter := repository.NotificationFilter{
+		UserID: userID,
+		Status: query.Status,
+		Limit:  defaultNotificationPageSize,
+	}
+	if query.PageSize > 0 {
+		filter.Limit = query.PageSize
+	}
+	if query.Page > 1 {
+		filter.Offset = (query.Page - 1) * filter.Limit
+	}
+
+	notifications, total, err := u.notifRepo.FindPage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []entity.QueuedNotification{}
+	}
+
+	return &dto.NotificationListResponse{
+		Data: notifications,
+		Pagination: dto.Pagination{
+			Page:       filter.Offset/filter.Limit + 1,
+			PageSize:   filter.Limit,
+			Total:      total,
+			TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+		},
+	}, nil
+}
+
+// SendDueReminders queues reminders for every bill whose reminder window has
+// opened at now and marks each one as reminded so it is only sent once. Bills
+// whose reminders cannot be queued are left unmarked and retried on the next
+// run.
+func (u *notificationUsecase) SendDueReminders(ctx context.Context, now time.Time) (int, error) {
+	bills, err := u.billRepo.FindDueForReminder(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := u.notifyBills(ctx, "REMINDER", entity.BillEventDueSoon, bills, now, u.SendBillReminder, u.billRepo.MarkReminded)
+
+	utils.FromContext(ctx).Info("[USECASE] Bill reminders processed", map[string]interface{}{
+		"due":  len(bills),
+		"sent": sent,
+	})
+	return sent, nil
+}
+
+// SendOverdueNotices queues notices to owners of overdue bills that have not
+// been told yet, marking each bill so the notice is not repeated.
+func (u *notificationUsecase) SendOverdueNotices(ctx context.Context, now time.Time) (int, error) {
+	bills, err := u.billRepo.FindOverdueUnnotified(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := u.notifyBills(ctx, "OVERDUE", entity.BillEventOverdue, bills, now, u.SendOverdueNotice, u.billRepo.MarkOverdueNotified)
+
+	utils.FromContext(ctx).Info("[USECASE] Overdue notices processed", map[string]interface{}{
+		"overdue": len(bills),
+		"sent":    sent,
+	})
+	return sent, nil
+}
+
+// SendBudgetAlerts warns users whose bills this month have reached 80% or
+// 100% of a category budget. Each threshold is announced once per month; a
+// budget that jumps straight past 100% only gets the exceeded alert.
+func (u *notificationUsecase) SendBudgetAlerts(ctx context.Context, now time.Time) (int, error) {
+	periodStart, periodEnd := budgetPeriod(now)
+	usages, err := u.budgetRepo.FindAllUsage(ctx, periodStart, periodEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	users := make(map[uint]entity.User)
+	sent := 0
+	for _, usage := range usages {
+		threshold := usage.CrossedThreshold()
+		if threshold <= usage.AlertedThreshold {
+			continue
+		}
+
+		user, ok := users[usage.UserID]
+		if !ok {
+			user, err = u.userRepo.FindByID(ctx, usage.UserID)
+			if err != nil {
+				utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_BUDGET_USER", err)
+				continue
+			}
+			users[usage.UserID] = user
+		}
+
+		if err := u.SendBudgetAlert(ctx, usage, threshold, user); err != nil {
+			utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_BUDGET_SEND", err)
+			continue
+		}
+
+		if err := u.budgetRepo.RecordAlert(ctx, usage.ID, periodStart, threshold, now); err != nil {
+			utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_BUDGET_MARK", err)
+			continue
+		}
+
+		sent++
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Budget alerts processed", map[string]interface{}{
+		"budgets": len(usages),
+		"sent":    sent,
+	})
+	return sent, nil
+}
+
+// notifyBills sends and marks each bill, then publishes event for it to the
+// owner's webhooks.
+func (u *notificationUsecase) notifyBills(
+	ctx context.Context,
+	kind string,
+	event string,
+	bills []entity.Bill,
+	now time.Time,
+	send func(ctx context.Context, bill entity.Bill, user entity.User) error,
+	mark func(ctx context.Context, id uint, at time.Time) error,
+) int {
+	users := make(map[uint]entity.User)
+	sent := 0
+	for _, bill := range bills {
+		user, ok := users[bill.UserID]
+		if !ok {
+			var err error
+			user, err = u.userRepo.FindByID(ctx, bill.UserID)
+			if err != nil {
+				utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_"+kind+"_USER", err)
+				continue
+			}
+			users[bill.UserID] = user
+		}
+
+		if err := send(ctx, bill, user); err != nil {
+			utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_"+kind+"_SEND", err)
+			continue
+		}
+
+		if err := mark(ctx, bill.ID, now); err != nil {
+			utils.LogErrorContext(ctx, "NOTIFICATION_USECASE_"+kind+"_MARK", err)
+			continue
+		}
+
+		u.events.PublishBillEvent(ctx, event, bill)
+		sent++
+	}
+
+	return sent
+}
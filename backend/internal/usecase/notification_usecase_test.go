@@ -0,0 +1,362 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/services"
+)
+
+type fakeNotifier struct {
+	channel string
+}
+
+func (n fakeNotifier) Channel() string    { return n.channel }
+func (n fakeNotifier) IsConfigured() bool { return true }
+
+func (n fakeNotifier) Recipient(user entity.User, pref entity.NotificationPreference) (string, bool) {
+	if pref.Address != "" {
+		return pref.Address, true
+	}
+	return user.Email, user.Email != ""
+}
+
+func (n fakeNotifier) Send(context.Context, string, entity.Notification) (string, error) {
+	return "ok", nil
+}
+
+type reminderBillRepo struct {
+	repository.BillRepository
+	due       []entity.Bill
+	queriedAt time.Time
+	reminded  map[uint]time.Time
+}
+
+func (r *reminderBillRepo) FindDueForReminder(_ context.Context, now time.Time) ([]entity.Bill, error) {
+	r.queriedAt = now
+	return r.due, nil
+}
+
+func (r *reminderBillRepo) MarkReminded(_ context.Context, id uint, at time.Time) error {
+	r.reminded[id] = at
+	return nil
+}
+
+type reminderUserRepo struct {
+	repository.UserRepository
+	users map[uint]entity.User
+}
+
+func (r *reminderUserRepo) FindByID(_ context.Context, id uint) (entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return entity.User{}, errors.New("no such user")
+	}
+	return user, nil
+}
+
+type reminderPrefRepo struct {
+	repository.NotificationPreferenceRepository
+}
+
+func (reminderPrefRepo) FindByUser(context.Context, uint) ([]entity.NotificationPreference, error) {
+	return nil, nil
+}
+
+type reminderNotifRepo struct {
+	repository.NotificationRepository
+	fail   map[uint]bool
+	queued []entity.QueuedNotification
+}
+
+func (r *reminderNotifRepo) Enqueue(_ context.Context, n entity.QueuedNotification) (bool, error) {
+	if r.fail[n.UserID] {
+		return false, errors.New("queue unavailable")
+	}
+	r.queued = append(r.queued, n)
+	return true, nil
+}
+
+type recordedEvents struct {
+	events []string
+}
+
+func (p *recordedEvents) PublishBillEvent(_ context.Context, event string, bill entity.Bill) {
+	p.events = append(p.events, event)
+}
+
+func TestSendDueReminders(t *testing.T) {
+	now := time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)
+	deleting := now.Add(-time.Hour)
+
+	users := map[uint]entity.User{
+		1: {ID: 1, Email: "ann@example.com"},
+		2: {ID: 2, Email: "bob@example.com", DeletionScheduledAt: &deleting},
+		3: {ID: 3, Email: "cat@example.com"},
+	}
+
+	tests := []struct {
+		name         string
+		due          []entity.Bill
+		failEnqueue  map[uint]bool
+		wantSent     int
+		wantReminded []uint
+		wantQueued   int
+	}{
+		{
+			name:         "reachable owner is queued and the bill marked",
+			due:          []entity.Bill{{ID: 10, UserID: 1, DueDate: now.AddDate(0, 0, 3)}},
+			wantSent:     1,
+			wantReminded: []uint{10},
+			wantQueued:   1,
+		},
+		{
+			name:         "owner pending deletion is skipped but the bill marked",
+			due:          []entity.Bill{{ID: 20, UserID: 2, DueDate: now.AddDate(0, 0, 3)}},
+			wantSent:     1,
+			wantReminded: []uint{20},
+			wantQueued:   0,
+		},
+		{
+			name: "bill that cannot be queued is left for the next run",
+			due: []entity.Bill{
+				{ID: 30, UserID: 3, DueDate: now.AddDate(0, 0, 1)},
+				{ID: 31, UserID: 1, DueDate: now.AddDate(0, 0, 1)},
+			},
+			failEnqueue:  map[uint]bool{3: true},
+			wantSent:     1,
+			wantReminded: []uint{31},
+			wantQueued:   1,
+		},
+		{
+			name:         "bill of an unknown owner is skipped",
+			due:          []entity.Bill{{ID: 40, UserID: 99, DueDate: now}},
+			wantSent:     0,
+			wantReminded: []uint{},
+			wantQueued:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bills := &reminderBillRepo{due: tt.due, reminded: map[uint]time.Time{}}
+			notifs := &reminderNotifRepo{fail: tt.failEnqueue}
+			events := &recordedEvents{}
+
+			notifiers := services.NewNotifierRegistry()
+			notifiers.Register(fakeNotifier{channel: "email"}, true)
+
+			u := NewNotificationUsecase(
+				&reminderUserRepo{users: users}, bills, nil,
+				reminderPrefRepo{}, notifs, notifiers, events,
+				config.NotifyConfig{MaxAttempts: 3},
+			)
+
+			sent, err := u.SendDueReminders(context.Background(), now)
+			if err != nil {
+				t.Fatalf("SendDueReminders: %v", err)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("sent = %d, want %d", sent, tt.wantSent)
+			}
+			if !bills.queriedAt.Equal(now) {
+				t.Errorf("due bills looked up at %v, want %v", bills.queriedAt, now)
+			}
+
+			reminded := []uint{}
+			for _, bill := range tt.due {
+				at, ok := bills.reminded[bill.ID]
+				if !ok {
+					continue
+				}
+				if !at.Equal(now) {
+					t.Errorf("bill %d marked at %v, want %v", bill.ID, at, now)
+				}
+				reminded = append(reminded, bill.ID)
+			}
+			if !reflect.DeepEqual(reminded, tt.wantReminded) {
+				t.Errorf("reminded bills %v, want %v", reminded, tt.wantReminded)
+			}
+
+			if len(notifs.queued) != tt.wantQueued {
+				t.Errorf("queued %d notifications, want %d", len(notifs.queued), tt.wantQueued)
+			}
+			for _, n := range notifs.queued {
+				if n.Kind != entity.NotificationBillReminder {
+					t.Errorf("queued kind %q, want %q", n.Kind, entity.NotificationBillReminder)
+				}
+			}
+			if len(events.events) != tt.wantSent {
+				t.Errorf("published %d events, want %d", len(events.events), tt.wantSent)
+			}
+		})
+	}
+}
+
+// fakePrefRepo keeps notification settings by user and channel.
+type fakePrefRepo struct {
+	repository.NotificationPreferenceRepository
+	mu    sync.Mutex
+	prefs map[uint]map[string]entity.NotificationPreference
+	saves int
+}
+
+func newFakePrefRepo() *fakePrefRepo {
+	return &fakePrefRepo{prefs: map[uint]map[string]entity.NotificationPreference{}}
+}
+
+func (r *fakePrefRepo) set(userID uint, pref entity.NotificationPreference) {
+	if r.prefs[userID] == nil {
+		r.prefs[userID] = map[string]entity.NotificationPreference{}
+	}
+	r.prefs[userID][pref.Channel] = pref
+}
+
+func (r *fakePrefRepo) get(userID uint, channel string) entity.NotificationPreference {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.prefs[userID][channel]
+}
+
+func (r *fakePrefRepo) FindByUser(_ context.Context, userID uint) ([]entity.NotificationPreference, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var prefs []entity.NotificationPreference
+	for _, pref := range r.prefs[userID] {
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
+
+func (r *fakePrefRepo) Save(_ context.Context, userID uint, prefs []entity.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saves++
+	for _, pref := range prefs {
+		r.set(userID, pref)
+	}
+	return nil
+}
+
+func (r *fakePrefRepo) SaveExclusive(_ context.Context, userID uint, pref entity.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for other, prefs := range r.prefs {
+		if existing, ok := prefs[pref.Channel]; ok && other != userID && existing.Address == pref.Address {
+			prefs[pref.Channel] = entity.NotificationPreference{Channel: pref.Channel}
+		}
+	}
+	r.set(userID, pref)
+	return nil
+}
+
+func (r *fakePrefRepo) FindUserByAddress(_ context.Context, channel, address string) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for userID, prefs := range r.prefs {
+		if pref, ok := prefs[channel]; ok && pref.Address == address {
+			return userID, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+func TestUpdateSettingsAddresses(t *testing.T) {
+	const userID, otherID = 1, 2
+
+	tests := []struct {
+		name        string
+		linked      string
+		setting     dto.NotificationChannelSetting
+		wantErr     error
+		wantAddress string
+	}{
+		{
+			name:    "telegram chat cannot be entered",
+			setting: dto.NotificationChannelSetting{Channel: "telegram", Enabled: true, Address: "5550001"},
+			wantErr: errAddressLinked,
+		},
+		{
+			name:    "linked telegram chat cannot be replaced",
+			linked:  "5550001",
+			setting: dto.NotificationChannelSetting{Channel: "telegram", Enabled: true, Address: "5550002"},
+			wantErr: errAddressLinked,
+		},
+		{
+			name:        "linked telegram chat can be sent back unchanged",
+			linked:      "5550001",
+			setting:     dto.NotificationChannelSetting{Channel: "telegram", Enabled: true, Address: " 5550001 "},
+			wantAddress: "5550001",
+		},
+		{
+			name:        "linked telegram chat is kept when the address is left out",
+			linked:      "5550001",
+			setting:     dto.NotificationChannelSetting{Channel: "telegram", Enabled: false},
+			wantAddress: "5550001",
+		},
+		{
+			name:    "address of another account is refused",
+			setting: dto.NotificationChannelSetting{Channel: "chat", Enabled: true, Address: "taken"},
+			wantErr: errAddressInUse,
+		},
+		{
+			name:        "unused address is saved",
+			setting:     dto.NotificationChannelSetting{Channel: "chat", Enabled: true, Address: "free"},
+			wantAddress: "free",
+		},
+		{
+			name:    "unknown channel",
+			setting: dto.NotificationChannelSetting{Channel: "pager", Enabled: true},
+			wantErr: errUnknownChannel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := newFakePrefRepo()
+			prefs.set(otherID, entity.NotificationPreference{Channel: "chat", Enabled: true, Address: "taken"})
+			if tt.linked != "" {
+				prefs.set(userID, entity.NotificationPreference{Channel: "telegram", Enabled: true, Address: tt.linked})
+			}
+
+			notifiers := services.NewNotifierRegistry()
+			notifiers.Register(services.NewTelegramService(config.TelegramConfig{BotToken: "123:abc"}), false)
+			notifiers.Register(fakeNotifier{channel: "chat"}, false)
+
+			u := NewNotificationUsecase(nil, nil, nil, prefs, nil, notifiers, nil, config.NotifyConfig{})
+
+			_, err := u.UpdateSettings(context.Background(), userID, &dto.NotificationSettingsRequest{
+				Channels: []dto.NotificationChannelSetting{tt.setting},
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateSettings error = %v, want %v", err, tt.wantErr)
+				}
+				if prefs.saves != 0 {
+					t.Error("settings were saved despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateSettings: %v", err)
+			}
+
+			got := prefs.get(userID, tt.setting.Channel)
+			if got.Address != tt.wantAddress || got.Enabled != tt.setting.Enabled {
+				t.Errorf("saved %+v, want address %q enabled %v", got, tt.wantAddress, tt.setting.Enabled)
+			}
+			if other := prefs.get(otherID, "chat"); other.Address != "taken" {
+				t.Errorf("other account's address changed to %q", other.Address)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// drainQueue runs workers that each claim an item and process it, until claim
+// reports sql.ErrNoRows or ctx is cancelled. It returns how many items process
+// reported as done, and the claim errors that stopped workers early.
+func drainQueue[T any](
+	ctx context.Context,
+	workers int,
+	claim func(ctx context.Context) (T, error),
+	process func(ctx context.Context, item T) bool,
+) (int, error) {
+	var done atomic.Int64
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				item, err := claim(ctx)
+				if errors.Is(err, sql.ErrNoRows) {
+					return
+				}
+				if err != nil {
+					errs[worker] = err
+					return
+				}
+
+				if process(ctx, item) {
+					done.Add(1)
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	return int(done.Load()), errors.Join(errs...)
+}
+
+// backoff is the wait after the given number of failed attempts: base,
+// doubled for each further attempt, capped at max.
+func backoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
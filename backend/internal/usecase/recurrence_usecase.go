@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/utils"
+	"time"
+)
+
+// maxCatchUpOccurrences bounds how many missed occurrences a single
+// recurrence may generate in one run, e.g. after a long downtime.
+const maxCatchUpOccurrences = 366
+
+type RecurrenceUsecase interface {
+	GetRecurrences(ctx context.Context, userID uint) ([]entity.Recurrence, error)
+	GetRecurrence(ctx context.Context, userID, recurrenceID uint) (entity.Recurrence, error)
+	UpdateRecurrence(ctx context.Context, userID, recurrenceID uint, req *dto.RecurrenceUpdateRequest) (entity.Recurrence, error)
+	StopRecurrence(ctx context.Context, userID, recurrenceID uint) error
+	GenerateDueOccurrences(ctx context.Context, now time.Time) (int, error)
+}
+
+type recurrenceUsecase struct {
+	recurrenceRepo repository.RecurrenceRepository
+	generator      occurrenceGenerator
+}
+
+func NewRecurrenceUsecase(recurrenceRepo repository.RecurrenceRepository, billRepo repository.BillRepository, events BillEventPublisher) RecurrenceUsecase {
+	return &recurrenceUsecase{
+		recurrenceRepo: recurrenceRepo,
+		generator:      occurrenceGenerator{billRepo: billRepo, recurrenceRepo: recurrenceRepo, events: events},
+	}
+}
+
+func (u *recurrenceUsecase) GetRecurrences(ctx context.Context, userID uint) ([]entity.Recurrence, error) {
+	return u.recurrenceRepo.FindByUserID(ctx, userID)
+}
+
+func (u *recurrenceUsecase) GetRecurrence(ctx context.Context, userID, recurrenceID uint) (entity.Recurrence, error) {
+	recurrence, err := u.recurrenceRepo.FindByID(ctx, recurrenceID, userID)
+	return recurrence, notFound(err, ErrRecurrenceNotFound)
+}
+
+func (u *recurrenceUsecase) UpdateRecurrence(ctx context.Context, userID, recurrenceID uint, req *dto.RecurrenceUpdateRequest) (entity.Recurrence, error) {
+	recurrence, err := u.GetRecurrence(ctx, userID, recurrenceID)
+	if err != nil {
+		return entity.Recurrence{}, err
+	}
+
+	if req.Frequency != "" || req.Interval > 0 {
+		// Re-anchor on the last generated occurrence so a new schedule only
+		// affects occurrences that do not exist yet. That occurrence may have
+		// been clamped to a month end, so month-based schedules keep their
+		// original day of month.
+		day := recurrence.DayOfMonth()
+		wasMonthBased := entity.IsMonthBased(recurrence.Frequency)
+		if recurrence.NextIndex > 0 {
+			lastIndex := recurrence.NextIndex - 1
+			recurrence.AnchorDate = recurrence.OccurrenceDate(lastIndex)
+			recurrence.AnchorIndex = lastIndex
+		}
+		if req.Frequency != "" {
+			recurrence.Frequency = req.Frequency
+		}
+		if req.Interval > 0 {
+			recurrence.Interval = req.Interval
+		}
+
+		recurrence.AnchorDay = 0
+		if wasMonthBased && entity.IsMonthBased(recurrence.Frequency) && day != recurrence.AnchorDate.Day() {
+			recurrence.AnchorDay = day
+		}
+	}
+
+	if req.EndDate != nil {
+		if *req.EndDate == "" {
+			recurrence.EndDate = nil
+		} else {
+			endDate, err := parseDate("end_date", *req.EndDate)
+			if err != nil {
+				return entity.Recurrence{}, err
+			}
+			recurrence.EndDate = &endDate
+		}
+	}
+
+	if req.Count != nil {
+		if *req.Count == 0 {
+			recurrence.MaxOccurrences = nil
+		} else {
+			count := *req.Count
+			recurrence.MaxOccurrences = &count
+		}
+	}
+
+	if req.Active != nil {
+		recurrence.Active = *req.Active
+	}
+
+	if err := recurrence.Validate(); err != nil {
+		return entity.Recurrence{}, errInvalidSchedule.WithMessage("%v", err)
+	}
+
+	return u.recurrenceRepo.Update(ctx, recurrence)
+}
+
+// StopRecurrence ends a recurrence. Bills already generated are kept.
+func (u *recurrenceUsecase) StopRecurrence(ctx context.Context, userID, recurrenceID uint) error {
+	recurrence, err := u.GetRecurrence(ctx, userID, recurrenceID)
+	if err != nil {
+		return err
+	}
+
+	recurrence.Active = false
+	_, err = u.recurrenceRepo.Update(ctx, recurrence)
+	return err
+}
+
+// GenerateDueOccurrences makes sure every active recurrence has an occurrence
+// due today or later, generating any that were missed.
+func (u *recurrenceUsecase) GenerateDueOccurrences(ctx context.Context, now time.Time) (int, error) {
+	recurrences, err := u.recurrenceRepo.FindActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for _, recurrence := range recurrences {
+		count, err := u.generator.catchUp(ctx, recurrence, now)
+		generated += count
+		if err != nil {
+			utils.LogErrorContext(ctx, "RECURRENCE_USECASE_GENERATE", err)
+		}
+	}
+
+	if generated > 0 {
+		utils.FromContext(ctx).Info("[USECASE] Recurring bills generated", map[string]interface{}{
+			"count": generated,
+		})
+	}
+	return generated, nil
+}
+
+// occurrenceGenerator creates the bills belonging to a recurrence. It is shared
+// by the scheduled catch-up and by BillUsecase when an occurrence is paid.
+// Occurrences generated in the background are published as bill.created;
+// BillUsecase publishes the first one itself once its tags are set.
+type occurrenceGenerator struct {
+	billRepo       repository.BillRepository
+	recurrenceRepo repository.RecurrenceRepository
+	events         BillEventPublisher
+}
+
+// generate creates the occurrence with the given index. created is false when
+// that occurrence already existed.
+func (g occurrenceGenerator) generate(ctx context.Context, recurrence entity.Recurrence, index int, now time.Time) (bill entity.Bill, created bool, err error) {
+	recurrenceID := recurrence.ID
+	occurrenceIndex := index
+	dueDate := recurrence.OccurrenceDate(index)
+
+	bill, err = g.billRepo.Create(ctx, entity.Bill{
+		UserID:          recurrence.UserID,
+		Name:            recurrence.Name,
+		Amount:          recurrence.Amount,
+		DueDate:         dueDate,
+		Description:     recurrence.Description,
+		Status:          deriveBillStatus(recurrence.Amount, 0, dueDate, now),
+		RemindBefore:    recurrence.RemindBefore,
+		RecurrenceID:    &recurrenceID,
+		OccurrenceIndex: &occurrenceIndex,
+		CategoryID:      recurrence.CategoryID,
+	})
+	created = true
+	if errors.Is(err, sql.ErrNoRows) {
+		created = false
+	} else if err != nil {
+		return entity.Bill{}, false, err
+	}
+
+	if err := g.recurrenceRepo.AdvanceNextIndex(ctx, recurrence.ID, index); err != nil {
+		return bill, created, err
+	}
+
+	return bill, created, nil
+}
+
+// generateAfter creates the occurrence following bill, provided bill is the
+// latest one generated and the recurrence has not ended.
+func (g occurrenceGenerator) generateAfter(ctx context.Context, bill entity.Bill, now time.Time) error {
+	if bill.RecurrenceID == nil || bill.OccurrenceIndex == nil {
+		return nil
+	}
+
+	recurrence, err := g.recurrenceRepo.FindByID(ctx, *bill.RecurrenceID, bill.UserID)
+	if err != nil {
+		return err
+	}
+
+	next := *bill.OccurrenceIndex + 1
+	if !recurrence.Active || recurrence.NextIndex != next || !recurrence.HasOccurrence(next) {
+		return nil
+	}
+
+	bill, created, err := g.generate(ctx, recurrence, next, now)
+	if created {
+		g.events.PublishBillEvent(ctx, entity.BillEventCreated, bill)
+	}
+	return err
+}
+
+// catchUp generates occurrences until the latest one is due today or later.
+func (g occurrenceGenerator) catchUp(ctx context.Context, recurrence entity.Recurrence, now time.Time) (int, error) {
+	today := startOfDay(now)
+	generated := 0
+
+	for i := 0; i < maxCatchUpOccurrences; i++ {
+		if !recurrence.HasOccurrence(recurrence.NextIndex) {
+			break
+		}
+		if recurrence.NextIndex > 0 && !recurrence.OccurrenceDate(recurrence.NextIndex-1).Before(today) {
+			break
+		}
+
+		bill, created, err := g.generate(ctx, recurrence, recurrence.NextIndex, now)
+		if created {
+			g.events.PublishBillEvent(ctx, entity.BillEventCreated, bill)
+			generated++
+		}
+		if err != nil {
+			return generated, err
+		}
+		recurrence.NextIndex++
+	}
+
+	return generated, nil
+}
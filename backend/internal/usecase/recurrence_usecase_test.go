@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+)
+
+type fakeRecurrenceRepo struct {
+	repository.RecurrenceRepository
+	recurrence entity.Recurrence
+}
+
+func (r *fakeRecurrenceRepo) FindByID(_ context.Context, id, userID uint) (entity.Recurrence, error) {
+	if id != r.recurrence.ID || userID != r.recurrence.UserID {
+		return entity.Recurrence{}, sql.ErrNoRows
+	}
+	return r.recurrence, nil
+}
+
+func (r *fakeRecurrenceRepo) Update(_ context.Context, recurrence entity.Recurrence) (entity.Recurrence, error) {
+	r.recurrence = recurrence
+	return recurrence, nil
+}
+
+func TestUpdateRecurrenceReanchorsOnClampedOccurrence(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name          string
+		recurrence    entity.Recurrence
+		req           dto.RecurrenceUpdateRequest
+		wantAnchor    time.Time
+		wantAnchorDay int
+		wantNext      []time.Time
+	}{
+		{
+			name:          "monthly on the 31st keeps its day after Feb 28",
+			recurrence:    entity.Recurrence{Frequency: entity.FrequencyMonthly, Interval: 1, AnchorDate: day(2025, 1, 31), NextIndex: 2},
+			req:           dto.RecurrenceUpdateRequest{Interval: 2},
+			wantAnchor:    day(2025, 2, 28),
+			wantAnchorDay: 31,
+			wantNext:      []time.Time{day(2025, 4, 30), day(2025, 6, 30), day(2025, 8, 31)},
+		},
+		{
+			name:          "monthly to yearly keeps the day",
+			recurrence:    entity.Recurrence{Frequency: entity.FrequencyMonthly, Interval: 1, AnchorDate: day(2024, 1, 30), NextIndex: 2},
+			req:           dto.RecurrenceUpdateRequest{Frequency: entity.FrequencyYearly},
+			wantAnchor:    day(2024, 2, 29),
+			wantAnchorDay: 30,
+			wantNext:      []time.Time{day(2025, 2, 28), day(2026, 2, 28)},
+		},
+		{
+			name:          "unclamped occurrence needs no anchor day",
+			recurrence:    entity.Recurrence{Frequency: entity.FrequencyMonthly, Interval: 1, AnchorDate: day(2025, 1, 31), NextIndex: 3},
+			req:           dto.RecurrenceUpdateRequest{Interval: 3},
+			wantAnchor:    day(2025, 3, 31),
+			wantAnchorDay: 0,
+			wantNext:      []time.Time{day(2025, 6, 30), day(2025, 9, 30), day(2025, 12, 31)},
+		},
+		{
+			name:          "switching to weekly drops the anchor day",
+			recurrence:    entity.Recurrence{Frequency: entity.FrequencyMonthly, Interval: 1, AnchorDate: day(2025, 1, 31), NextIndex: 2},
+			req:           dto.RecurrenceUpdateRequest{Frequency: entity.FrequencyWeekly},
+			wantAnchor:    day(2025, 2, 28),
+			wantAnchorDay: 0,
+			wantNext:      []time.Time{day(2025, 3, 7), day(2025, 3, 14)},
+		},
+		{
+			name:          "switching from weekly to monthly uses the new anchor's day",
+			recurrence:    entity.Recurrence{Frequency: entity.FrequencyWeekly, Interval: 1, AnchorDate: day(2025, 1, 17), AnchorDay: 31, NextIndex: 2},
+			req:           dto.RecurrenceUpdateRequest{Frequency: entity.FrequencyMonthly},
+			wantAnchor:    day(2025, 1, 24),
+			wantAnchorDay: 0,
+			wantNext:      []time.Time{day(2025, 2, 24), day(2025, 3, 24)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.recurrence.ID, tt.recurrence.UserID = 7, 3
+			repo := &fakeRecurrenceRepo{recurrence: tt.recurrence}
+			u := NewRecurrenceUsecase(repo, nil, nil)
+
+			got, err := u.UpdateRecurrence(context.Background(), 3, 7, &tt.req)
+			if err != nil {
+				t.Fatalf("UpdateRecurrence: %v", err)
+			}
+			if !got.AnchorDate.Equal(tt.wantAnchor) {
+				t.Errorf("AnchorDate = %s, want %s", got.AnchorDate.Format("2006-01-02"), tt.wantAnchor.Format("2006-01-02"))
+			}
+			if got.AnchorIndex != tt.recurrence.NextIndex-1 {
+				t.Errorf("AnchorIndex = %d, want %d", got.AnchorIndex, tt.recurrence.NextIndex-1)
+			}
+			if got.AnchorDay != tt.wantAnchorDay {
+				t.Errorf("AnchorDay = %d, want %d", got.AnchorDay, tt.wantAnchorDay)
+			}
+			for i, want := range tt.wantNext {
+				index := got.NextIndex + i
+				if date := got.OccurrenceDate(index); !date.Equal(want) {
+					t.Errorf("OccurrenceDate(%d) = %s, want %s", index, date.Format("2006-01-02"), want.Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateRecurrenceOfAnotherUser(t *testing.T) {
+	repo := &fakeRecurrenceRepo{recurrence: entity.Recurrence{ID: 7, UserID: 3, Frequency: entity.FrequencyMonthly, Interval: 1}}
+	u := NewRecurrenceUsecase(repo, nil, nil)
+
+	_, err := u.UpdateRecurrence(context.Background(), 4, 7, &dto.RecurrenceUpdateRequest{Interval: 2})
+	if !errors.Is(err, ErrRecurrenceNotFound) {
+		t.Errorf("UpdateRecurrence error = %v, want %v", err, ErrRecurrenceNotFound)
+	}
+}
@@ -0,0 +1,382 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/services"
+	"financebroke/backend/internal/utils"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// telegramLinkTTL is how long a chat link token can be used.
+const telegramLinkTTL = 15 * time.Minute
+
+// telegramSnooze is how long the "Snooze" button holds off a bill's reminder.
+const telegramSnooze = 24 * time.Hour
+
+// telegramListSize is how many bills /bills shows.
+const telegramListSize = 10
+
+var (
+	errTelegramBotDisabled = apperror.Validation("TELEGRAM_BOT_DISABLED", "the Telegram bot is not enabled on this server")
+	// errTelegramNotLinked is answered with telegramNotLinkedText.
+	errTelegramNotLinked = errors.New("telegram chat is not linked")
+)
+
+const (
+	telegramHelpText = "Commands:\n" +
+		"/bills - your unpaid bills\n" +
+		"/upcoming - bills due in the next few days\n" +
+		"/paid <id> - mark a bill as paid"
+	telegramNotLinkedText = "This chat is not linked to a FinanceBroke account yet. " +
+		"Open Notification settings in the app and choose \"Link Telegram\"."
+	telegramPrivateOnlyText = "Please message me in a private chat to manage your bills."
+	telegramFailedText      = "Something went wrong. Please try again later."
+)
+
+// TelegramBotUsecase runs the Telegram bot: it links chats to accounts and
+// answers commands and button presses from linked chats.
+type TelegramBotUsecase interface {
+	CreateLink(ctx context.Context, userID uint) (*dto.TelegramLinkResponse, error)
+	HandleUpdate(ctx context.Context, update services.TelegramUpdate) error
+	PollUpdates(ctx context.Context, now time.Time) (int, error)
+	RegisterWebhook(ctx context.Context) error
+}
+
+type telegramBotUsecase struct {
+	userTokenRepo repository.UserTokenRepository
+	prefRepo      repository.NotificationPreferenceRepository
+	bills         BillUsecase
+	telegram      *services.TelegramService
+	cfg           config.TelegramConfig
+
+	// offset is the next update to fetch in polling mode. Only the polling
+	// job uses it, and jobs never overlap.
+	offset int64
+}
+
+func NewTelegramBotUsecase(
+	userTokenRepo repository.UserTokenRepository,
+	prefRepo repository.NotificationPreferenceRepository,
+	bills BillUsecase,
+	telegram *services.TelegramService,
+	cfg config.TelegramConfig,
+) TelegramBotUsecase {
+	return &telegramBotUsecase{
+		userTokenRepo: userTokenRepo,
+		prefRepo:      prefRepo,
+		bills:         bills,
+		telegram:      telegram,
+		cfg:           cfg,
+	}
+}
+
+// CreateLink issues a token the user sends to the bot with /start to link
+// their chat. Earlier tokens stop working.
+func (u *telegramBotUsecase) CreateLink(ctx context.Context, userID uint) (*dto.TelegramLinkResponse, error) {
+	if u.cfg.UpdateMode == "" || !u.telegram.IsConfigured() {
+		return nil, errTelegramBotDisabled
+	}
+
+	now := time.Now()
+	if err := u.userTokenRepo.InvalidateAll(ctx, userID, entity.UserTokenTelegramLink, now); err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := u.userTokenRepo.Create(ctx, entity.UserToken{
+		UserID:    userID,
+		Purpose:   entity.UserTokenTelegramLink,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(telegramLinkTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.TelegramLinkResponse{
+		Token:     token,
+		Command:   "/start " + token,
+		ExpiresAt: created.ExpiresAt,
+	}
+	if u.cfg.BotUsername != "" {
+		response.Link = "https://t.me/" + url.PathEscape(u.cfg.BotUsername) + "?start=" + url.QueryEscape(token)
+	}
+	return response, nil
+}
+
+// HandleUpdate answers one message or button press. Problems with the user's
+// request are replied to the chat; the error is only for failures to reach
+// Telegram.
+func (u *telegramBotUsecase) HandleUpdate(ctx context.Context, update services.TelegramUpdate) error {
+	switch {
+	case update.CallbackQuery != nil:
+		return u.handleCallback(ctx, update.CallbackQuery)
+	case update.Message != nil && update.Message.Text != "":
+		return u.handleMessage(ctx, update.Message)
+	}
+	return nil
+}
+
+func (u *telegramBotUsecase) handleMessage(ctx context.Context, msg *services.TelegramInboundMessage) error {
+	if msg.Chat.Type != "private" {
+		// Anyone in a group could act on the bills of a linked account
+		return u.reply(ctx, msg.Chat.ID, telegramPrivateOnlyText)
+	}
+
+	command, arg := parseTelegramCommand(msg.Text)
+	if command == "/start" && arg != "" {
+		return u.reply(ctx, msg.Chat.ID, u.link(ctx, msg.Chat.ID, arg))
+	}
+
+	userID, err := u.chatUser(ctx, msg.Chat.ID)
+	if err != nil {
+		return u.reply(ctx, msg.Chat.ID, u.errorText(ctx, err))
+	}
+
+	var text string
+	switch command {
+	case "/bills":
+		text, err = u.listBills(ctx, userID)
+	case "/upcoming":
+		text, err = u.listUpcoming(ctx, userID)
+	case "/paid":
+		text, err = u.markPaidCommand(ctx, userID, arg)
+	default:
+		text = telegramHelpText
+	}
+	if err != nil {
+		text = u.errorText(ctx, err)
+	}
+	return u.reply(ctx, msg.Chat.ID, text)
+}
+
+// parseTelegramCommand splits "/paid@SomeBot 12" into "/paid" and "12".
+func parseTelegramCommand(text string) (string, string) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(arg)
+}
+
+// link connects the chat to the account that created token, and takes it
+// away from any other account it was linked to.
+func (u *telegramBotUsecase) link(ctx context.Context, chatID int64, token string) string {
+	consumed, err := u.userTokenRepo.Consume(ctx, entity.UserTokenTelegramLink, utils.HashToken(token), time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return "This link is invalid or has expired. Create a new one in the app."
+	}
+	if err != nil {
+		utils.LogErrorContext(ctx, "TELEGRAM_BOT_LINK", err)
+		return telegramFailedText
+	}
+
+	err = u.prefRepo.SaveExclusive(ctx, consumed.UserID, entity.NotificationPreference{
+		Channel: u.telegram.Channel(),
+		Enabled: true,
+		Address: strconv.FormatInt(chatID, 10),
+	})
+	if err != nil {
+		utils.LogErrorContext(ctx, "TELEGRAM_BOT_LINK", err)
+		return telegramFailedText
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Telegram chat linked", map[string]interface{}{
+		"user_id": consumed.UserID,
+	})
+	return "✅ Your FinanceBroke account is linked. Bill reminders will be sent here.\n\n" + telegramHelpText
+}
+
+// chatUser returns the account linked to a chat.
+func (u *telegramBotUsecase) chatUser(ctx context.Context, chatID int64) (uint, error) {
+	userID, err := u.prefRepo.FindUserByAddress(ctx, u.telegram.Channel(), strconv.FormatInt(chatID, 10))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errTelegramNotLinked
+	}
+	return userID, err
+}
+
+// errorText is the reply for a failed command: the message of domain errors,
+// which are meant for the user, or a generic one.
+func (u *telegramBotUsecase) errorText(ctx context.Context, err error) string {
+	if errors.Is(err, errTelegramNotLinked) {
+		return telegramNotLinkedText
+	}
+	if appErr, ok := apperror.As(err); ok && appErr.Kind != apperror.KindUpstream {
+		return "❌ " + appErr.Message
+	}
+	utils.LogErrorContext(ctx, "TELEGRAM_BOT_COMMAND", err)
+	return telegramFailedText
+}
+
+func (u *telegramBotUsecase) listBills(ctx context.Context, userID uint) (string, error) {
+	list, err := u.bills.GetUserBills(ctx, userID, &dto.BillListQuery{
+		Status:   strings.Join([]string{entity.BillStatusUnpaid, entity.BillStatusPartiallyPaid, entity.BillStatusOverdue}, ","),
+		Sort:     "due_date",
+		PageSize: telegramListSize,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(list.Data) == 0 {
+		return "🎉 You have no unpaid bills.", nil
+	}
+
+	text := "💳 Unpaid bills\n\n" + formatTelegramBills(list.Data)
+	if list.Pagination.Total > int64(len(list.Data)) {
+		text += fmt.Sprintf("\n…and %d more in the app.", list.Pagination.Total-int64(len(list.Data)))
+	}
+	return text + telegramPaidHint, nil
+}
+
+func (u *telegramBotUsecase) listUpcoming(ctx context.Context, userID uint) (string, error) {
+	bills, err := u.bills.GetUpcomingBills(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if len(bills) == 0 {
+		return "Nothing is due in the next few days.", nil
+	}
+	return "📅 Upcoming bills\n\n" + formatTelegramBills(bills) + telegramPaidHint, nil
+}
+
+func formatTelegramBills(bills []entity.Bill) string {
+	lines := make([]string, len(bills))
+	for i, bill := range bills {
+		lines[i] = fmt.Sprintf("#%d %s - Rp%s, due %s (%s)",
+			bill.ID, bill.Name, bill.Amount-bill.PaidAmount, bill.DueDate.Format("2006-01-02"), bill.Status)
+	}
+	return strings.Join(lines, "\n")
+}
+
+const telegramPaidHint = "\n\nSend /paid <id> to mark a bill as paid."
+
+func (u *telegramBotUsecase) markPaidCommand(ctx context.Context, userID uint, arg string) (string, error) {
+	billID, err := strconv.ParseUint(strings.TrimPrefix(arg, "#"), 10, 32)
+	if err != nil || billID == 0 {
+		return "Usage: /paid <id>. Send /bills to see the IDs of your bills.", nil
+	}
+	return u.markPaid(ctx, userID, uint(billID))
+}
+
+func (u *telegramBotUsecase) markPaid(ctx context.Context, userID, billID uint) (string, error) {
+	bill, err := u.bills.GetBill(ctx, userID, billID)
+	if err != nil {
+		return "", err
+	}
+	if bill.Status == entity.BillStatusPaid {
+		return fmt.Sprintf("%s is already paid.", bill.Name), nil
+	}
+
+	bill, err = u.bills.UpdateBill(ctx, userID, billID, dto.EditScopeThis, &dto.BillUpdateRequest{Status: entity.BillStatusPaid})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✅ %s marked as paid.", bill.Name), nil
+}
+
+// handleCallback acts on a press of a button attached to a bill
+// notification. The buttons are removed once the bill has been dealt with.
+func (u *telegramBotUsecase) handleCallback(ctx context.Context, query *services.TelegramCallbackQuery) error {
+	if query.Message == nil {
+		return u.telegram.AnswerCallbackQuery(ctx, query.ID, "")
+	}
+	chatID := query.Message.Chat.ID
+
+	action, id, _ := strings.Cut(query.Data, ":")
+	billID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return u.telegram.AnswerCallbackQuery(ctx, query.ID, "")
+	}
+
+	userID, err := u.chatUser(ctx, chatID)
+	var text string
+	if err == nil {
+		switch action {
+		case services.TelegramActionPaid:
+			text, err = u.markPaid(ctx, userID, uint(billID))
+		case services.TelegramActionSnooze:
+			text, err = u.snooze(ctx, userID, uint(billID))
+		default:
+			return u.telegram.AnswerCallbackQuery(ctx, query.ID, "")
+		}
+	}
+
+	done := err == nil || errors.Is(err, ErrBillNotFound) || errors.Is(err, errBillAlreadyPaid)
+	if err != nil {
+		text = u.errorText(ctx, err)
+	}
+	if err := u.telegram.AnswerCallbackQuery(ctx, query.ID, text); err != nil {
+		return err
+	}
+	if done {
+		return u.telegram.RemoveKeyboard(ctx, chatID, query.Message.MessageID)
+	}
+	return nil
+}
+
+func (u *telegramBotUsecase) snooze(ctx context.Context, userID, billID uint) (string, error) {
+	bill, err := u.bills.SnoozeReminder(ctx, userID, billID, time.Now().Add(telegramSnooze))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("⏰ I'll remind you about %s again tomorrow.", bill.Name), nil
+}
+
+func (u *telegramBotUsecase) reply(ctx context.Context, chatID int64, text string) error {
+	return u.telegram.SendMessage(ctx, services.TelegramMessage{
+		ChatID: strconv.FormatInt(chatID, 10),
+		Text:   text,
+	})
+}
+
+// PollUpdates fetches and handles the updates that arrived since the last
+// poll, waiting up to the poll timeout for new ones. An update that fails is
+// logged and skipped rather than fetched again.
+func (u *telegramBotUsecase) PollUpdates(ctx context.Context, now time.Time) (int, error) {
+	updates, err := u.telegram.GetUpdates(ctx, u.offset, u.cfg.PollTimeout)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	for _, update := range updates {
+		u.offset = update.UpdateID + 1
+		if err := u.HandleUpdate(ctx, update); err != nil {
+			utils.LogErrorContext(ctx, "TELEGRAM_BOT_UPDATE", err)
+		}
+	}
+	return len(updates), nil
+}
+
+// RegisterWebhook tells Telegram how the bot receives updates: at the
+// configured webhook URL, or through polling, which needs any webhook
+// removed.
+func (u *telegramBotUsecase) RegisterWebhook(ctx context.Context) error {
+	switch u.cfg.UpdateMode {
+	case config.TelegramUpdatesPolling:
+		return u.telegram.DeleteWebhook(ctx)
+	case config.TelegramUpdatesWebhook:
+		if u.cfg.WebhookURL == "" {
+			// Registered outside the app
+			return nil
+		}
+		return u.telegram.SetWebhook(ctx, u.cfg.WebhookURL, u.cfg.WebhookSecret)
+	}
+	return nil
+}
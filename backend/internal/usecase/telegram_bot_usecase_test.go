@@ -0,0 +1,518 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/services"
+)
+
+const testBotToken = "123456:test-token"
+
+type telegramCall struct {
+	method  string
+	payload map[string]interface{}
+}
+
+// fakeTelegramAPI stands in for the Bot API and records every method called.
+type fakeTelegramAPI struct {
+	*httptest.Server
+	mu    sync.Mutex
+	calls []telegramCall
+}
+
+func newFakeTelegramAPI(t *testing.T) *fakeTelegramAPI {
+	api := &fakeTelegramAPI{}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, ok := strings.CutPrefix(r.URL.Path, "/bot"+testBotToken+"/")
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Unauthorized"}`))
+			return
+		}
+
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("%s: invalid request body: %v", method, err)
+		}
+		api.mu.Lock()
+		api.calls = append(api.calls, telegramCall{method: method, payload: payload})
+		api.mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (a *fakeTelegramAPI) called(method string) []telegramCall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var calls []telegramCall
+	for _, call := range a.calls {
+		if call.method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// lastReply returns the text of the last message sent to chatID.
+func (a *fakeTelegramAPI) lastReply(t *testing.T, chatID string) string {
+	t.Helper()
+	messages := a.called("sendMessage")
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].payload["chat_id"] == chatID {
+			return messages[i].payload["text"].(string)
+		}
+	}
+	t.Fatalf("nothing was sent to chat %s", chatID)
+	return ""
+}
+
+type fakeUserTokenRepo struct {
+	repository.UserTokenRepository
+	tokens []entity.UserToken
+}
+
+func (r *fakeUserTokenRepo) Create(_ context.Context, token entity.UserToken) (entity.UserToken, error) {
+	token.ID = uint(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, token)
+	return token, nil
+}
+
+func (r *fakeUserTokenRepo) Consume(_ context.Context, purpose, tokenHash string, at time.Time) (entity.UserToken, error) {
+	for i, token := range r.tokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash && token.UsedAt == nil && token.ExpiresAt.After(at) {
+			r.tokens[i].UsedAt = &at
+			return r.tokens[i], nil
+		}
+	}
+	return entity.UserToken{}, sql.ErrNoRows
+}
+
+func (r *fakeUserTokenRepo) InvalidateAll(_ context.Context, userID uint, purpose string, at time.Time) error {
+	for i, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			r.tokens[i].UsedAt = &at
+		}
+	}
+	return nil
+}
+
+// fakeBills is the part of BillUsecase the bot uses, over an in-memory set of
+// bills.
+type fakeBills struct {
+	BillUsecase
+	bills        map[uint]entity.Bill
+	snoozedUntil map[uint]time.Time
+}
+
+func (b *fakeBills) GetBill(_ context.Context, userID, billID uint) (entity.Bill, error) {
+	bill, ok := b.bills[billID]
+	if !ok || bill.UserID != userID {
+		return entity.Bill{}, ErrBillNotFound
+	}
+	return bill, nil
+}
+
+func (b *fakeBills) UpdateBill(ctx context.Context, userID, billID uint, _ string, req *dto.BillUpdateRequest) (entity.Bill, error) {
+	bill, err := b.GetBill(ctx, userID, billID)
+	if err != nil {
+		return entity.Bill{}, err
+	}
+	bill.Status = req.Status
+	b.bills[billID] = bill
+	return bill, nil
+}
+
+func (b *fakeBills) SnoozeReminder(ctx context.Context, userID, billID uint, until time.Time) (entity.Bill, error) {
+	bill, err := b.GetBill(ctx, userID, billID)
+	if err != nil {
+		return entity.Bill{}, err
+	}
+	if bill.Status == entity.BillStatusPaid {
+		return entity.Bill{}, errBillAlreadyPaid
+	}
+	b.snoozedUntil[billID] = until
+	return bill, nil
+}
+
+type telegramBotFixture struct {
+	api    *fakeTelegramAPI
+	tokens *fakeUserTokenRepo
+	prefs  *fakePrefRepo
+	bills  *fakeBills
+	bot    TelegramBotUsecase
+}
+
+func newTelegramBotFixture(t *testing.T) *telegramBotFixture {
+	api := newFakeTelegramAPI(t)
+	cfg := config.TelegramConfig{
+		BotToken:    testBotToken,
+		BotUsername: "FinanceBrokeBot",
+		APIURL:      api.URL,
+		UpdateMode:  config.TelegramUpdatesPolling,
+	}
+
+	f := &telegramBotFixture{
+		api:    api,
+		tokens: &fakeUserTokenRepo{},
+		prefs:  newFakePrefRepo(),
+		bills: &fakeBills{
+			bills: map[uint]entity.Bill{
+				10: {ID: 10, UserID: 1, Name: "Electricity", Status: entity.BillStatusUnpaid},
+				11: {ID: 11, UserID: 1, Name: "Internet", Status: entity.BillStatusPaid},
+				20: {ID: 20, UserID: 2, Name: "Rent", Status: entity.BillStatusUnpaid},
+			},
+			snoozedUntil: map[uint]time.Time{},
+		},
+	}
+	f.bot = NewTelegramBotUsecase(f.tokens, f.prefs, f.bills, services.NewTelegramService(cfg), cfg)
+	return f
+}
+
+func privateMessage(chatID int64, text string) services.TelegramUpdate {
+	return services.TelegramUpdate{Message: &services.TelegramInboundMessage{
+		MessageID: 1,
+		Chat:      services.TelegramChat{ID: chatID, Type: "private"},
+		Text:      text,
+	}}
+}
+
+func TestTelegramCreateLink(t *testing.T) {
+	f := newTelegramBotFixture(t)
+
+	link, err := f.bot.CreateLink(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	if link.Command != "/start "+link.Token {
+		t.Errorf("Command = %q, want /start followed by the token", link.Command)
+	}
+	if want := "https://t.me/FinanceBrokeBot?start=" + link.Token; link.Link != want {
+		t.Errorf("Link = %q, want %q", link.Link, want)
+	}
+	if ttl := time.Until(link.ExpiresAt); ttl <= 0 || ttl > telegramLinkTTL {
+		t.Errorf("link expires in %v, want within %v", ttl, telegramLinkTTL)
+	}
+	for _, token := range f.tokens.tokens {
+		if token.TokenHash == link.Token {
+			t.Error("the token is stored unhashed")
+		}
+	}
+
+	disabled := NewTelegramBotUsecase(f.tokens, f.prefs, f.bills,
+		services.NewTelegramService(config.TelegramConfig{BotToken: testBotToken}), config.TelegramConfig{})
+	if _, err := disabled.CreateLink(context.Background(), 1); !errors.Is(err, errTelegramBotDisabled) {
+		t.Errorf("CreateLink without updates error = %v, want %v", err, errTelegramBotDisabled)
+	}
+}
+
+func TestTelegramLinkChat(t *testing.T) {
+	tests := []struct {
+		name string
+		// token returns the token to send with /start.
+		token      func(t *testing.T, f *telegramBotFixture) string
+		chatType   string
+		wantLinked bool
+		wantReply  string
+	}{
+		{
+			name: "valid token links the chat",
+			token: func(t *testing.T, f *telegramBotFixture) string {
+				return createLinkToken(t, f, 1)
+			},
+			wantLinked: true,
+			wantReply:  "account is linked",
+		},
+		{
+			name: "token is single use",
+			token: func(t *testing.T, f *telegramBotFixture) string {
+				token := createLinkToken(t, f, 1)
+				if err := f.bot.HandleUpdate(context.Background(), privateMessage(999, "/start "+token)); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantReply: "invalid or has expired",
+		},
+		{
+			name: "older token stops working when a new one is created",
+			token: func(t *testing.T, f *telegramBotFixture) string {
+				token := createLinkToken(t, f, 1)
+				createLinkToken(t, f, 1)
+				return token
+			},
+			wantReply: "invalid or has expired",
+		},
+		{
+			name: "expired token",
+			token: func(t *testing.T, f *telegramBotFixture) string {
+				token := createLinkToken(t, f, 1)
+				f.tokens.tokens[len(f.tokens.tokens)-1].ExpiresAt = time.Now().Add(-time.Second)
+				return token
+			},
+			wantReply: "invalid or has expired",
+		},
+		{
+			name: "unknown token",
+			token: func(*testing.T, *telegramBotFixture) string {
+				return "not-a-token"
+			},
+			wantReply: "invalid or has expired",
+		},
+		{
+			name: "group chats cannot be linked",
+			token: func(t *testing.T, f *telegramBotFixture) string {
+				return createLinkToken(t, f, 1)
+			},
+			chatType:  "group",
+			wantReply: telegramPrivateOnlyText,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTelegramBotFixture(t)
+			token := tt.token(t, f)
+
+			update := privateMessage(4242, "/start "+token)
+			if tt.chatType != "" {
+				update.Message.Chat.Type = tt.chatType
+			}
+			if err := f.bot.HandleUpdate(context.Background(), update); err != nil {
+				t.Fatalf("HandleUpdate: %v", err)
+			}
+
+			if reply := f.api.lastReply(t, "4242"); !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply = %q, want it to contain %q", reply, tt.wantReply)
+			}
+
+			pref := f.prefs.get(1, "telegram")
+			if linked := pref.Address == "4242"; linked != tt.wantLinked {
+				t.Errorf("chat linked = %v, want %v (saved %+v)", linked, tt.wantLinked, pref)
+			}
+			if tt.wantLinked && !pref.Enabled {
+				t.Error("linked channel is not enabled")
+			}
+		})
+	}
+}
+
+func createLinkToken(t *testing.T, f *telegramBotFixture, userID uint) string {
+	t.Helper()
+	link, err := f.bot.CreateLink(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	return link.Token
+}
+
+func TestTelegramLinkMovesChatBetweenAccounts(t *testing.T) {
+	f := newTelegramBotFixture(t)
+	f.prefs.set(2, entity.NotificationPreference{Channel: "telegram", Enabled: true, Address: "4242"})
+
+	token := createLinkToken(t, f, 1)
+	if err := f.bot.HandleUpdate(context.Background(), privateMessage(4242, "/start "+token)); err != nil {
+		t.Fatalf("HandleUpdate: %v", err)
+	}
+
+	if got := f.prefs.get(1, "telegram").Address; got != "4242" {
+		t.Errorf("new account's chat = %q, want 4242", got)
+	}
+	if got := f.prefs.get(2, "telegram"); got.Address != "" || got.Enabled {
+		t.Errorf("previous account still has the chat: %+v", got)
+	}
+}
+
+func TestTelegramCallback(t *testing.T) {
+	tests := []struct {
+		name        string
+		linkedTo    uint
+		data        string
+		wantAnswer  string
+		wantRemoved bool
+		wantStatus  map[uint]string
+		wantSnoozed uint
+	}{
+		{
+			name:       "unlinked chat cannot act on bills",
+			data:       "paid:10",
+			wantAnswer: telegramNotLinkedText,
+			wantStatus: map[uint]string{10: entity.BillStatusUnpaid},
+		},
+		{
+			name:        "bill of another account is not found",
+			linkedTo:    1,
+			data:        "paid:20",
+			wantAnswer:  "❌ " + ErrBillNotFound.Message,
+			wantRemoved: true,
+			wantStatus:  map[uint]string{20: entity.BillStatusUnpaid},
+		},
+		{
+			name:        "snooze of another account's bill is not found",
+			linkedTo:    1,
+			data:        "snooze:20",
+			wantAnswer:  "❌ " + ErrBillNotFound.Message,
+			wantRemoved: true,
+		},
+		{
+			name:        "linked chat marks its bill paid",
+			linkedTo:    1,
+			data:        "paid:10",
+			wantAnswer:  "Electricity marked as paid",
+			wantRemoved: true,
+			wantStatus:  map[uint]string{10: entity.BillStatusPaid},
+		},
+		{
+			name:        "bill already paid",
+			linkedTo:    1,
+			data:        "paid:11",
+			wantAnswer:  "Internet is already paid",
+			wantRemoved: true,
+		},
+		{
+			name:        "linked chat snoozes its bill",
+			linkedTo:    1,
+			data:        "snooze:10",
+			wantAnswer:  "remind you about Electricity again tomorrow",
+			wantRemoved: true,
+			wantSnoozed: 10,
+		},
+		{
+			name:       "malformed data is only acknowledged",
+			linkedTo:   1,
+			data:       "paid:abc",
+			wantStatus: map[uint]string{10: entity.BillStatusUnpaid},
+		},
+		{
+			name:       "unknown action is only acknowledged",
+			linkedTo:   1,
+			data:       "delete:10",
+			wantStatus: map[uint]string{10: entity.BillStatusUnpaid},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTelegramBotFixture(t)
+			if tt.linkedTo != 0 {
+				f.prefs.set(tt.linkedTo, entity.NotificationPreference{Channel: "telegram", Enabled: true, Address: "4242"})
+			}
+
+			before := time.Now()
+			err := f.bot.HandleUpdate(context.Background(), services.TelegramUpdate{CallbackQuery: &services.TelegramCallbackQuery{
+				ID:   "cb-1",
+				Data: tt.data,
+				Message: &services.TelegramInboundMessage{
+					MessageID: 77,
+					Chat:      services.TelegramChat{ID: 4242, Type: "private"},
+				},
+			}})
+			if err != nil {
+				t.Fatalf("HandleUpdate: %v", err)
+			}
+
+			answers := f.api.called("answerCallbackQuery")
+			if len(answers) != 1 {
+				t.Fatalf("answered the callback %d times, want once", len(answers))
+			}
+			if id := answers[0].payload["callback_query_id"]; id != "cb-1" {
+				t.Errorf("answered callback %v, want cb-1", id)
+			}
+			answer, _ := answers[0].payload["text"].(string)
+			if tt.wantAnswer == "" && answer != "" {
+				t.Errorf("answer = %q, want none", answer)
+			}
+			if !strings.Contains(answer, tt.wantAnswer) {
+				t.Errorf("answer = %q, want it to contain %q", answer, tt.wantAnswer)
+			}
+
+			removed := f.api.called("editMessageReplyMarkup")
+			if (len(removed) == 1) != tt.wantRemoved {
+				t.Errorf("keyboard removals = %d, want removed %v", len(removed), tt.wantRemoved)
+			}
+			if len(removed) == 1 && (removed[0].payload["chat_id"] != float64(4242) || removed[0].payload["message_id"] != float64(77)) {
+				t.Errorf("removed keyboard of %v, want message 77 in chat 4242", removed[0].payload)
+			}
+
+			for id, status := range tt.wantStatus {
+				if got := f.bills.bills[id].Status; got != status {
+					t.Errorf("bill %d status = %q, want %q", id, got, status)
+				}
+			}
+
+			for id, until := range f.bills.snoozedUntil {
+				if id != tt.wantSnoozed {
+					t.Errorf("bill %d was snoozed", id)
+					continue
+				}
+				if until.Before(before.Add(telegramSnooze)) || until.After(time.Now().Add(telegramSnooze)) {
+					t.Errorf("bill %d snoozed until %v, want a day from now", id, until)
+				}
+			}
+			if _, ok := f.bills.snoozedUntil[tt.wantSnoozed]; tt.wantSnoozed != 0 && !ok {
+				t.Errorf("bill %d was not snoozed", tt.wantSnoozed)
+			}
+		})
+	}
+}
+
+func TestTelegramCommandsNeedLinkedPrivateChat(t *testing.T) {
+	tests := []struct {
+		name      string
+		chatType  string
+		linked    bool
+		text      string
+		wantReply string
+		wantPaid  bool
+	}{
+		{name: "unlinked chat", text: "/paid 10", wantReply: telegramNotLinkedText},
+		{name: "group chat of a linked account", chatType: "group", linked: true, text: "/paid 10", wantReply: telegramPrivateOnlyText},
+		{name: "linked chat", linked: true, text: "/paid 10", wantReply: "Electricity marked as paid", wantPaid: true},
+		{name: "linked chat addressing the bot by name", linked: true, text: "/paid@FinanceBrokeBot #10", wantReply: "Electricity marked as paid", wantPaid: true},
+		{name: "bill of another account", linked: true, text: "/paid 20", wantReply: "❌ " + ErrBillNotFound.Message},
+		{name: "missing bill ID", linked: true, text: "/paid", wantReply: "Usage: /paid <id>"},
+		{name: "unknown command", linked: true, text: "/hello", wantReply: telegramHelpText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTelegramBotFixture(t)
+			if tt.linked {
+				f.prefs.set(1, entity.NotificationPreference{Channel: "telegram", Enabled: true, Address: "4242"})
+			}
+
+			update := privateMessage(4242, tt.text)
+			if tt.chatType != "" {
+				update.Message.Chat.Type = tt.chatType
+			}
+			if err := f.bot.HandleUpdate(context.Background(), update); err != nil {
+				t.Fatalf("HandleUpdate: %v", err)
+			}
+
+			if reply := f.api.lastReply(t, "4242"); !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("reply = %q, want it to contain %q", reply, tt.wantReply)
+			}
+			if paid := f.bills.bills[10].Status == entity.BillStatusPaid; paid != tt.wantPaid {
+				t.Errorf("bill 10 paid = %v, want %v", paid, tt.wantPaid)
+			}
+			if f.bills.bills[20].Status != entity.BillStatusUnpaid {
+				t.Error("another account's bill was changed")
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/utils"
+	"time"
+)
+
+type TwoFactorUsecase interface {
+	GetStatus(ctx context.Context, userID uint) (*dto.TwoFactorStatusResponse, error)
+	Enroll(ctx context.Context, userID uint) (*dto.TwoFactorEnrollResponse, error)
+	Confirm(ctx context.Context, userID uint, req *dto.TwoFactorConfirmRequest) (*dto.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID uint, req *dto.PasswordConfirmRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, req *dto.PasswordConfirmRequest) (*dto.RecoveryCodesResponse, error)
+}
+
+var (
+	ErrTwoFactorAlreadyEnabled = apperror.Conflict("TWO_FACTOR_ENABLED", "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = apperror.Conflict("TWO_FACTOR_NOT_ENABLED", "two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled    = apperror.Conflict("TWO_FACTOR_NOT_ENROLLED", "start two-factor enrollment first")
+	ErrInvalidTwoFactorCode    = apperror.Validation("INVALID_TWO_FACTOR_CODE", "invalid two-factor code")
+	// ErrIncorrectPassword is returned when re-confirming the current
+	// password for a sensitive change fails.
+	ErrIncorrectPassword = apperror.Forbidden("INCORRECT_PASSWORD", "incorrect password")
+)
+
+const (
+	// twoFactorIssuer is the account issuer shown in authenticator apps.
+	twoFactorIssuer = "FinanceBroke"
+	// recoveryCodeCount is how many recovery codes are issued at a time.
+	recoveryCodeCount = 10
+)
+
+type twoFactorUsecase struct {
+	userRepo      repository.UserRepository
+	twoFactorRepo repository.TwoFactorRepository
+}
+
+func NewTwoFactorUsecase(userRepo repository.UserRepository, twoFactorRepo repository.TwoFactorRepository) TwoFactorUsecase {
+	return &twoFactorUsecase{userRepo: userRepo, twoFactorRepo: twoFactorRepo}
+}
+
+func (u *twoFactorUsecase) GetStatus(ctx context.Context, userID uint) (*dto.TwoFactorStatusResponse, error) {
+	twoFactor, err := u.twoFactorRepo.Find(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !twoFactor.Enabled()) {
+		return &dto.TwoFactorStatusResponse{Enabled: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := u.twoFactorRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorStatusResponse{
+		Enabled:                true,
+		EnabledAt:              twoFactor.EnabledAt,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// Enroll generates a new secret for the user to add to an authenticator app.
+// Two-factor authentication is not enforced until Confirm succeeds.
+func (u *twoFactorUsecase) Enroll(ctx context.Context, userID uint) (*dto.TwoFactorEnrollResponse, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.twoFactorRepo.SavePending(ctx, userID, secret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		utils.LogErrorContext(ctx, "TWO_FACTOR_USECASE_ENROLL", err)
+		return nil, err
+	}
+
+	return &dto.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user proves their app
+// produces valid codes, and returns the recovery codes. The codes are only
+// ever shown here and when regenerated.
+func (u *twoFactorUsecase) Confirm(ctx context.Context, userID uint, req *dto.TwoFactorConfirmRequest) (*dto.RecoveryCodesResponse, error) {
+	twoFactor, err := u.twoFactorRepo.Find(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+	if twoFactor.Enabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	now := time.Now()
+	step, ok := utils.ValidateTOTP(twoFactor.Secret, req.Code, now)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.twoFactorRepo.Enable(ctx, userID, step, now, hashes); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		utils.LogErrorContext(ctx, "TWO_FACTOR_USECASE_CONFIRM", err)
+		return nil, err
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Two-factor authentication enabled", map[string]interface{}{
+		"user_id": userID,
+	})
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (u *twoFactorUsecase) Disable(ctx context.Context, userID uint, req *dto.PasswordConfirmRequest) error {
+	if err := confirmPassword(ctx, u.userRepo, userID, req.Password); err != nil {
+		return err
+	}
+
+	if err := u.twoFactorRepo.Delete(ctx, userID); err != nil {
+		utils.LogErrorContext(ctx, "TWO_FACTOR_USECASE_DISABLE", err)
+		return err
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Two-factor authentication disabled", map[string]interface{}{
+		"user_id": userID,
+	})
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, used or not, with a
+// new set.
+func (u *twoFactorUsecase) RegenerateRecoveryCodes(ctx context.Context, userID uint, req *dto.PasswordConfirmRequest) (*dto.RecoveryCodesResponse, error) {
+	if err := confirmPassword(ctx, u.userRepo, userID, req.Password); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := u.twoFactorRepo.Find(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !twoFactor.Enabled()) {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		utils.LogErrorContext(ctx, "TWO_FACTOR_USECASE_REGENERATE", err)
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// verifyTOTP checks a code from the user's authenticator app and marks its
+// time step as used so the same code cannot be accepted twice.
+func verifyTOTP(ctx context.Context, twoFactorRepo repository.TwoFactorRepository, twoFactor entity.TwoFactor, code string, now time.Time) error {
+	step, ok := utils.ValidateTOTP(twoFactor.Secret, code, now)
+	if !ok || step <= twoFactor.LastStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := twoFactorRepo.UseStep(ctx, twoFactor.UserID, step); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+	return nil
+}
+
+func confirmPassword(ctx context.Context, userRepo repository.UserRepository, userID uint, password string) error {
+	passwordHash, err := userRepo.FindPasswordHash(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	if !utils.CheckPassword(password, passwordHash) {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
@@ -0,0 +1,440 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"financebroke/backend/internal/apperror"
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/dto"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/services"
+	"financebroke/backend/internal/utils"
+	"strings"
+	"time"
+)
+
+// maxWebhooksPerUser bounds how many endpoints one user can register.
+const maxWebhooksPerUser = 10
+
+// defaultWebhookDeliveryPageSize is the page size of GET /webhooks/:id/deliveries.
+const defaultWebhookDeliveryPageSize = 20
+
+var (
+	errWebhookLimit      = apperror.Conflict("WEBHOOK_LIMIT_REACHED", "too many webhooks")
+	errInvalidWebhookURL = apperror.Validation("INVALID_WEBHOOK_URL", "invalid webhook URL")
+	errUnknownBillEvent  = apperror.Validation("UNKNOWN_WEBHOOK_EVENT", "unknown webhook event")
+)
+
+// BillEventPublisher queues bill events for the owner's webhooks. Publishing
+// never fails the change that caused the event; problems are logged.
+type BillEventPublisher interface {
+	PublishBillEvent(ctx context.Context, event string, bill entity.Bill)
+}
+
+type WebhookUsecase interface {
+	BillEventPublisher
+	ListWebhooks(ctx context.Context, userID uint) ([]entity.Webhook, error)
+	CreateWebhook(ctx context.Context, userID uint, req *dto.WebhookRequest) (*dto.WebhookCreatedResponse, error)
+	UpdateWebhook(ctx context.Context, userID, webhookID uint, req *dto.WebhookUpdateRequest) (entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID uint) error
+	TestWebhook(ctx context.Context, userID, webhookID uint) (entity.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, userID, webhookID uint, query *dto.WebhookDeliveryListQuery) (*dto.WebhookDeliveryListResponse, error)
+	DeliverPending(ctx context.Context, now time.Time) (int, error)
+}
+
+type webhookUsecase struct {
+	webhookRepo  repository.WebhookRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	sender       *services.WebhookService
+	cfg          config.WebhookConfig
+}
+
+func NewWebhookUsecase(
+	webhookRepo repository.WebhookRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	sender *services.WebhookService,
+	cfg config.WebhookConfig,
+) WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		sender:       sender,
+		cfg:          cfg,
+	}
+}
+
+func (u *webhookUsecase) ListWebhooks(ctx context.Context, userID uint) ([]entity.Webhook, error) {
+	webhooks, err := u.webhookRepo.FindByUser(ctx, userID)
+	if webhooks == nil {
+		webhooks = []entity.Webhook{}
+	}
+	return webhooks, err
+}
+
+// CreateWebhook registers an endpoint with a new signing secret. The secret
+// is only ever returned here.
+func (u *webhookUsecase) CreateWebhook(ctx context.Context, userID uint, req *dto.WebhookRequest) (*dto.WebhookCreatedResponse, error) {
+	url := strings.TrimSpace(req.URL)
+	if err := u.sender.ValidateURL(url); err != nil {
+		return nil, errInvalidWebhookURL.WithMessage("invalid webhook URL: %v", err)
+	}
+
+	events, err := normalizeBillEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := u.webhookRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWebhooksPerUser {
+		return nil, errWebhookLimit.WithMessage("a user can have at most %d webhooks", maxWebhooksPerUser)
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := u.webhookRepo.Create(ctx, entity.Webhook{
+		UserID:      userID,
+		URL:         url,
+		Secret:      "whsec_" + token,
+		Events:      events,
+		Description: strings.TrimSpace(req.Description),
+		Enabled:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.FromContext(ctx).Info("[USECASE] Webhook created", map[string]interface{}{
+		"user_id":    userID,
+		"webhook_id": webhook.ID,
+	})
+	return &dto.WebhookCreatedResponse{Webhook: webhook, Secret: webhook.Secret}, nil
+}
+
+// normalizeBillEvents checks that every event is known and drops duplicates.
+func normalizeBillEvents(events []string) ([]string, error) {
+	seen := make(map[string]bool, len(events))
+	normalized := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if !entity.IsValidBillEvent(event) {
+			return nil, errUnknownBillEvent.WithMessage("unknown webhook event %q", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			normalized = append(normalized, event)
+		}
+	}
+	return normalized, nil
+}
+
+// UpdateWebhook changes the fields set in req. Enabling a webhook clears the
+// failures that disabled it.
+func (u *webhookUsecase) UpdateWebhook(ctx context.Context, userID, webhookID uint, req *dto.WebhookUpdateRequest) (entity.Webhook, error) {
+	webhook, err := u.findWebhook(ctx, userID, webhookID)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	if req.URL != nil {
+		url := strings.TrimSpace(*req.URL)
+		if err := u.sender.ValidateURL(url); err != nil {
+			return entity.Webhook{}, errInvalidWebhookURL.WithMessage("invalid webhook URL: %v", err)
+		}
+		webhook.URL = url
+	}
+	if req.Events != nil {
+		if webhook.Events, err = normalizeBillEvents(req.Events); err != nil {
+			return entity.Webhook{}, err
+		}
+	}
+	if req.Description != nil {
+		webhook.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Enabled != nil {
+		if *req.Enabled && !webhook.Enabled {
+			webhook.ConsecutiveFailures = 0
+			webhook.DisabledAt = nil
+		}
+		webhook.Enabled = *req.Enabled
+	}
+
+	updated, err := u.webhookRepo.Update(ctx, webhook)
+	return updated, notFound(err, ErrWebhookNotFound)
+}
+
+// DeleteWebhook removes the webhook together with its delivery log.
+func (u *webhookUsecase) DeleteWebhook(ctx context.Context, userID, webhookID uint) error {
+	return notFound(u.webhookRepo.Delete(ctx, webhookID, userID), ErrWebhookNotFound)
+}
+
+func (u *webhookUsecase) findWebhook(ctx context.Context, userID, webhookID uint) (entity.Webhook, error) {
+	webhook, err := u.webhookRepo.FindByID(ctx, webhookID, userID)
+	return webhook, notFound(err, ErrWebhookNotFound)
+}
+
+// TestWebhook sends a ping to the endpoint right away, whether or not it is
+// enabled, and returns the logged delivery. A failed ping is not retried and
+// does not count towards disabling the webhook.
+func (u *webhookUsecase) TestWebhook(ctx context.Context, userID, webhookID uint) (entity.WebhookDelivery, error) {
+	webhook, err := u.findWebhook(ctx, userID, webhookID)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	payload, err := webhookPayload(entity.WebhookEventPing, map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"message":    "Test delivery from FinanceBroke",
+	})
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	// Created already held by this request, so no worker sends it as well
+	now := time.Now()
+	lockedUntil := now.Add(2 * u.cfg.Timeout)
+	delivery, err := u.deliveryRepo.Create(ctx, entity.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		UserID:        userID,
+		Event:         entity.WebhookEventPing,
+		Payload:       payload,
+		Status:        entity.WebhookDeliverySending,
+		MaxAttempts:   1,
+		NextAttemptAt: now,
+		LockedUntil:   &lockedUntil,
+	})
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	u.deliver(ctx, delivery)
+
+	return u.deliveryRepo.FindByID(context.WithoutCancel(ctx), delivery.ID, userID)
+}
+
+// GetDeliveries lists the webhook's deliveries, newest first.
+func (u *webhookUsecase) GetDeliveries(ctx context.Context, userID, webhookID uint, query *dto.WebhookDeliveryListQuery) (*dto.WebhookDeliveryListResponse, error) {
+	if _, err := u.findWebhook(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	filter := repository.WebhookDeliveryFilter{
+		WebhookID: webhookID,
+		UserID:    userID,
+		Status:    query.Status,
+		Limit:     defaultWebhookDeliveryPageSize,
+	}
+	if query.PageSize > 0 {
+		filter.Limit = query.PageSize
+	}
+	if query.Page > 1 {
+		filter.Offset = (query.Page - 1) * filter.Limit
+	}
+
+	deliveries, total, err := u.deliveryRepo.FindPage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []entity.WebhookDelivery{}
+	}
+
+	return &dto.WebhookDeliveryListResponse{
+		Data: deliveries,
+		Pagination: dto.Pagination{
+			Page:       filter.Offset/filter.Limit + 1,
+			PageSize:   filter.Limit,
+			Total:      total,
+			TotalPages: int((total + int64(filter.Limit) - 1) / int64(filter.Limit)),
+		},
+	}, nil
+}
+
+// PublishBillEvent queues the event, with the bill as it is now, for every
+// enabled webhook of the bill's owner that subscribes to it.
+func (u *webhookUsecase) PublishBillEvent(ctx context.Context, event string, bill entity.Bill) {
+	webhooks, err := u.webhookRepo.FindSubscribed(ctx, bill.UserID, event)
+	if err != nil {
+		utils.LogErrorContext(ctx, "WEBHOOK_USECASE_PUBLISH", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := webhookPayload(event, map[string]interface{}{"bill": bill})
+	if err != nil {
+		utils.LogErrorContext(ctx, "WEBHOOK_USECASE_PUBLISH", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		_, err := u.deliveryRepo.Create(ctx, entity.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			UserID:        bill.UserID,
+			Event:         event,
+			Payload:       payload,
+			MaxAttempts:   u.cfg.MaxAttempts,
+			NextAttemptAt: time.Now(),
+		})
+		if err != nil {
+			utils.LogErrorContext(ctx, "WEBHOOK_USECASE_PUBLISH", err)
+		}
+	}
+}
+
+// webhookPayload builds the JSON body of an event. Every event gets its own
+// ID, kept across retries, so receivers can drop duplicates.
+func webhookPayload(event string, data map[string]interface{}) (json.RawMessage, error) {
+	id, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(dto.WebhookEvent{
+		ID:        "evt_" + id,
+		Type:      event,
+		CreatedAt: time.Now(),
+		Data:      data,
+	})
+}
+
+// DeliverPending sends every queued delivery due at now, using up to
+// cfg.Workers requests at a time, and returns how many were delivered.
+func (u *webhookUsecase) DeliverPending(ctx context.Context, now time.Time) (int, error) {
+	delivered, err := drainQueue(ctx, u.cfg.Workers,
+		func(ctx context.Context) (entity.WebhookDelivery, error) {
+			return u.deliveryRepo.Claim(ctx, now, time.Now().Add(2*u.cfg.Timeout))
+		},
+		u.deliver,
+	)
+
+	if delivered > 0 {
+		utils.FromContext(ctx).Info("[USECASE] Webhooks delivered", map[string]interface{}{
+			"delivered": delivered,
+		})
+	}
+	return delivered, err
+}
+
+// deliver posts one claimed delivery and records the outcome. Failed
+// deliveries are retried with exponential backoff until MaxAttempts is
+// reached, and every failure except a ping's counts towards disabling the
+// webhook. Deliveries cut short by ctx being cancelled are put back without
+// counting an attempt. It reports whether the endpoint accepted the delivery.
+func (u *webhookUsecase) deliver(ctx context.Context, d entity.WebhookDelivery) bool {
+	// The outcome must be recorded even when ctx has been cancelled
+	record := context.WithoutCancel(ctx)
+	ping := d.Event == entity.WebhookEventPing
+
+	webhook, err := u.webhookRepo.FindByID(record, d.WebhookID, d.UserID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			utils.LogErrorContext(ctx, "WEBHOOK_USECASE_FIND", err)
+			if err := u.deliveryRepo.Release(record, d.ID); err != nil {
+				utils.LogErrorContext(ctx, "WEBHOOK_USECASE_RELEASE", err)
+			}
+		}
+		// A deleted webhook takes its deliveries with it
+		return false
+	}
+
+	if !webhook.Enabled && !ping {
+		if err := u.deliveryRepo.RecordFailure(record, d.ID, nil, "", "webhook is disabled", nil); err != nil {
+			utils.LogErrorContext(ctx, "WEBHOOK_USECASE_RECORD_FAILURE", err)
+		}
+		return false
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, u.cfg.Timeout)
+	response, err := u.sender.Deliver(sendCtx, services.WebhookRequest{
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		Event:      d.Event,
+		DeliveryID: d.ID,
+		Payload:    d.Payload,
+	})
+	cancel()
+
+	if err == nil {
+		if err := u.deliveryRepo.MarkDelivered(record, d.ID, response.StatusCode, response.Body, time.Now()); err != nil {
+			utils.LogErrorContext(ctx, "WEBHOOK_USECASE_MARK_DELIVERED", err)
+		}
+		if !ping && webhook.ConsecutiveFailures > 0 {
+			if err := u.webhookRepo.RecordSuccess(record, webhook.ID); err != nil {
+				utils.LogErrorContext(ctx, "WEBHOOK_USECASE_RECORD_SUCCESS", err)
+			}
+		}
+		return true
+	}
+
+	if ctx.Err() != nil {
+		if err := u.deliveryRepo.Release(record, d.ID); err != nil {
+			utils.LogErrorContext(ctx, "WEBHOOK_USECASE_RELEASE", err)
+		}
+		return false
+	}
+
+	var retryAt *time.Time
+	attempts := d.Attempts + 1
+	if attempts < d.MaxAttempts {
+		next := time.Now().Add(backoff(u.cfg.RetryDelay, u.cfg.MaxRetryDelay, attempts))
+		retryAt = &next
+	}
+	var responseStatus *int
+	if response.StatusCode != 0 {
+		responseStatus = &response.StatusCode
+	}
+
+	utils.FromContext(ctx).Warn("[USECASE] Webhook delivery failed", map[string]interface{}{
+		"delivery_id": d.ID,
+		"webhook_id":  webhook.ID,
+		"attempts":    attempts,
+		"error":       err.Error(),
+		"will_retry":  retryAt != nil,
+	})
+	if err := u.deliveryRepo.RecordFailure(record, d.ID, responseStatus, response.Body, err.Error(), retryAt); err != nil {
+		utils.LogErrorContext(ctx, "WEBHOOK_USECASE_RECORD_FAILURE", err)
+	}
+
+	if !ping {
+		u.countFailure(ctx, webhook)
+	}
+	return false
+}
+
+// countFailure adds a failed delivery to the webhook's run of failures and,
+// when that disables it, gives up on the deliveries still queued for it.
+func (u *webhookUsecase) countFailure(ctx context.Context, webhook entity.Webhook) {
+	record := context.WithoutCancel(ctx)
+
+	updated, err := u.webhookRepo.RecordFailure(record, webhook.ID, u.cfg.DisableAfter, time.Now())
+	if err != nil {
+		utils.LogErrorContext(ctx, "WEBHOOK_USECASE_COUNT_FAILURE", err)
+		return
+	}
+	// Only the failure that reaches the limit disables the webhook
+	if updated.Enabled || updated.ConsecutiveFailures != u.cfg.DisableAfter {
+		return
+	}
+
+	dropped, err := u.deliveryRepo.FailPending(record, webhook.ID, "webhook was disabled after repeated failures")
+	if err != nil {
+		utils.LogErrorContext(ctx, "WEBHOOK_USECASE_FAIL_PENDING", err)
+	}
+
+	utils.FromContext(ctx).Warn("[USECASE] Webhook disabled after repeated failures", map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"user_id":    webhook.UserID,
+		"failures":   updated.ConsecutiveFailures,
+		"dropped":    dropped,
+	})
+}
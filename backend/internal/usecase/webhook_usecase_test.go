@@ -0,0 +1,322 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"financebroke/backend/internal/config"
+	"financebroke/backend/internal/entity"
+	"financebroke/backend/internal/repository"
+	"financebroke/backend/internal/services"
+)
+
+// fakeWebhookRepo keeps one webhook and counts failures the way the
+// database does.
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+	mu        sync.Mutex
+	webhook   entity.Webhook
+	successes int
+}
+
+func (r *fakeWebhookRepo) FindByID(_ context.Context, id, userID uint) (entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id != r.webhook.ID || userID != r.webhook.UserID {
+		return entity.Webhook{}, sql.ErrNoRows
+	}
+	return r.webhook, nil
+}
+
+func (r *fakeWebhookRepo) RecordSuccess(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhook.ConsecutiveFailures = 0
+	r.successes++
+	return nil
+}
+
+func (r *fakeWebhookRepo) RecordFailure(_ context.Context, id uint, disableAfter int, at time.Time) (entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhook.ConsecutiveFailures++
+	if r.webhook.Enabled && r.webhook.ConsecutiveFailures >= disableAfter {
+		r.webhook.Enabled = false
+		r.webhook.DisabledAt = &at
+	}
+	return r.webhook, nil
+}
+
+type deliveryOutcome struct {
+	delivered bool
+	status    *int
+	retryAt   *time.Time
+	lastError string
+}
+
+type fakeDeliveryRepo struct {
+	repository.WebhookDeliveryRepository
+	mu          sync.Mutex
+	queue       []entity.WebhookDelivery
+	outcomes    map[uint]deliveryOutcome
+	failPending []uint
+}
+
+func (r *fakeDeliveryRepo) Claim(context.Context, time.Time, time.Time) (entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.queue) == 0 {
+		return entity.WebhookDelivery{}, sql.ErrNoRows
+	}
+	d := r.queue[0]
+	r.queue = r.queue[1:]
+	return d, nil
+}
+
+func (r *fakeDeliveryRepo) MarkDelivered(_ context.Context, id uint, status int, _ string, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcomes[id] = deliveryOutcome{delivered: true, status: &status}
+	return nil
+}
+
+func (r *fakeDeliveryRepo) RecordFailure(_ context.Context, id uint, status *int, _, lastError string, retryAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcomes[id] = deliveryOutcome{status: status, retryAt: retryAt, lastError: lastError}
+	return nil
+}
+
+func (r *fakeDeliveryRepo) FailPending(_ context.Context, webhookID uint, _ string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failPending = append(r.failPending, webhookID)
+	dropped := int64(len(r.queue))
+	r.queue = nil
+	return dropped, nil
+}
+
+func TestWebhookDeliverPending(t *testing.T) {
+	const retryDelay = time.Minute
+	cfg := config.WebhookConfig{
+		Workers:              1,
+		Timeout:              5 * time.Second,
+		MaxAttempts:          4,
+		RetryDelay:           retryDelay,
+		MaxRetryDelay:        3 * retryDelay,
+		DisableAfter:         3,
+		AllowPrivateNetworks: true,
+	}
+
+	type wantOutcome struct {
+		delivered  bool
+		status     int
+		retryAfter time.Duration // zero when no retry is due
+	}
+
+	tests := []struct {
+		name         string
+		status       int
+		failures     int
+		enabled      bool
+		deliveries   []entity.WebhookDelivery
+		want         map[uint]wantOutcome
+		wantFailures int
+		wantEnabled  bool
+		wantDropped  bool
+		wantSuccess  int
+	}{
+		{
+			name:     "accepted delivery resets the failure count",
+			status:   http.StatusOK,
+			failures: 2,
+			enabled:  true,
+			deliveries: []entity.WebhookDelivery{
+				{ID: 1, Event: entity.BillEventPaid, MaxAttempts: 4},
+			},
+			want:         map[uint]wantOutcome{1: {delivered: true, status: http.StatusOK}},
+			wantFailures: 0,
+			wantEnabled:  true,
+			wantSuccess:  1,
+		},
+		{
+			name:    "failures are retried with growing, capped backoff",
+			status:  http.StatusInternalServerError,
+			enabled: true,
+			deliveries: []entity.WebhookDelivery{
+				{ID: 1, Event: entity.BillEventPaid, Attempts: 0, MaxAttempts: 4},
+				{ID: 2, Event: entity.BillEventPaid, Attempts: 1, MaxAttempts: 4},
+			},
+			want: map[uint]wantOutcome{
+				1: {status: http.StatusInternalServerError, retryAfter: retryDelay},
+				2: {status: http.StatusInternalServerError, retryAfter: 2 * retryDelay},
+			},
+			wantFailures: 2,
+			wantEnabled:  true,
+		},
+		{
+			name:    "backoff is capped and the last attempt is not retried",
+			status:  http.StatusBadGateway,
+			enabled: true,
+			deliveries: []entity.WebhookDelivery{
+				{ID: 1, Event: entity.BillEventPaid, Attempts: 2, MaxAttempts: 5},
+				{ID: 2, Event: entity.BillEventPaid, Attempts: 3, MaxAttempts: 4},
+			},
+			want: map[uint]wantOutcome{
+				1: {status: http.StatusBadGateway, retryAfter: 3 * retryDelay},
+				2: {status: http.StatusBadGateway},
+			},
+			wantFailures: 2,
+			wantEnabled:  true,
+		},
+		{
+			name:    "reaching the limit disables the webhook and drops its queue",
+			status:  http.StatusInternalServerError,
+			enabled: true,
+			deliveries: []entity.WebhookDelivery{
+				{ID: 1, Event: entity.BillEventPaid, MaxAttempts: 4},
+				{ID: 2, Event: entity.BillEventOverdue, MaxAttempts: 4},
+				{ID: 3, Event: entity.BillEventDueSoon, MaxAttempts: 4},
+				{ID: 4, Event: entity.BillEventPaid, MaxAttempts: 4},
+			},
+			want: map[uint]wantOutcome{
+				1: {status: http.StatusInternalServerError, retryAfter: retryDelay},
+				2: {status: http.StatusInternalServerError, retryAfter: retryDelay},
+				3: {status: http.StatusInternalServerError, retryAfter: retryDelay},
+			},
+			wantFailures: 3,
+			wantEnabled:  false,
+			wantDropped:  true,
+		},
+		{
+			name:     "disabled webhook fails its deliveries without sending",
+			status:   http.StatusOK,
+			failures: 3,
+			enabled:  false,
+			deliveries: []entity.WebhookDelivery{
+				{ID: 1, Event: entity.BillEventPaid, MaxAttempts: 4},
+			},
+			want:         map[uint]wantOutcome{1: {}},
+			wantFailures: 3,
+			wantEnabled:  false,
+		},
+		{
+			name:     "failed ping does not count towards disabling",
+			status:   http.StatusInternalServerError,
+			failures: 2,
+			enabled:  true,
+			deliveries: []entity.WebhookDelivery{
+				{ID: 1, Event: entity.WebhookEventPing, MaxAttempts: 1},
+			},
+			want:         map[uint]wantOutcome{1: {status: http.StatusInternalServerError}},
+			wantFailures: 2,
+			wantEnabled:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			received := 0
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				received++
+				mu.Unlock()
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			webhooks := &fakeWebhookRepo{webhook: entity.Webhook{
+				ID:                  5,
+				UserID:              9,
+				URL:                 receiver.URL,
+				Secret:              "whsec_test",
+				Enabled:             tt.enabled,
+				ConsecutiveFailures: tt.failures,
+			}}
+			for i := range tt.deliveries {
+				tt.deliveries[i].WebhookID, tt.deliveries[i].UserID = 5, 9
+				tt.deliveries[i].Payload = []byte(`{}`)
+			}
+			deliveries := &fakeDeliveryRepo{
+				queue:    append([]entity.WebhookDelivery(nil), tt.deliveries...),
+				outcomes: map[uint]deliveryOutcome{},
+			}
+
+			u := NewWebhookUsecase(webhooks, deliveries, services.NewWebhookService(cfg), cfg)
+
+			start := time.Now()
+			if _, err := u.DeliverPending(context.Background(), start); err != nil {
+				t.Fatalf("DeliverPending: %v", err)
+			}
+			end := time.Now()
+
+			if len(deliveries.outcomes) != len(tt.want) {
+				t.Errorf("recorded %d outcomes, want %d", len(deliveries.outcomes), len(tt.want))
+			}
+			for id, want := range tt.want {
+				got, ok := deliveries.outcomes[id]
+				if !ok {
+					t.Errorf("delivery %d: no outcome recorded", id)
+					continue
+				}
+				if got.delivered != want.delivered {
+					t.Errorf("delivery %d: delivered = %v, want %v", id, got.delivered, want.delivered)
+				}
+				if want.status == 0 && got.status != nil {
+					t.Errorf("delivery %d: status = %d, want none", id, *got.status)
+				}
+				if want.status != 0 && (got.status == nil || *got.status != want.status) {
+					t.Errorf("delivery %d: status = %v, want %d", id, got.status, want.status)
+				}
+
+				switch {
+				case want.retryAfter == 0 && got.retryAt != nil:
+					t.Errorf("delivery %d: retry at %v, want no retry", id, got.retryAt)
+				case want.retryAfter != 0 && got.retryAt == nil:
+					t.Errorf("delivery %d: no retry, want one after %v", id, want.retryAfter)
+				case want.retryAfter != 0:
+					if got.retryAt.Before(start.Add(want.retryAfter)) || got.retryAt.After(end.Add(want.retryAfter)) {
+						t.Errorf("delivery %d: retry at %v, want %v after the attempt", id, got.retryAt, want.retryAfter)
+					}
+				}
+			}
+
+			if webhooks.webhook.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("consecutive failures = %d, want %d", webhooks.webhook.ConsecutiveFailures, tt.wantFailures)
+			}
+			if webhooks.webhook.Enabled != tt.wantEnabled {
+				t.Errorf("enabled = %v, want %v", webhooks.webhook.Enabled, tt.wantEnabled)
+			}
+			if !tt.wantEnabled && tt.enabled && webhooks.webhook.DisabledAt == nil {
+				t.Error("disabled webhook has no disabled_at")
+			}
+			if dropped := len(deliveries.failPending) > 0; dropped != tt.wantDropped {
+				t.Errorf("pending deliveries dropped = %v, want %v", dropped, tt.wantDropped)
+			}
+			if len(deliveries.failPending) > 1 {
+				t.Errorf("pending deliveries dropped %d times, want once", len(deliveries.failPending))
+			}
+			if webhooks.successes != tt.wantSuccess {
+				t.Errorf("RecordSuccess called %d times, want %d", webhooks.successes, tt.wantSuccess)
+			}
+
+			sent := 0
+			for _, outcome := range deliveries.outcomes {
+				if outcome.status != nil {
+					sent++
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if received != sent {
+				t.Errorf("receiver got %d requests, want %d", received, sent)
+			}
+		})
+	}
+}
@@ -1,18 +1,22 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
+	"financebroke/backend/internal/config"
+
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtSecret = []byte("your-secret-key-change-in-production")
-
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    uint `json:"user_id"`
+	SessionID uint `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -26,22 +30,64 @@ func CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
-func GenerateToken(userID uint) (string, error) {
+// GenerateOpaqueToken returns a random URL-safe token for use as a refresh
+// token or one-time link. Only its HashToken digest should be stored.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex SHA-256 digest of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenManager issues and validates the short-lived access tokens used to
+// authenticate requests. Each access token belongs to a server-side session.
+type TokenManager struct {
+	secret     []byte
+	ttl        time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenManager(cfg config.JWTConfig) *TokenManager {
+	return &TokenManager{secret: []byte(cfg.Secret), ttl: cfg.TTL, refreshTTL: cfg.RefreshTTL}
+}
+
+// RefreshTTL is how long a refresh token stays valid.
+func (m *TokenManager) RefreshTTL() time.Duration {
+	return m.refreshTTL
+}
+
+// GenerateToken issues an access token for the session and returns it with
+// its expiry.
+func (m *TokenManager) GenerateToken(userID, sessionID uint) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.ttl)
 	claims := JWTClaims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	signed, err := token.SignedString(m.secret)
+	return signed, expiresAt, err
 }
 
-func ValidateToken(tokenString string) (*JWTClaims, error) {
+func (m *TokenManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return m.secret, nil
 	})
 
 	if err != nil {
@@ -1,83 +1,141 @@
 package utils
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"runtime"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync/atomic"
 )
 
-type Logger struct{}
+// Log output formats accepted by SetupLogger.
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
 
-func GetLogger() *Logger {
-	return &Logger{}
-}
+type requestIDKey struct{}
 
-type ErrorWithContext struct {
-	Err      error
-	Context  string
-	Function string
-	File     string
-	Line     int
+var baseLogger atomic.Pointer[slog.Logger]
+
+func init() {
+	baseLogger.Store(slog.Default())
 }
 
-func (e *ErrorWithContext) Error() string {
-	return fmt.Sprintf("[%s] %s: %v (%s:%d)", e.Context, e.Function, e.Err, e.File, e.Line)
+// Logger writes structured log lines. Fields passed to its methods become
+// attributes of the line, and a logger bound to a request context also adds
+// the request ID.
+type Logger struct {
+	ctx context.Context
 }
 
-func LogError(ctx string, err error) error {
-	if err == nil {
-		return nil
+// SetupLogger replaces the process-wide logger with one writing the given
+// format ("json" or "text") to w, dropping lines below level. Output of the
+// standard log package is routed through it as well.
+func SetupLogger(w io.Writer, level, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
 	}
 
-	// Get caller info
-	pc, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "unknown"
-		line = 0
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case LogFormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format %q", format)
 	}
 
-	fn := runtime.FuncForPC(pc)
-	if fn == nil {
-		log.Printf("[ERROR] [%s] %v (%s:%d)", ctx, err, file, line)
-		return err
+	logger := slog.New(contextHandler{handler})
+	baseLogger.Store(logger)
+	slog.SetDefault(logger)
+	return nil
+}
+
+// GetLogger returns a logger without request context.
+func GetLogger() *Logger {
+	return &Logger{ctx: context.Background()}
+}
+
+// FromContext returns a logger that tags every line with the request ID
+// carried by ctx, if any.
+func FromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	return &Logger{ctx: ctx}
+}
 
-	funcName := fn.Name()
+// WithRequestID returns a copy of ctx carrying the given request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
 
-	// Log detailed error
-	log.Printf("[ERROR] [%s] %s: %v (%s:%d)", ctx, funcName, err, file, line)
+// RequestIDFromContext returns the request ID stored in ctx, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
 
-	return &ErrorWithContext{
-		Err:      err,
-		Context:  ctx,
-		Function: funcName,
-		File:     file,
-		Line:     line,
-	}
+func (l *Logger) Debug(message string, fields ...map[string]interface{}) {
+	l.log(slog.LevelDebug, message, fields)
 }
 
 func (l *Logger) Info(message string, fields ...map[string]interface{}) {
-	// Get caller info
-	pc, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "unknown"
-		line = 0
-	}
+	l.log(slog.LevelInfo, message, fields)
+}
 
-	fn := runtime.FuncForPC(pc)
-	if fn == nil {
-		log.Printf("[INFO] %s", message)
+func (l *Logger) Warn(message string, fields ...map[string]interface{}) {
+	l.log(slog.LevelWarn, message, fields)
+}
+
+func (l *Logger) Error(message string, fields ...map[string]interface{}) {
+	l.log(slog.LevelError, message, fields)
+}
+
+func (l *Logger) log(level slog.Level, message string, fields []map[string]interface{}) {
+	logger := baseLogger.Load()
+	if !logger.Enabled(l.ctx, level) {
 		return
 	}
 
-	funcName := fn.Name()
+	var attrs []slog.Attr
+	for _, set := range fields {
+		keys := make([]string, 0, len(set))
+		for key := range set {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			attrs = append(attrs, slog.Any(key, set[key]))
+		}
+	}
+
+	logger.LogAttrs(l.ctx, level, message, attrs...)
+}
+
+// LogError logs err at error level under the given context label and returns
+// it unchanged, so it can be used inline.
+func LogError(label string, err error) error {
+	return LogErrorContext(context.Background(), label, err)
+}
 
-	// Log with fields if any
-	if len(fields) > 0 && len(fields[0]) > 0 {
-		log.Printf("[INFO] %s: %s %+v", funcName, message, fields[0])
-	} else {
-		log.Printf("[INFO] %s: %s (%s:%d)", funcName, message, file, line)
+// LogErrorContext is LogError for code that has a request context.
+func LogErrorContext(ctx context.Context, label string, err error) error {
+	if err == nil {
+		return nil
 	}
+
+	FromContext(ctx).Error("["+label+"] Failed", map[string]interface{}{
+		"error": err.Error(),
+	})
+	return err
 }
 
 func WrapError(ctx string, err error, message string) error {
@@ -87,4 +145,24 @@ func WrapError(ctx string, err error, message string) error {
 
 	wrapped := fmt.Errorf("%s: %w", message, err)
 	return LogError(ctx, wrapped)
-}
\ No newline at end of file
+}
+
+// contextHandler adds the request ID from the record's context to every line.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
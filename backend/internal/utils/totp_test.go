@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors,
+// "12345678901234567890", in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTPRFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1. The RFC lists 8-digit codes; a 6-digit
+	// code is the same value's last six digits.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},          // 94287082
+		{unix: 1111111109, code: "081804"},  // 07081804
+		{unix: 1111111111, code: "050471"},  // 14050471
+		{unix: 1234567890, code: "005924"},  // 89005924
+		{unix: 2000000000, code: "279037"},  // 69279037
+		{unix: 20000000000, code: "353130"}, // 65353130
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			now := time.Unix(tt.unix, 0)
+			step, ok := ValidateTOTP(rfc6238Secret, tt.code, now)
+			if !ok {
+				t.Fatalf("ValidateTOTP(%q) at %d rejected the RFC code", tt.code, tt.unix)
+			}
+			if want := tt.unix / 30; step != want {
+				t.Errorf("matched step %d, want %d", step, want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	// 081804 is the code of step 37037036, from 1111111080 to 1111111109
+	const code = "081804"
+	const step = 37037036
+
+	tests := []struct {
+		name   string
+		unix   int64
+		wantOK bool
+	}{
+		{name: "two steps early", unix: 1111111049, wantOK: false},
+		{name: "one step early", unix: 1111111050, wantOK: true},
+		{name: "current step start", unix: 1111111080, wantOK: true},
+		{name: "current step end", unix: 1111111109, wantOK: true},
+		{name: "one step late", unix: 1111111139, wantOK: true},
+		{name: "two steps late", unix: 1111111140, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ValidateTOTP(rfc6238Secret, code, time.Unix(tt.unix, 0))
+			if ok != tt.wantOK {
+				t.Fatalf("ValidateTOTP at %d = %v, want %v", tt.unix, ok, tt.wantOK)
+			}
+			if ok && got != step {
+				t.Errorf("matched step %d, want the code's own step %d", got, step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		wantOK bool
+	}{
+		{name: "spaces are ignored", secret: rfc6238Secret, code: " 287 082 ", wantOK: true},
+		{name: "lowercase secret", secret: strings.ToLower(rfc6238Secret), code: "287082", wantOK: true},
+		{name: "eight digits", secret: rfc6238Secret, code: "94287082", wantOK: false},
+		{name: "too short", secret: rfc6238Secret, code: "28708", wantOK: false},
+		{name: "wrong code", secret: rfc6238Secret, code: "287083", wantOK: false},
+		{name: "secret is not base32", secret: "not base32!", code: "287082", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok != tt.wantOK {
+				t.Errorf("ValidateTOTP(%q, %q) = %v, want %v", tt.secret, tt.code, ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	want := HashRecoveryCode("K7M2Q-P9XRT")
+
+	tests := []struct {
+		code      string
+		wantMatch bool
+	}{
+		{code: "K7M2Q-P9XRT", wantMatch: true},
+		{code: "k7m2q-p9xrt", wantMatch: true},
+		{code: "K7M2QP9XRT", wantMatch: true},
+		{code: "K7M2Q P9XRT", wantMatch: true},
+		{code: " k7m2q - p9xrt ", wantMatch: true},
+		{code: "K7M2Q-P9XRU", wantMatch: false},
+		{code: "K7M2Q", wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if match := HashRecoveryCode(tt.code) == want; match != tt.wantMatch {
+				t.Errorf("HashRecoveryCode(%q) matches %v, want %v", tt.code, match, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	code, err := GenerateRecoveryCode()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCode: %v", err)
+	}
+	if len(code) != 11 || code[5] != '-' {
+		t.Fatalf("code %q is not XXXXX-XXXXX", code)
+	}
+	for _, c := range strings.Replace(code, "-", "", 1) {
+		if !strings.ContainsRune(recoveryCodeAlphabet, c) {
+			t.Errorf("code %q has %q, which is not in the recovery code alphabet", code, c)
+		}
+	}
+}
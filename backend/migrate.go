@@ -1,60 +0,0 @@
-package main
-
-import (
-	"database/sql"
-	"fmt"
-	"log"
-	"os"
-
-	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/joho/godotenv"
-)
-
-func main() {
-	// Load .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("No .env file found")
-	}
-
-	// Connect to database
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		getEnv("DB_HOST", "localhost"),
-		getEnv("DB_USER", "postgres"),
-		getEnv("DB_PASSWORD", "secret"),
-		getEnv("DB_NAME", "financetok"),
-		getEnv("DB_PORT", "5432"),
-	)
-
-	db, err := sql.Open("pgx", dsn)
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
-	}
-	defer db.Close()
-
-	// Test connection
-	err = db.Ping()
-	if err != nil {
-		log.Fatal("Failed to ping database:", err)
-	}
-
-	// Read and execute migration file
-	migrationSQL, err := os.ReadFile("migrations/001_create_tables.sql")
-	if err != nil {
-		log.Fatal("Failed to read migration file:", err)
-	}
-
-	_, err = db.Exec(string(migrationSQL))
-	if err != nil {
-		log.Fatal("Failed to execute migration:", err)
-	}
-
-	fmt.Println("Migration completed successfully!")
-}
-
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return defaultValue
-}
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS update_bills_updated_at ON bills;
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bills_pending_reminder;
+ALTER TABLE bills DROP COLUMN IF EXISTS reminded_at;
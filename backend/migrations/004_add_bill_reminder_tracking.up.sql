@@ -0,0 +1,5 @@
+-- Track when the reminder for a bill was delivered so the scheduler does not repeat it
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMP WITH TIME ZONE;
+
+-- Speed up the scheduler scan for bills still waiting for a reminder
+CREATE INDEX IF NOT EXISTS idx_bills_pending_reminder ON bills(due_date) WHERE reminded_at IS NULL AND status != 'paid';
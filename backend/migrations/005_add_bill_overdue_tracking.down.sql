@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bills_unpaid_due_date;
+ALTER TABLE bills DROP COLUMN IF EXISTS overdue_notified_at;
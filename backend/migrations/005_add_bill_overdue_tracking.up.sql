@@ -0,0 +1,5 @@
+-- Track when the overdue notice for a bill was delivered so it is sent only once
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS overdue_notified_at TIMESTAMP WITH TIME ZONE;
+
+-- Speed up the scheduler scan for unpaid bills that have passed their due date
+CREATE INDEX IF NOT EXISTS idx_bills_unpaid_due_date ON bills(due_date) WHERE status = 'unpaid';
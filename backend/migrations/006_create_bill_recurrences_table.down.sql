@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_bills_recurrence_occurrence;
+ALTER TABLE bills DROP COLUMN IF EXISTS occurrence_index;
+ALTER TABLE bills DROP COLUMN IF EXISTS recurrence_id;
+DROP TABLE IF EXISTS bill_recurrences;
@@ -0,0 +1,33 @@
+-- Create bill recurrences table
+CREATE TABLE IF NOT EXISTS bill_recurrences (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    interval_count INTEGER NOT NULL DEFAULT 1 CHECK (interval_count > 0),
+    anchor_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    anchor_index INTEGER NOT NULL DEFAULT 0,
+    end_date TIMESTAMP WITH TIME ZONE,
+    max_occurrences INTEGER CHECK (max_occurrences > 0),
+    next_index INTEGER NOT NULL DEFAULT 0,
+    name VARCHAR(255) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    description TEXT,
+    remind_before INTEGER DEFAULT 3,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bill_recurrences_user_id ON bill_recurrences(user_id);
+CREATE INDEX IF NOT EXISTS idx_bill_recurrences_active ON bill_recurrences(active) WHERE active;
+
+-- Link bills to the recurrence that generated them
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS recurrence_id INTEGER REFERENCES bill_recurrences(id) ON DELETE SET NULL;
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS occurrence_index INTEGER;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bills_recurrence_occurrence ON bills(recurrence_id, occurrence_index);
+
+DROP TRIGGER IF EXISTS update_bill_recurrences_updated_at ON bill_recurrences;
+CREATE TRIGGER update_bill_recurrences_updated_at
+    BEFORE UPDATE ON bill_recurrences
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
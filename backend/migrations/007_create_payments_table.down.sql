@@ -0,0 +1,6 @@
+-- Partially paid bills fall back to unpaid
+UPDATE bills SET status = 'unpaid' WHERE status = 'partially_paid';
+ALTER TABLE bills DROP CONSTRAINT IF EXISTS bills_status_check;
+ALTER TABLE bills ADD CONSTRAINT bills_status_check CHECK (status IN ('unpaid', 'paid', 'overdue'));
+
+DROP TABLE IF EXISTS payments;
@@ -0,0 +1,35 @@
+-- Create payments table
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    bill_id INTEGER NOT NULL REFERENCES bills(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    paid_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    method VARCHAR(50) NOT NULL DEFAULT 'other' CHECK (method IN ('cash', 'bank_transfer', 'card', 'e_wallet', 'other')),
+    reference VARCHAR(255),
+    note TEXT,
+    voided_at TIMESTAMP WITH TIME ZONE,
+    void_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_bill_id ON payments(bill_id);
+CREATE INDEX IF NOT EXISTS idx_payments_user_id_paid_at ON payments(user_id, paid_at);
+
+DROP TRIGGER IF EXISTS update_payments_updated_at ON payments;
+CREATE TRIGGER update_payments_updated_at
+    BEFORE UPDATE ON payments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Bills can now be partially paid
+ALTER TABLE bills DROP CONSTRAINT IF EXISTS bills_status_check;
+ALTER TABLE bills ADD CONSTRAINT bills_status_check CHECK (status IN ('unpaid', 'partially_paid', 'paid', 'overdue'));
+
+-- Preserve paid totals for bills marked paid before payments were tracked
+INSERT INTO payments (bill_id, user_id, amount, paid_at, method, note)
+SELECT b.id, b.user_id, b.amount, b.updated_at, 'other', 'Recorded before payment tracking'
+FROM bills b
+WHERE b.status = 'paid' AND b.amount > 0
+    AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.bill_id = b.id);
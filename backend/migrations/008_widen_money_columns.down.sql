@@ -0,0 +1,7 @@
+ALTER TABLE bill_recurrences DROP CONSTRAINT IF EXISTS bill_recurrences_amount_check;
+ALTER TABLE bills DROP CONSTRAINT IF EXISTS bills_amount_check;
+
+-- Fails if any stored amount no longer fits
+ALTER TABLE payments ALTER COLUMN amount TYPE DECIMAL(10,2);
+ALTER TABLE bill_recurrences ALTER COLUMN amount TYPE DECIMAL(10,2);
+ALTER TABLE bills ALTER COLUMN amount TYPE DECIMAL(10,2);
@@ -0,0 +1,10 @@
+-- Store money as exact decimals wide enough for large balances
+ALTER TABLE bills ALTER COLUMN amount TYPE NUMERIC(18,2);
+ALTER TABLE bill_recurrences ALTER COLUMN amount TYPE NUMERIC(18,2);
+ALTER TABLE payments ALTER COLUMN amount TYPE NUMERIC(18,2);
+
+ALTER TABLE bills DROP CONSTRAINT IF EXISTS bills_amount_check;
+ALTER TABLE bills ADD CONSTRAINT bills_amount_check CHECK (amount >= 0);
+
+ALTER TABLE bill_recurrences DROP CONSTRAINT IF EXISTS bill_recurrences_amount_check;
+ALTER TABLE bill_recurrences ADD CONSTRAINT bill_recurrences_amount_check CHECK (amount >= 0);
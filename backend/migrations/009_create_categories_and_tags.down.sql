@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS bill_tags;
+ALTER TABLE bill_recurrences DROP COLUMN IF EXISTS category_id;
+ALTER TABLE bills DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS categories;
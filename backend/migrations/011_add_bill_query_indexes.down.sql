@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS idx_bills_user_id ON bills(user_id);
+
+DROP INDEX IF EXISTS idx_bills_name_trgm;
+DROP INDEX IF EXISTS idx_bills_user_created_at;
+DROP INDEX IF EXISTS idx_bills_user_amount;
+DROP INDEX IF EXISTS idx_bills_user_status_due_date;
+DROP INDEX IF EXISTS idx_bills_user_due_date;
@@ -0,0 +1,11 @@
+-- Indexes backing filtered, sorted and paginated bill listings
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_bills_user_due_date ON bills(user_id, due_date, id);
+CREATE INDEX IF NOT EXISTS idx_bills_user_status_due_date ON bills(user_id, status, due_date);
+CREATE INDEX IF NOT EXISTS idx_bills_user_amount ON bills(user_id, amount);
+CREATE INDEX IF NOT EXISTS idx_bills_user_created_at ON bills(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_bills_name_trgm ON bills USING gin (name gin_trgm_ops);
+
+-- Covered by the composite indexes above
+DROP INDEX IF EXISTS idx_bills_user_id;
@@ -0,0 +1,3 @@
+ALTER TABLE user_tokens DROP COLUMN IF EXISTS payload;
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
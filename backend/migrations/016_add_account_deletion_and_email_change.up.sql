@@ -0,0 +1,8 @@
+-- Accounts scheduled for deletion are removed, with everything that cascades
+-- from them, once this time passes
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Extra data carried by a token, such as the new address for an email change
+ALTER TABLE user_tokens ADD COLUMN IF NOT EXISTS payload TEXT NOT NULL DEFAULT '';
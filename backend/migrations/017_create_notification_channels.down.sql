@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS telegram_chat_id VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_notify BOOLEAN DEFAULT true;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS telegram_notify BOOLEAN DEFAULT false;
+
+UPDATE users u SET email_notify = c.enabled
+FROM user_notification_channels c
+WHERE c.user_id = u.id AND c.channel = 'email';
+
+UPDATE users u SET telegram_notify = c.enabled, telegram_chat_id = NULLIF(c.address, '')
+FROM user_notification_channels c
+WHERE c.user_id = u.id AND c.channel = 'telegram';
+
+DROP TABLE IF EXISTS user_notification_channels;
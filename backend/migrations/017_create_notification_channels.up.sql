@@ -0,0 +1,26 @@
+-- Per-user notification settings, one row per channel. address is where the
+-- user is reached on the channel, such as a Telegram chat ID; channels that
+-- use the account's email address leave it empty. Channels without a row use
+-- their default.
+CREATE TABLE IF NOT EXISTS user_notification_channels (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(32) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, channel)
+);
+
+INSERT INTO user_notification_channels (user_id, channel, enabled)
+SELECT id, 'email', COALESCE(email_notify, true) FROM users
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_notification_channels (user_id, channel, enabled, address)
+SELECT id, 'telegram', COALESCE(telegram_notify, false), COALESCE(telegram_chat_id, '') FROM users
+WHERE telegram_notify OR COALESCE(telegram_chat_id, '') <> ''
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS email_notify;
+ALTER TABLE users DROP COLUMN IF EXISTS telegram_notify;
+ALTER TABLE users DROP COLUMN IF EXISTS telegram_chat_id;
@@ -0,0 +1,32 @@
+-- Outbox of notifications. Each row is one message for one channel, delivered
+-- by a background worker and retried with backoff until it is sent or runs
+-- out of attempts. payload holds what the channel needs to render it.
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(32) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    bill_id INTEGER REFERENCES bills(id) ON DELETE SET NULL,
+    -- Distinguishes repeated notifications about the same bill, such as a
+    -- reminder for a new due date
+    reference VARCHAR(64) NOT NULL DEFAULT '',
+    recipient VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- A worker holds a row in 'sending' until this time; after it the row
+    -- can be claimed again, e.g. when the worker crashed
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    provider_response TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A bill gets at most one notification of each kind per channel and reference
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_bill_dedupe ON notifications(bill_id, channel, kind, reference) WHERE bill_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_due ON notifications(next_attempt_at) WHERE status IN ('pending', 'sending');
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at DESC);
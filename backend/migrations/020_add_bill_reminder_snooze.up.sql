@@ -0,0 +1,2 @@
+-- Snoozing a bill's reminder from Telegram re-arms it for this time
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS reminder_snoozed_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_user_notification_channels_address;
@@ -0,0 +1,13 @@
+-- An address, such as a Telegram chat the bot takes commands from, belongs to
+-- one account. Addresses saved by several accounts are kept only for the one
+-- that saved it last.
+UPDATE user_notification_channels c
+SET address = '', enabled = false, updated_at = CURRENT_TIMESTAMP
+WHERE c.address <> '' AND EXISTS (
+    SELECT 1 FROM user_notification_channels o
+    WHERE o.channel = c.channel AND o.address = c.address
+        AND (COALESCE(o.updated_at, 'epoch'), o.user_id) > (COALESCE(c.updated_at, 'epoch'), c.user_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_notification_channels_address
+    ON user_notification_channels(channel, address) WHERE address <> '';
@@ -0,0 +1 @@
+ALTER TABLE bill_recurrences DROP COLUMN IF EXISTS anchor_day;
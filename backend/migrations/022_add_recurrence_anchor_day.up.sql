@@ -0,0 +1,3 @@
+-- The day of month monthly and yearly schedules fall on when the anchor date
+-- itself was clamped to a shorter month; 0 means the anchor date's own day
+ALTER TABLE bill_recurrences ADD COLUMN IF NOT EXISTS anchor_day SMALLINT NOT NULL DEFAULT 0;
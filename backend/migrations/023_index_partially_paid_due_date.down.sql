@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bills_unpaid_due_date;
+CREATE INDEX IF NOT EXISTS idx_bills_unpaid_due_date ON bills(due_date) WHERE status = 'unpaid';
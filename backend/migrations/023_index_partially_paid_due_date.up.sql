@@ -0,0 +1,4 @@
+-- Partially paid bills go overdue too, so the scheduler scan needs them in the
+-- unpaid due date index
+DROP INDEX IF EXISTS idx_bills_unpaid_due_date;
+CREATE INDEX IF NOT EXISTS idx_bills_unpaid_due_date ON bills(due_date) WHERE status IN ('unpaid', 'partially_paid');
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL schema migrations. Each migration is a
+// pair of NNN_name.up.sql and NNN_name.down.sql files, applied in order of
+// their number.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -1,12 +1,15 @@
 import React, { createContext, useContext, useState, useEffect, ReactNode } from 'react';
-import { User, LoginRequest, RegisterRequest } from '../types';
-import { authApi } from '../services/api';
+import { User, LoginRequest, RegisterRequest, AuthResponse, TwoFactorLoginRequest } from '../types';
+import { authApi, storeSession, clearSession } from '../services/api';
 
 interface AuthContextType {
   user: User | null;
-  login: (data: LoginRequest) => Promise<void>;
+  // login resolves to a challenge token when a second factor is required
+  login: (data: LoginRequest) => Promise<string | null>;
+  loginTwoFactor: (data: TwoFactorLoginRequest) => Promise<void>;
   register: (data: RegisterRequest) => Promise<void>;
   logout: () => void;
+  refreshUser: () => Promise<void>;
   loading: boolean;
 }
 
@@ -40,29 +43,49 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
 
   const login = async (data: LoginRequest) => {
     const response = await authApi.login(data);
-    localStorage.setItem('token', response.token);
-    localStorage.setItem('user', JSON.stringify(response.user));
+    if (response.two_factor_required) {
+      return response.challenge_token || null;
+    }
+    storeSession(response as AuthResponse);
+    setUser((response as AuthResponse).user);
+    return null;
+  };
+
+  const loginTwoFactor = async (data: TwoFactorLoginRequest) => {
+    const response = await authApi.loginTwoFactor(data);
+    storeSession(response);
     setUser(response.user);
   };
 
   const register = async (data: RegisterRequest) => {
     const response = await authApi.register(data);
-    localStorage.setItem('token', response.token);
-    localStorage.setItem('user', JSON.stringify(response.user));
+    storeSession(response);
     setUser(response.user);
   };
 
   const logout = () => {
-    localStorage.removeItem('token');
-    localStorage.removeItem('user');
+    // Revoke the session server-side; log out locally even if that fails
+    const token = localStorage.getItem('token');
+    clearSession();
+    if (token) {
+      authApi.logout(token).catch(() => {});
+    }
     setUser(null);
   };
 
+  const refreshUser = async () => {
+    const profile = await authApi.getProfile();
+    localStorage.setItem('user', JSON.stringify(profile));
+    setUser(profile);
+  };
+
   const value = {
     user,
     login,
+    loginTwoFactor,
     register,
     logout,
+    refreshUser,
     loading,
   };
 
@@ -30,7 +30,7 @@ const BillsPage: React.FC = () => {
   const fetchBills = async () => {
     try {
       setError(null);
-      const data = await billApi.getBills();
+      const { data } = await billApi.getBills();
       setBills(data);
     } catch (err: any) {
       console.error('Failed to fetch bills:', err);
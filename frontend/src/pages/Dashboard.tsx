@@ -2,13 +2,14 @@ import React, { useState, useEffect } from 'react';
 import { Link } from 'react-router-dom';
 import { useAuth } from '../contexts/AuthContext';
 import { Bill, DashboardResponse } from '../types';
-import { dashboardApi, billApi } from '../services/api';
+import { dashboardApi, billApi, authApi } from '../services/api';
 import Logo from '../components/Logo';
 
 const Dashboard: React.FC = () => {
-  const { user, logout } = useAuth();
+  const { user, logout, refreshUser } = useAuth();
   const [dashboard, setDashboard] = useState<DashboardResponse | null>(null);
   const [loading, setLoading] = useState(true);
+  const [verificationMessage, setVerificationMessage] = useState('');
 
   useEffect(() => {
     fetchDashboard();
@@ -25,6 +26,24 @@ const Dashboard: React.FC = () => {
     }
   };
 
+  const resendVerification = async () => {
+    try {
+      const response = await authApi.resendVerification();
+      setVerificationMessage(response.message);
+    } catch (err: any) {
+      setVerificationMessage(err.response?.data?.error || 'Failed to send verification email');
+    }
+  };
+
+  const restoreAccount = async () => {
+    try {
+      await authApi.restoreAccount();
+      await refreshUser();
+    } catch (err) {
+      console.error('Failed to restore account:', err);
+    }
+  };
+
   const markAsPaid = async (billId: number) => {
     try {
       await billApi.updateBill(billId, { status: 'paid' });
@@ -100,6 +119,30 @@ const Dashboard: React.FC = () => {
         <div className="absolute top-32 right-10 w-16 h-16 bg-blue-400 border-2 border-black -z-10"></div>
         <div className="absolute bottom-20 left-10 w-12 h-12 bg-pink-400 border-2 border-black -z-10"></div>
 
+        {/* Pending account deletion */}
+        {user?.deletion_scheduled_at && (
+          <div className="alert-brutal alert-brutal-error mb-8 flex items-center justify-between">
+            <span>
+              This account will be deleted on {new Date(user.deletion_scheduled_at).toLocaleDateString()}.
+            </span>
+            <button onClick={restoreAccount} className="btn-brutal ml-4">
+              Keep My Account
+            </button>
+          </div>
+        )}
+
+        {/* Email verification */}
+        {user && !user.email_verified_at && (
+          <div className="alert-brutal alert-brutal-error mb-8 flex items-center justify-between">
+            <span>
+              {verificationMessage || 'Please confirm your email address. Bill reminders are not emailed until you do.'}
+            </span>
+            <button onClick={resendVerification} className="btn-brutal ml-4">
+              Resend Email
+            </button>
+          </div>
+        )}
+
         {/* Stats Grid */}
         {dashboard && (
           <div className="grid grid-cols-1 gap-6 sm:grid-cols-2 lg:grid-cols-4 mb-12">
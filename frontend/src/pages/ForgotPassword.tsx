@@ -0,0 +1,90 @@
+import React, { useState } from 'react';
+import { Link } from 'react-router-dom';
+import { authApi } from '../services/api';
+import Logo from '../components/Logo';
+
+const ForgotPassword: React.FC = () => {
+  const [email, setEmail] = useState('');
+  const [message, setMessage] = useState('');
+  const [error, setError] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError('');
+
+    try {
+      const response = await authApi.forgotPassword({ email });
+      setMessage(response.message);
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Request failed');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-gradient-to-br from-yellow-200 via-pink-200 to-purple-200 flex items-center justify-center p-4">
+      <div className="relative w-full max-w-md">
+        <div className="card-brutal p-8 ">
+          <div className="text-center mb-8">
+            <Logo size="large" />
+            <p className="text-lg font-bold uppercase tracking-wider text-gray-700 mt-4">
+              Forgot Password
+            </p>
+          </div>
+
+          {error && (
+            <div className="alert-brutal alert-brutal-error mb-6 ">
+              {error}
+            </div>
+          )}
+
+          {message ? (
+            <div className="alert-brutal alert-brutal-success mb-6 ">
+              {message}
+            </div>
+          ) : (
+            <form onSubmit={handleSubmit} className="space-y-6">
+              <div>
+                <label htmlFor="email" className="block text-sm font-bold uppercase mb-2">
+                  Email Address
+                </label>
+                <input
+                  id="email"
+                  name="email"
+                  type="email"
+                  required
+                  value={email}
+                  onChange={(e) => setEmail(e.target.value)}
+                  className="input-brutal w-full"
+                  placeholder="you@email.com"
+                />
+              </div>
+
+              <button
+                type="submit"
+                disabled={loading}
+                className="btn-brutal btn-brutal-primary w-full"
+              >
+                {loading ? 'Sending...' : 'Send Reset Link'}
+              </button>
+            </form>
+          )}
+
+          <div className="mt-8 text-center">
+            <Link
+              to="/login"
+              className="inline-block font-bold text-black border-b-2 border-black  "
+            >
+              Back to sign in
+            </Link>
+          </div>
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default ForgotPassword;
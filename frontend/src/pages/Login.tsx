@@ -11,8 +11,10 @@ const Login: React.FC = () => {
   });
   const [error, setError] = useState('');
   const [loading, setLoading] = useState(false);
+  const [challengeToken, setChallengeToken] = useState<string | null>(null);
+  const [code, setCode] = useState('');
 
-  const { login } = useAuth();
+  const { login, loginTwoFactor } = useAuth();
   const navigate = useNavigate();
 
   const handleChange = (e: React.ChangeEvent<HTMLInputElement>) => {
@@ -28,8 +30,23 @@ const Login: React.FC = () => {
     setError('');
 
     try {
-      await login(formData);
-      navigate('/dashboard');
+      if (challengeToken) {
+        // Authenticator codes are digits only; anything else is a recovery code
+        const isTotp = /^\d{6}$/.test(code.replace(/\s/g, ''));
+        await loginTwoFactor({
+          challenge_token: challengeToken,
+          ...(isTotp ? { code } : { recovery_code: code }),
+        });
+        navigate('/dashboard');
+        return;
+      }
+
+      const challenge = await login(formData);
+      if (challenge) {
+        setChallengeToken(challenge);
+      } else {
+        navigate('/dashboard');
+      }
     } catch (err: any) {
       setError(err.response?.data?.error || 'Login failed');
     } finally {
@@ -61,37 +78,59 @@ const Login: React.FC = () => {
           )}
 
           <form onSubmit={handleSubmit} className="space-y-6">
-            <div>
-              <label htmlFor="email" className="block text-sm font-bold uppercase mb-2">
-                Email Address
-              </label>
-              <input
-                id="email"
-                name="email"
-                type="email"
-                required
-                value={formData.email}
-                onChange={handleChange}
-                className="input-brutal w-full"
-                placeholder="you@email.com"
-              />
-            </div>
+            {challengeToken ? (
+              <div>
+                <label htmlFor="code" className="block text-sm font-bold uppercase mb-2">
+                  Authentication Code
+                </label>
+                <input
+                  id="code"
+                  name="code"
+                  type="text"
+                  autoComplete="one-time-code"
+                  required
+                  autoFocus
+                  value={code}
+                  onChange={(e) => setCode(e.target.value)}
+                  className="input-brutal w-full"
+                  placeholder="123456 or recovery code"
+                />
+              </div>
+            ) : (
+              <>
+                <div>
+                  <label htmlFor="email" className="block text-sm font-bold uppercase mb-2">
+                    Email Address
+                  </label>
+                  <input
+                    id="email"
+                    name="email"
+                    type="email"
+                    required
+                    value={formData.email}
+                    onChange={handleChange}
+                    className="input-brutal w-full"
+                    placeholder="you@email.com"
+                  />
+                </div>
 
-            <div>
-              <label htmlFor="password" className="block text-sm font-bold uppercase mb-2">
-                Password
-              </label>
-              <input
-                id="password"
-                name="password"
-                type="password"
-                required
-                value={formData.password}
-                onChange={handleChange}
-                className="input-brutal w-full"
-                placeholder="••••••••"
-              />
-            </div>
+                <div>
+                  <label htmlFor="password" className="block text-sm font-bold uppercase mb-2">
+                    Password
+                  </label>
+                  <input
+                    id="password"
+                    name="password"
+                    type="password"
+                    required
+                    value={formData.password}
+                    onChange={handleChange}
+                    className="input-brutal w-full"
+                    placeholder="••••••••"
+                  />
+                </div>
+              </>
+            )}
 
             <button
               type="submit"
@@ -100,17 +139,26 @@ const Login: React.FC = () => {
             >
               {loading ? (
                 <span className="flex items-center justify-center">
-                  <span className="animate-pulse">Signing in...</span>
+                  <span className="animate-pulse">{challengeToken ? 'Verifying...' : 'Signing in...'}</span>
                 </span>
               ) : (
                 <span className="flex items-center justify-center">
-                  Sign In
+                  {challengeToken ? 'Verify' : 'Sign In'}
                   <span className="ml-2">→</span>
                 </span>
               )}
             </button>
           </form>
 
+          <div className="mt-4 text-center">
+            <Link
+              to="/forgot-password"
+              className="inline-block text-sm font-bold text-gray-700 border-b-2 border-gray-700  "
+            >
+              Forgot your password?
+            </Link>
+          </div>
+
           <div className="mt-8 text-center">
             <Link
               to="/register"
@@ -0,0 +1,114 @@
+import React, { useState } from 'react';
+import { Link, useNavigate, useSearchParams } from 'react-router-dom';
+import { authApi } from '../services/api';
+import Logo from '../components/Logo';
+
+const ResetPassword: React.FC = () => {
+  const [searchParams] = useSearchParams();
+  const [formData, setFormData] = useState({
+    password: '',
+    confirm_password: '',
+  });
+  const [error, setError] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  const navigate = useNavigate();
+  const token = searchParams.get('token') || '';
+
+  const handleChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+    setFormData({
+      ...formData,
+      [e.target.name]: e.target.value,
+    });
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError('');
+
+    try {
+      await authApi.resetPassword({ token, ...formData });
+      navigate('/login');
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Password reset failed');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-gradient-to-br from-yellow-200 via-pink-200 to-purple-200 flex items-center justify-center p-4">
+      <div className="relative w-full max-w-md">
+        <div className="card-brutal p-8 ">
+          <div className="text-center mb-8">
+            <Logo size="large" />
+            <p className="text-lg font-bold uppercase tracking-wider text-gray-700 mt-4">
+              Choose a New Password
+            </p>
+          </div>
+
+          {error && (
+            <div className="alert-brutal alert-brutal-error mb-6 ">
+              {error}
+            </div>
+          )}
+
+          <form onSubmit={handleSubmit} className="space-y-6">
+            <div>
+              <label htmlFor="password" className="block text-sm font-bold uppercase mb-2">
+                New Password
+              </label>
+              <input
+                id="password"
+                name="password"
+                type="password"
+                required
+                minLength={6}
+                value={formData.password}
+                onChange={handleChange}
+                className="input-brutal w-full"
+                placeholder="••••••••"
+              />
+            </div>
+
+            <div>
+              <label htmlFor="confirm_password" className="block text-sm font-bold uppercase mb-2">
+                Confirm Password
+              </label>
+              <input
+                id="confirm_password"
+                name="confirm_password"
+                type="password"
+                required
+                value={formData.confirm_password}
+                onChange={handleChange}
+                className="input-brutal w-full"
+                placeholder="••••••••"
+              />
+            </div>
+
+            <button
+              type="submit"
+              disabled={loading || !token}
+              className="btn-brutal btn-brutal-primary w-full"
+            >
+              {loading ? 'Saving...' : 'Reset Password'}
+            </button>
+          </form>
+
+          <div className="mt-8 text-center">
+            <Link
+              to="/forgot-password"
+              className="inline-block font-bold text-black border-b-2 border-black  "
+            >
+              Request a new link
+            </Link>
+          </div>
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default ResetPassword;
@@ -0,0 +1,63 @@
+import React, { useEffect, useRef, useState } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
+import { useAuth } from '../contexts/AuthContext';
+import { authApi } from '../services/api';
+import Logo from '../components/Logo';
+
+const VerifyEmail: React.FC = () => {
+  const [searchParams] = useSearchParams();
+  const [message, setMessage] = useState('Verifying your email...');
+  const [error, setError] = useState('');
+  const { user, refreshUser } = useAuth();
+  const submitted = useRef(false);
+
+  useEffect(() => {
+    // Tokens are single-use, so only submit once
+    if (submitted.current) {
+      return;
+    }
+    submitted.current = true;
+
+    const token = searchParams.get('token') || '';
+    authApi.verifyEmail(token)
+      .then((response) => {
+        setMessage(response.message);
+        if (user) {
+          refreshUser().catch(() => {});
+        }
+      })
+      .catch((err: any) => {
+        setError(err.response?.data?.error || 'Verification failed');
+      });
+  }, [searchParams, user, refreshUser]);
+
+  return (
+    <div className="min-h-screen bg-gradient-to-br from-yellow-200 via-pink-200 to-purple-200 flex items-center justify-center p-4">
+      <div className="relative w-full max-w-md">
+        <div className="card-brutal p-8 ">
+          <div className="text-center mb-8">
+            <Logo size="large" />
+            <p className="text-lg font-bold uppercase tracking-wider text-gray-700 mt-4">
+              Email Verification
+            </p>
+          </div>
+
+          <div className={`alert-brutal ${error ? 'alert-brutal-error' : 'alert-brutal-success'} mb-6 `}>
+            {error || message}
+          </div>
+
+          <div className="mt-8 text-center">
+            <Link
+              to={user ? '/dashboard' : '/login'}
+              className="inline-block font-bold text-black border-b-2 border-black  "
+            >
+              Continue
+            </Link>
+          </div>
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default VerifyEmail;
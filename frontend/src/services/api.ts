@@ -1,5 +1,5 @@
 import axios from 'axios';
-import { LoginRequest, RegisterRequest, AuthResponse, Bill, BillCreateRequest, BillUpdateRequest, DashboardResponse } from '../types';
+import { User, LoginRequest, LoginResponse, TwoFactorLoginRequest, RegisterRequest, UpdateProfileRequest, ChangePasswordRequest, ChangeEmailRequest, AuthResponse, ForgotPasswordRequest, ResetPasswordRequest, Bill, BillCreateRequest, BillUpdateRequest, BillListQuery, BillListResponse, DashboardResponse } from '../types';
 
 const API_BASE_URL = process.env.REACT_APP_API_URL || 'http://localhost:8080/api/v1';
 
@@ -19,27 +19,78 @@ api.interceptors.request.use((config) => {
   return config;
 });
 
-// Handle token expiration
+export const storeSession = (response: AuthResponse) => {
+  localStorage.setItem('token', response.token);
+  localStorage.setItem('refresh_token', response.refresh_token);
+  localStorage.setItem('user', JSON.stringify(response.user));
+};
+
+export const clearSession = () => {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refresh_token');
+  localStorage.removeItem('user');
+};
+
+// Concurrent 401s share one refresh, since each refresh token works only once
+let refreshing: Promise<string> | null = null;
+
+const refreshAccessToken = (): Promise<string> => {
+  if (!refreshing) {
+    const refreshToken = localStorage.getItem('refresh_token');
+    refreshing = (refreshToken
+      ? axios.post<AuthResponse>(`${API_BASE_URL}/refresh`, { refresh_token: refreshToken }).then((res) => {
+          storeSession(res.data);
+          return res.data.token;
+        })
+      : Promise.reject(new Error('No refresh token'))
+    ).finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+};
+
+// Renew expired access tokens, logging out when the session is gone
 api.interceptors.response.use(
   (response) => response,
-  (error) => {
-    if (error.response?.status === 401) {
-      localStorage.removeItem('token');
-      localStorage.removeItem('user');
-      window.location.href = '/login';
+  async (error) => {
+    const original = error.config;
+    const isAuthRequest = ['/login', '/login/2fa', '/register', '/refresh', '/logout', '/password/reset', '/email/verify'].includes(original?.url);
+    if (error.response?.status === 401 && original && !original._retried && !isAuthRequest) {
+      original._retried = true;
+      try {
+        const token = await refreshAccessToken();
+        original.headers.Authorization = `Bearer ${token}`;
+        return api(original);
+      } catch {
+        clearSession();
+        window.location.href = '/login';
+      }
     }
     return Promise.reject(error);
   }
 );
 
 export const authApi = {
-  login: (data: LoginRequest): Promise<AuthResponse> => api.post('/login', data).then(res => res.data),
+  login: (data: LoginRequest): Promise<LoginResponse> => api.post('/login', data).then(res => res.data),
+  loginTwoFactor: (data: TwoFactorLoginRequest): Promise<AuthResponse> => api.post('/login/2fa', data).then(res => res.data),
   register: (data: RegisterRequest): Promise<AuthResponse> => api.post('/register', data).then(res => res.data),
-  getProfile: (): Promise<any> => api.get('/profile').then(res => res.data),
+  getProfile: (): Promise<User> => api.get('/profile').then(res => res.data),
+  updateProfile: (data: UpdateProfileRequest): Promise<User> => api.put('/profile', data).then(res => res.data),
+  changePassword: (data: ChangePasswordRequest): Promise<{ message: string }> => api.put('/profile/password', data).then(res => res.data),
+  changeEmail: (data: ChangeEmailRequest): Promise<{ message: string }> => api.post('/profile/email', data).then(res => res.data),
+  deleteAccount: (password: string): Promise<{ message: string; deletion_scheduled_at: string }> => api.delete('/profile', { data: { password } }).then(res => res.data),
+  restoreAccount: (): Promise<User> => api.post('/profile/restore').then(res => res.data),
+  logout: (token: string): Promise<void> => api.post('/logout', null, { headers: { Authorization: `Bearer ${token}` } }).then(res => res.data),
+  logoutAll: (): Promise<void> => api.post('/logout-all').then(res => res.data),
+  forgotPassword: (data: ForgotPasswordRequest): Promise<{ message: string }> => api.post('/password/forgot', data).then(res => res.data),
+  resetPassword: (data: ResetPasswordRequest): Promise<{ message: string }> => api.post('/password/reset', data).then(res => res.data),
+  verifyEmail: (token: string): Promise<{ message: string }> => api.post('/email/verify', { token }).then(res => res.data),
+  resendVerification: (): Promise<{ message: string }> => api.post('/email/verify/resend').then(res => res.data),
 };
 
 export const billApi = {
-  getBills: (): Promise<Bill[]> => api.get('/bills').then(res => res.data),
+  getBills: (params: BillListQuery = { page_size: 100 }): Promise<BillListResponse> => api.get('/bills', { params }).then(res => res.data),
   getBill: (id: number): Promise<Bill> => api.get(`/bills/${id}`).then(res => res.data),
   createBill: (data: BillCreateRequest): Promise<Bill> => api.post('/bills', data).then(res => res.data),
   updateBill: (id: number, data: BillUpdateRequest): Promise<Bill> => api.put(`/bills/${id}`, data).then(res => res.data),
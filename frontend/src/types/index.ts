@@ -2,9 +2,81 @@ export interface User {
   id: number;
   email: string;
   name: string;
-  telegram_chat_id: string;
-  email_notify: boolean;
-  telegram_notify: boolean;
+  email_verified_at: string | null;
+  deletion_scheduled_at: string | null;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface NotificationChannel {
+  channel: string;
+  enabled: boolean;
+  address: string;
+  available: boolean;
+}
+
+export interface TelegramLink {
+  token: string;
+  link?: string;
+  command: string;
+  expires_at: string;
+}
+
+export interface NotificationRecord {
+  id: number;
+  user_id: number;
+  channel: string;
+  kind: string;
+  bill_id: number | null;
+  status: 'pending' | 'sending' | 'sent' | 'failed';
+  attempts: number;
+  max_attempts: number;
+  next_attempt_at: string;
+  last_error: string;
+  provider_response: string;
+  sent_at: string | null;
+  created_at: string;
+  updated_at: string;
+}
+
+export type WebhookEvent =
+  | 'bill.created'
+  | 'bill.updated'
+  | 'bill.deleted'
+  | 'bill.due_soon'
+  | 'bill.overdue'
+  | 'bill.paid';
+
+export interface Webhook {
+  id: number;
+  user_id: number;
+  url: string;
+  events: WebhookEvent[];
+  description: string;
+  enabled: boolean;
+  consecutive_failures: number;
+  disabled_at: string | null;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CreatedWebhook extends Webhook {
+  secret: string;
+}
+
+export interface WebhookDelivery {
+  id: number;
+  webhook_id: number;
+  event: WebhookEvent | 'ping';
+  payload: unknown;
+  status: 'pending' | 'sending' | 'delivered' | 'failed';
+  attempts: number;
+  max_attempts: number;
+  next_attempt_at: string;
+  response_status: number | null;
+  response_body: string;
+  last_error: string;
+  delivered_at: string | null;
   created_at: string;
   updated_at: string;
 }
@@ -14,10 +86,14 @@ export interface Bill {
   user_id: number;
   name: string;
   amount: number;
+  paid_amount: number;
   due_date: string;
   description: string;
-  status: 'unpaid' | 'paid' | 'overdue';
+  status: 'unpaid' | 'partially_paid' | 'paid' | 'overdue';
   remind_before: number;
+  reminder_snoozed_until?: string;
+  category_id: number | null;
+  tags: { id: number; name: string }[];
   created_at: string;
   updated_at: string;
 }
@@ -34,8 +110,47 @@ export interface RegisterRequest {
   confirm_password: string;
 }
 
+export interface LoginResponse extends Partial<AuthResponse> {
+  two_factor_required: boolean;
+  challenge_token?: string;
+  challenge_expires_at?: string;
+}
+
+export interface TwoFactorLoginRequest {
+  challenge_token: string;
+  code?: string;
+  recovery_code?: string;
+}
+
+export interface UpdateProfileRequest {
+  name: string;
+}
+
+export interface ChangePasswordRequest {
+  current_password: string;
+  new_password: string;
+  confirm_password: string;
+}
+
+export interface ChangeEmailRequest {
+  new_email: string;
+  password: string;
+}
+
+export interface ForgotPasswordRequest {
+  email: string;
+}
+
+export interface ResetPasswordRequest {
+  token: string;
+  password: string;
+  confirm_password: string;
+}
+
 export interface AuthResponse {
   token: string;
+  expires_at: string;
+  refresh_token: string;
   user: User;
 }
 
@@ -45,6 +160,8 @@ export interface BillCreateRequest {
   due_date: string;
   description?: string;
   remind_before?: number;
+  category_id?: number;
+  tags?: string[];
 }
 
 export interface BillUpdateRequest {
@@ -54,6 +171,32 @@ export interface BillUpdateRequest {
   description?: string;
   status?: 'unpaid' | 'paid' | 'overdue';
   remind_before?: number;
+  category_id?: number;
+  tags?: string[];
+}
+
+export interface BillListQuery {
+  status?: string;
+  due_from?: string;
+  due_to?: string;
+  min_amount?: string;
+  max_amount?: string;
+  q?: string;
+  category_id?: number;
+  tag?: string;
+  sort?: string;
+  page?: number;
+  page_size?: number;
+}
+
+export interface BillListResponse {
+  data: Bill[];
+  pagination: {
+    page: number;
+    page_size: number;
+    total: number;
+    total_pages: number;
+  };
 }
 
 export interface DashboardResponse {
@@ -69,4 +212,26 @@ export interface DashboardResponse {
     unpaid_amount: number;
     overdue_amount: number;
   };
+  categories: {
+    category_id: number | null;
+    name: string;
+    color: string;
+    bill_count: number;
+    total_amount: number;
+    paid_amount: number;
+    unpaid_amount: number;
+  }[];
+  budgets: {
+    id: number;
+    category_id: number;
+    category_name: string;
+    period_start: string;
+    period_end: string;
+    amount: number;
+    spent: number;
+    paid: number;
+    remaining: number;
+    percent_used: number;
+    threshold: number;
+  }[];
 }
\ No newline at end of file
@@ -0,0 +1,28 @@
+#!/bin/sh
+
+# FinanceBroke container entrypoint, run under tini
+# Starts nginx and the backend side by side. On SIGTERM the backend drains its
+# in-flight requests while nginx keeps proxying them, then nginx shuts down
+# gracefully. If either process exits on its own, the other is stopped too.
+
+nginx -g 'daemon off;' &
+nginx_pid=$!
+./main &
+main_pid=$!
+
+trap 'kill -TERM "$main_pid" 2>/dev/null' TERM INT
+
+while kill -0 "$main_pid" 2>/dev/null && kill -0 "$nginx_pid" 2>/dev/null; do
+    # wait, unlike sleep, returns as soon as a signal is trapped
+    sleep 1 &
+    wait $!
+done
+
+kill -TERM "$main_pid" 2>/dev/null
+wait "$main_pid"
+status=$?
+
+kill -QUIT "$nginx_pid" 2>/dev/null
+wait "$nginx_pid"
+
+exit "$status"